@@ -0,0 +1,246 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blsync implements a beacon chain light client which follows the head
+// of the beacon chain through the light client REST API of a beacon node and
+// drives the execution client through the engine API, allowing geth to track
+// the chain without running a full consensus client.
+//
+// The light client API only provides the headers of the execution payloads, so
+// the light client can not deliver the blocks through newPayload. Instead, the
+// verified execution headers are announced along with the forkchoice updates and
+// the execution client retrieves the blocks from its peers through beacon sync.
+package blsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/light"
+	"github.com/ethereum/go-ethereum/beacon/light/api"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// pollInterval is the time between two attempts to retrieve new heads from
+	// the beacon node, half a slot to keep the latency low.
+	pollInterval = 6 * time.Second
+
+	// maxUpdateRequest is the maximum number of light client updates requested
+	// from the beacon node at once.
+	maxUpdateRequest = 128
+)
+
+// EngineAPI is the engine API used to drive the execution client. As there are
+// no payloads to deliver, each forkchoice update carries the header of the new
+// head block, which the execution client syncs to if it does not know it yet.
+type EngineAPI interface {
+	ForkchoiceUpdatedWithHeader(head *ctypes.Header, update engine.ForkchoiceStateV1) (engine.ForkChoiceResponse, error)
+}
+
+// Client is a beacon light client which verifies the heads announced by a beacon
+// node and feeds them into the execution client as forkchoice updates.
+type Client struct {
+	config *Config
+	api    *api.BeaconLightApi
+	chain  *light.CommitteeChain
+	engine EngineAPI
+
+	headSlot  uint64      // Slot of the last beacon head sent to the engine
+	finalized common.Hash // Last finalized execution block hash sent to the engine
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewClient creates a beacon light client driving the given engine API.
+func NewClient(config *Config, engine EngineAPI) *Client {
+	threshold := config.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client{
+		config: config,
+		api:    api.NewBeaconLightApi(config.ApiURL, config.CustomHeaders),
+		chain:  light.NewCommitteeChain(&config.ChainConfig, threshold),
+		engine: engine,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start implements node.Lifecycle, starting the light sync.
+func (c *Client) Start() error {
+	if c.config.Checkpoint == (common.Hash{}) {
+		return errors.New("beacon light sync requires a checkpoint")
+	}
+	log.Info("Starting beacon light client", "api", c.config.ApiURL, "checkpoint", c.config.Checkpoint)
+
+	c.wg.Add(1)
+	go c.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the light sync.
+func (c *Client) Stop() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// loop periodically retrieves the latest heads from the beacon node and updates
+// the forkchoice of the execution client.
+func (c *Client) loop() {
+	defer c.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := c.sync(); err != nil && c.ctx.Err() == nil {
+				log.Warn("Beacon light sync failed", "err", err)
+			}
+			timer.Reset(pollInterval)
+
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// sync performs a single round of light sync: bootstrapping the committee chain
+// if needed, verifying the latest signed head and finality proof, and sending a
+// forkchoice update to the engine if the head advanced.
+func (c *Client) sync() error {
+	if !c.chain.Initialized() {
+		bootstrap, err := c.api.GetBootstrap(c.ctx, c.config.Checkpoint)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve bootstrap data: %w", err)
+		}
+		if err := c.chain.Bootstrap(c.config.Checkpoint, bootstrap); err != nil {
+			return err
+		}
+	}
+	// Retrieve and verify the latest signed head
+	head, err := c.api.GetOptimisticUpdate(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve optimistic update: %w", err)
+	}
+	if err := head.Validate(); err != nil {
+		return fmt.Errorf("invalid optimistic update: %w", err)
+	}
+	if err := c.verify(head.SignedHeader()); err != nil {
+		return fmt.Errorf("failed to verify optimistic update: %w", err)
+	}
+	// Retrieve and verify the latest finality proof, not failing if unavailable
+	finalized := c.finalized
+	if update, err := c.api.GetFinalityUpdate(c.ctx); err != nil {
+		log.Debug("Failed to retrieve finality update", "err", err)
+	} else if err := update.Validate(); err != nil {
+		log.Warn("Invalid finality update", "err", err)
+	} else if err := c.verify(update.SignedHeader()); err != nil {
+		log.Warn("Failed to verify finality update", "err", err)
+	} else {
+		finalized = update.Finalized.PayloadHeader.BlockHash
+	}
+	if head.Attested.Header.Slot <= c.headSlot && finalized == c.finalized {
+		return nil
+	}
+	return c.forkchoiceUpdate(head.Attested.Header, head.Attested.PayloadHeader, finalized)
+}
+
+// verify checks the signature of a beacon header, advancing the committee chain
+// first if the committee of the signature period is not yet known.
+func (c *Client) verify(head types.SignedHeader) error {
+	err := c.chain.VerifySignedHeader(head)
+	if !errors.Is(err, light.ErrNeedCommittee) {
+		return err
+	}
+	if err := c.syncCommittees(types.SyncPeriod(head.SignatureSlot)); err != nil {
+		return err
+	}
+	return c.chain.VerifySignedHeader(head)
+}
+
+// syncCommittees advances the committee chain up to the given period by
+// retrieving and verifying the light client updates of the preceding periods.
+func (c *Client) syncCommittees(target uint64) error {
+	for {
+		next, ok := c.chain.NextSyncPeriod()
+		if !ok {
+			return light.ErrNotInitialized
+		}
+		if next > target {
+			return nil
+		}
+		// The committee of a period is proven by the update of the previous one
+		count := target - next + 1
+		if count > maxUpdateRequest {
+			count = maxUpdateRequest
+		}
+		updates, committees, err := c.api.GetBestUpdatesAndCommittees(c.ctx, next-1, count)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve light client updates: %w", err)
+		}
+		for i, update := range updates {
+			if err := c.chain.InsertUpdate(update, committees[i]); err != nil {
+				return fmt.Errorf("failed to insert light client update of period %d: %w", next-1+uint64(i), err)
+			}
+		}
+	}
+}
+
+// forkchoiceUpdate sends a verified head and finalized execution block hash to
+// the execution client.
+func (c *Client) forkchoiceUpdate(head types.Header, payload *types.ExecutionHeader, finalized common.Hash) error {
+	// The payload header is proven by the beacon header, but the block hash in
+	// it is not, so check that it matches the reconstructed block header.
+	header := payload.BlockHeader(head.ParentRoot)
+	if hash := header.Hash(); hash != payload.BlockHash {
+		return fmt.Errorf("execution block hash mismatch: have %x, want %x", hash, payload.BlockHash)
+	}
+	update := engine.ForkchoiceStateV1{
+		HeadBlockHash:      payload.BlockHash,
+		SafeBlockHash:      finalized,
+		FinalizedBlockHash: finalized,
+	}
+	resp, err := c.engine.ForkchoiceUpdatedWithHeader(header, update)
+	if err != nil {
+		return fmt.Errorf("failed to update forkchoice: %w", err)
+	}
+	c.headSlot, c.finalized = head.Slot, finalized
+
+	switch resp.PayloadStatus.Status {
+	case engine.VALID:
+		log.Info("Execution client head updated", "slot", head.Slot, "number", payload.BlockNumber, "hash", payload.BlockHash, "finalized", finalized)
+	case engine.SYNCING, engine.ACCEPTED:
+		log.Info("Execution client syncing to beacon head", "slot", head.Slot, "number", payload.BlockNumber, "hash", payload.BlockHash)
+	default:
+		log.Warn("Execution client rejected beacon head", "slot", head.Slot, "number", payload.BlockNumber, "hash", payload.BlockHash, "status", resp.PayloadStatus.Status)
+	}
+	return nil
+}
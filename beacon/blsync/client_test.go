@@ -0,0 +1,372 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blsync
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/light"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	bls "github.com/protolambda/bls12-381-util"
+)
+
+// testSigners is the number of committee members signing the test headers.
+const testSigners = 16
+
+var testConfig = (&types.ChainConfig{
+	GenesisValidatorsRoot: common.Hash{0x01},
+}).AddFork("GENESIS", 0, []byte{0, 0, 0, 0})
+
+// testCommittee is a sync committee with known secret keys.
+type testCommittee struct {
+	keys       []*bls.SecretKey
+	serialized *types.SerializedSyncCommittee
+}
+
+func newTestCommittee(t *testing.T, seed byte) *testCommittee {
+	var (
+		committee = &testCommittee{serialized: new(types.SerializedSyncCommittee)}
+		pubkeys   []*bls.Pubkey
+	)
+	for i := 0; i < params.SyncCommitteeSize; i++ {
+		var blob [32]byte
+		blob[0] = seed
+		binary.BigEndian.PutUint64(blob[24:], uint64(i+1))
+
+		key := new(bls.SecretKey)
+		if err := key.Deserialize(&blob); err != nil {
+			t.Fatalf("failed to create secret key: %v", err)
+		}
+		pubkey, err := bls.SkToPk(key)
+		if err != nil {
+			t.Fatalf("failed to derive public key: %v", err)
+		}
+		committee.keys = append(committee.keys, key)
+		pubkeys = append(pubkeys, pubkey)
+
+		pk := pubkey.Serialize()
+		copy(committee.serialized[i*params.BLSPubkeySize:], pk[:])
+	}
+	aggregate, err := bls.AggregatePubkeys(pubkeys)
+	if err != nil {
+		t.Fatalf("failed to aggregate public keys: %v", err)
+	}
+	pk := aggregate.Serialize()
+	copy(committee.serialized[params.SyncCommitteeSize*params.BLSPubkeySize:], pk[:])
+	return committee
+}
+
+// sign creates a sync aggregate of the first few committee members over the
+// given header.
+func (c *testCommittee) sign(t *testing.T, header types.Header) types.SyncAggregate {
+	root, err := testConfig.Forks.SigningRoot(header)
+	if err != nil {
+		t.Fatalf("failed to calculate signing root: %v", err)
+	}
+	var (
+		aggregate types.SyncAggregate
+		sigs      []*bls.Signature
+	)
+	for i := 0; i < testSigners; i++ {
+		aggregate.Signers[i/8] |= 1 << (i % 8)
+		sigs = append(sigs, bls.Sign(c.keys[i], root[:]))
+	}
+	sig, err := bls.Aggregate(sigs)
+	if err != nil {
+		t.Fatalf("failed to aggregate signatures: %v", err)
+	}
+	aggregate.Signature = sig.Serialize()
+	return aggregate
+}
+
+// proofTree is a sparse binary merkle tree with only a few known leaves, all
+// other subtrees being zero.
+type proofTree map[uint64]merkle.Value
+
+func (tree proofTree) node(index uint64) merkle.Value {
+	if value, ok := tree[index]; ok {
+		return value
+	}
+	for leaf := range tree {
+		for ; leaf > index; leaf /= 2 {
+		}
+		if leaf == index {
+			var (
+				left   = tree.node(index * 2)
+				right  = tree.node(index*2 + 1)
+				hasher = sha256.New()
+				value  merkle.Value
+			)
+			hasher.Write(left[:])
+			hasher.Write(right[:])
+			hasher.Sum(value[:0])
+			return value
+		}
+	}
+	return merkle.Value{}
+}
+
+func (tree proofTree) root() common.Hash {
+	return common.Hash(tree.node(1))
+}
+
+func (tree proofTree) branch(index uint64) merkle.Values {
+	var branch merkle.Values
+	for ; index > 1; index /= 2 {
+		branch = append(branch, tree.node(index^1))
+	}
+	return branch
+}
+
+// jsonHeader is the JSON representation of a light client header.
+type jsonHeader struct {
+	Beacon          types.Header           `json:"beacon"`
+	Execution       *types.ExecutionHeader `json:"execution,omitempty"`
+	ExecutionBranch merkle.Values          `json:"execution_branch,omitempty"`
+}
+
+// newTestHeader creates a beacon header at the given slot, with an execution
+// payload and the given leaves in its state. If corrupt is set, the block hash
+// of the payload does not match its contents.
+func newTestHeader(t *testing.T, slot uint64, state proofTree, corrupt bool) jsonHeader {
+	payload := &types.ExecutionHeader{
+		BlockNumber:   slot,
+		Timestamp:     slot * 12,
+		ExtraData:     []byte("test"),
+		BaseFeePerGas: big.NewInt(7),
+	}
+	payload.BlockHash = payload.BlockHeader(common.Hash{}).Hash()
+	if corrupt {
+		payload.BlockHash = common.Hash{0xff}
+	}
+	root, err := payload.Root()
+	if err != nil {
+		t.Fatalf("failed to calculate payload root: %v", err)
+	}
+	body := proofTree{params.BodyIndexExecPayload: merkle.Value(root)}
+	return jsonHeader{
+		Beacon: types.Header{
+			Slot:      slot,
+			StateRoot: state.root(),
+			BodyRoot:  body.root(),
+		},
+		Execution:       payload,
+		ExecutionBranch: body.branch(params.BodyIndexExecPayload),
+	}
+}
+
+// testBeaconNode serves a beacon chain spanning two sync periods through the
+// light client API.
+type testBeaconNode struct {
+	checkpoint common.Hash
+	headHash   common.Hash // Execution block hash of the latest head
+	finalHash  common.Hash // Execution block hash of the latest finalized block
+
+	bootstrap  any
+	updates    any
+	optimistic any
+	finality   any
+}
+
+// newTestBeaconNode creates a test beacon node. If corrupt is set, the block hash
+// of the latest head does not match its execution payload.
+func newTestBeaconNode(t *testing.T, corrupt bool) *testBeaconNode {
+	var (
+		node      = new(testBeaconNode)
+		committee = newTestCommittee(t, 1) // Committee of period 1
+		next      = newTestCommittee(t, 2) // Committee of period 2
+	)
+	// Bootstrap from a checkpoint in period 1
+	state := proofTree{params.StateIndexSyncCommittee: merkle.Value(committee.serialized.Root())}
+	checkpoint := newTestHeader(t, params.SyncPeriodLength+10, state, false)
+	node.checkpoint = checkpoint.Beacon.Hash()
+	node.bootstrap = map[string]any{"data": map[string]any{
+		"header":                        checkpoint,
+		"current_sync_committee":        committee.serialized,
+		"current_sync_committee_branch": state.branch(params.StateIndexSyncCommittee),
+	}}
+	// Prove the committee of period 2 with the update of period 1
+	finalized := newTestHeader(t, params.SyncPeriodLength+64, proofTree{}, false)
+	state = proofTree{
+		params.StateIndexNextSyncCommittee: merkle.Value(next.serialized.Root()),
+		params.StateIndexFinalBlock:        merkle.Value(finalized.Beacon.Hash()),
+	}
+	attested := newTestHeader(t, params.SyncPeriodLength+100, state, false)
+	node.updates = []any{map[string]any{"data": map[string]any{
+		"attested_header":            attested,
+		"next_sync_committee":        next.serialized,
+		"next_sync_committee_branch": state.branch(params.StateIndexNextSyncCommittee),
+		"finalized_header":           finalized,
+		"finality_branch":            state.branch(params.StateIndexFinalBlock),
+		"sync_aggregate":             committee.sign(t, attested.Beacon),
+		"signature_slot":             common.Decimal(attested.Beacon.Slot + 1),
+	}}}
+	// Create a head and finality proof in period 2, signed by the new committee
+	finalized = newTestHeader(t, 2*params.SyncPeriodLength, proofTree{}, false)
+	state = proofTree{params.StateIndexFinalBlock: merkle.Value(finalized.Beacon.Hash())}
+	head := newTestHeader(t, 2*params.SyncPeriodLength+5, state, corrupt)
+	node.headHash, node.finalHash = head.Execution.BlockHash, finalized.Execution.BlockHash
+	signature := next.sign(t, head.Beacon)
+
+	node.optimistic = map[string]any{"data": map[string]any{
+		"attested_header": head,
+		"sync_aggregate":  signature,
+		"signature_slot":  common.Decimal(head.Beacon.Slot + 1),
+	}}
+	node.finality = map[string]any{"data": map[string]any{
+		"attested_header":  head,
+		"finalized_header": finalized,
+		"finality_branch":  state.branch(params.StateIndexFinalBlock),
+		"sync_aggregate":   signature,
+		"signature_slot":   common.Decimal(head.Beacon.Slot + 1),
+	}}
+	return node
+}
+
+func (node *testBeaconNode) serve(t *testing.T) *httptest.Server {
+	respond := func(data any) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewEncoder(w).Encode(data); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/eth/v1/beacon/light_client/bootstrap/"+node.checkpoint.Hex(), respond(node.bootstrap))
+	mux.Handle("/eth/v1/beacon/light_client/optimistic_update", respond(node.optimistic))
+	mux.Handle("/eth/v1/beacon/light_client/finality_update", respond(node.finality))
+	mux.HandleFunc("/eth/v1/beacon/light_client/updates", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start_period") != "1" || r.URL.Query().Get("count") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		respond(node.updates)(w, r)
+	})
+	return httptest.NewServer(mux)
+}
+
+// testEngine records the forkchoice updates and head headers received.
+type testEngine struct {
+	updates []engine.ForkchoiceStateV1
+	heads   []*ctypes.Header
+}
+
+func (e *testEngine) ForkchoiceUpdatedWithHeader(head *ctypes.Header, update engine.ForkchoiceStateV1) (engine.ForkChoiceResponse, error) {
+	e.updates = append(e.updates, update)
+	e.heads = append(e.heads, head)
+	return engine.ForkChoiceResponse{PayloadStatus: engine.PayloadStatusV1{Status: engine.SYNCING}}, nil
+}
+
+// Tests that the light client bootstraps from a checkpoint, advances the sync
+// committee chain and feeds the verified heads into the engine API.
+func TestLightSync(t *testing.T) {
+	node := newTestBeaconNode(t, false)
+	server := node.serve(t)
+	defer server.Close()
+
+	eng := new(testEngine)
+	client := NewClient(&Config{
+		ChainConfig: *testConfig,
+		Checkpoint:  node.checkpoint,
+		ApiURL:      server.URL,
+		Threshold:   testSigners,
+	}, eng)
+
+	if err := client.sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if next, _ := client.chain.NextSyncPeriod(); next != 3 {
+		t.Fatalf("committee chain not advanced: have next period %d, want %d", next, 3)
+	}
+	want := engine.ForkchoiceStateV1{
+		HeadBlockHash:      node.headHash,
+		SafeBlockHash:      node.finalHash,
+		FinalizedBlockHash: node.finalHash,
+	}
+	if len(eng.updates) != 1 || eng.updates[0] != want {
+		t.Fatalf("forkchoice update mismatch: have %+v, want %+v", eng.updates, want)
+	}
+	if hash := eng.heads[0].Hash(); hash != node.headHash {
+		t.Fatalf("head header mismatch: have hash %x, want %x", hash, node.headHash)
+	}
+	// Ensure the same head is not resent
+	if err := client.sync(); err != nil {
+		t.Fatalf("failed to resync: %v", err)
+	}
+	if len(eng.updates) != 1 {
+		t.Fatalf("unchanged head resent: have %d updates", len(eng.updates))
+	}
+}
+
+// Tests that heads which are not signed by a sufficient number of committee
+// members, or bootstrap data not matching the checkpoint are rejected.
+func TestLightSyncInvalid(t *testing.T) {
+	node := newTestBeaconNode(t, false)
+	server := node.serve(t)
+	defer server.Close()
+
+	// Require more signers than available
+	eng := new(testEngine)
+	client := NewClient(&Config{
+		ChainConfig: *testConfig,
+		Checkpoint:  node.checkpoint,
+		ApiURL:      server.URL,
+		Threshold:   testSigners + 1,
+	}, eng)
+	if err := client.sync(); !errors.Is(err, light.ErrInsufficientSignatures) {
+		t.Fatalf("insufficient signatures not detected: %v", err)
+	}
+	// Bootstrap from an unknown checkpoint
+	client = NewClient(&Config{
+		ChainConfig: *testConfig,
+		Checkpoint:  common.Hash{0xff},
+		ApiURL:      server.URL,
+		Threshold:   testSigners,
+	}, eng)
+	if err := client.sync(); err == nil {
+		t.Fatalf("bootstrapped from unknown checkpoint")
+	}
+	// Follow a head with an execution block hash not matching its payload
+	node = newTestBeaconNode(t, true)
+	corrupt := node.serve(t)
+	defer corrupt.Close()
+
+	client = NewClient(&Config{
+		ChainConfig: *testConfig,
+		Checkpoint:  node.checkpoint,
+		ApiURL:      corrupt.URL,
+		Threshold:   testSigners,
+	}, eng)
+	if err := client.sync(); err == nil {
+		t.Fatalf("head with mismatching block hash accepted")
+	}
+	if len(eng.updates) != 0 {
+		t.Fatalf("unverified head sent to engine: %+v", eng.updates)
+	}
+}
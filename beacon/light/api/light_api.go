@@ -0,0 +1,264 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package api implements a client for the beacon node light client REST API.
+//
+// See the API definition here:
+// https://github.com/ethereum/beacon-APIs/tree/master/apis/beacon/light_client
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrNotFound = errors.New("404 Not Found")
+
+// maxResponseSize is the maximum size of a REST API response accepted.
+const maxResponseSize = 16 * 1024 * 1024
+
+// BeaconLightApi requests light client information from a beacon node REST API.
+type BeaconLightApi struct {
+	url           string
+	client        *http.Client
+	customHeaders map[string]string
+}
+
+// NewBeaconLightApi creates a client for the REST API served at the given URL,
+// adding the given custom headers to each request.
+func NewBeaconLightApi(url string, customHeaders map[string]string) *BeaconLightApi {
+	return &BeaconLightApi{
+		url:           url,
+		client:        &http.Client{},
+		customHeaders: customHeaders,
+	}
+}
+
+// httpGet performs a GET request on the given path and returns the body of a
+// successful response.
+func (api *BeaconLightApi) httpGet(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	uri, err := url.JoinPath(api.url, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range api.customHeaders {
+		req.Header.Set(k, v)
+	}
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("unexpected error from API endpoint %q: status code %d", path, resp.StatusCode)
+	}
+}
+
+// jsonBeaconHeader is the JSON representation of a light client header, with
+// the execution fields only being present since the Capella fork.
+type jsonBeaconHeader struct {
+	Beacon          types.Header           `json:"beacon"`
+	Execution       *types.ExecutionHeader `json:"execution"`
+	ExecutionBranch merkle.Values          `json:"execution_branch"`
+}
+
+// withExecProof converts the JSON header into a header with execution proof.
+func (h *jsonBeaconHeader) withExecProof() types.HeaderWithExecProof {
+	return types.HeaderWithExecProof{
+		Header:        h.Beacon,
+		PayloadHeader: h.Execution,
+		PayloadBranch: h.ExecutionBranch,
+	}
+}
+
+// GetBootstrap retrieves the sync committee of the period of the given
+// checkpoint block root, along with the proof of the committee.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientbootstrap
+func (api *BeaconLightApi) GetBootstrap(ctx context.Context, checkpoint common.Hash) (*types.BootstrapData, error) {
+	resp, err := api.httpGet(ctx, "/eth/v1/beacon/light_client/bootstrap/"+checkpoint.Hex(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Data struct {
+			Header          jsonBeaconHeader               `json:"header"`
+			Committee       *types.SerializedSyncCommittee `json:"current_sync_committee"`
+			CommitteeBranch merkle.Values                  `json:"current_sync_committee_branch"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	if data.Data.Committee == nil {
+		return nil, errors.New("sync committee is missing")
+	}
+	return &types.BootstrapData{
+		Header:          data.Data.Header.Beacon,
+		CommitteeRoot:   data.Data.Committee.Root(),
+		Committee:       data.Data.Committee,
+		CommitteeBranch: data.Data.CommitteeBranch,
+	}, nil
+}
+
+// jsonLightClientUpdate is the JSON representation of a light client update.
+type jsonLightClientUpdate struct {
+	AttestedHeader          jsonBeaconHeader               `json:"attested_header"`
+	NextSyncCommittee       *types.SerializedSyncCommittee `json:"next_sync_committee"`
+	NextSyncCommitteeBranch merkle.Values                  `json:"next_sync_committee_branch"`
+	FinalizedHeader         *jsonBeaconHeader              `json:"finalized_header,omitempty"`
+	FinalityBranch          merkle.Values                  `json:"finality_branch,omitempty"`
+	SyncAggregate           types.SyncAggregate            `json:"sync_aggregate"`
+	SignatureSlot           common.Decimal                 `json:"signature_slot"`
+}
+
+// GetBestUpdatesAndCommittees fetches the best known light client updates for
+// the given range of periods, along with the sync committees of the subsequent
+// periods proven by them.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientupdate
+func (api *BeaconLightApi) GetBestUpdatesAndCommittees(ctx context.Context, firstPeriod, count uint64) ([]*types.LightClientUpdate, []*types.SerializedSyncCommittee, error) {
+	params := url.Values{
+		"start_period": {strconv.FormatUint(firstPeriod, 10)},
+		"count":        {strconv.FormatUint(count, 10)},
+	}
+	resp, err := api.httpGet(ctx, "/eth/v1/beacon/light_client/updates", params)
+	if err != nil {
+		return nil, nil, err
+	}
+	var data []struct {
+		Data jsonLightClientUpdate `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, nil, err
+	}
+	if len(data) != int(count) {
+		return nil, nil, errors.New("invalid number of light client updates")
+	}
+	var (
+		updates    = make([]*types.LightClientUpdate, count)
+		committees = make([]*types.SerializedSyncCommittee, count)
+	)
+	for i, d := range data {
+		attested := d.Data.AttestedHeader.Beacon
+		if attested.SyncPeriod() != firstPeriod+uint64(i) {
+			return nil, nil, errors.New("wrong update period")
+		}
+		if d.Data.NextSyncCommittee == nil {
+			return nil, nil, errors.New("next sync committee is missing")
+		}
+		update := &types.LightClientUpdate{
+			AttestedHeader: types.SignedHeader{
+				Header:        attested,
+				Signature:     d.Data.SyncAggregate,
+				SignatureSlot: uint64(d.Data.SignatureSlot),
+			},
+			NextSyncCommitteeRoot:   d.Data.NextSyncCommittee.Root(),
+			NextSyncCommitteeBranch: d.Data.NextSyncCommitteeBranch,
+		}
+		// Finality is only meaningful for the update if it's from the same period
+		if d.Data.FinalizedHeader != nil && d.Data.FinalizedHeader.Beacon.SyncPeriod() == attested.SyncPeriod() && d.Data.FinalizedHeader.Beacon.Slot != 0 {
+			update.FinalizedHeader = &d.Data.FinalizedHeader.Beacon
+			update.FinalityBranch = d.Data.FinalityBranch
+		}
+		updates[i], committees[i] = update, d.Data.NextSyncCommittee
+	}
+	return updates, committees, nil
+}
+
+// GetOptimisticUpdate fetches the latest header signed by the sync committee,
+// along with the execution payload header belonging to it.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientoptimisticupdate
+func (api *BeaconLightApi) GetOptimisticUpdate(ctx context.Context) (*types.OptimisticUpdate, error) {
+	resp, err := api.httpGet(ctx, "/eth/v1/beacon/light_client/optimistic_update", nil)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Data struct {
+			AttestedHeader jsonBeaconHeader    `json:"attested_header"`
+			SyncAggregate  types.SyncAggregate `json:"sync_aggregate"`
+			SignatureSlot  common.Decimal      `json:"signature_slot"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	return &types.OptimisticUpdate{
+		Attested:      data.Data.AttestedHeader.withExecProof(),
+		Signature:     data.Data.SyncAggregate,
+		SignatureSlot: uint64(data.Data.SignatureSlot),
+	}, nil
+}
+
+// GetFinalityUpdate fetches the latest finalized header proven by a header
+// signed by the sync committee, along with the execution payload headers
+// belonging to both.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientfinalityupdate
+func (api *BeaconLightApi) GetFinalityUpdate(ctx context.Context) (*types.FinalityUpdate, error) {
+	resp, err := api.httpGet(ctx, "/eth/v1/beacon/light_client/finality_update", nil)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Data struct {
+			AttestedHeader  jsonBeaconHeader    `json:"attested_header"`
+			FinalizedHeader jsonBeaconHeader    `json:"finalized_header"`
+			FinalityBranch  merkle.Values       `json:"finality_branch"`
+			SyncAggregate   types.SyncAggregate `json:"sync_aggregate"`
+			SignatureSlot   common.Decimal      `json:"signature_slot"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	return &types.FinalityUpdate{
+		Attested:       data.Data.AttestedHeader.withExecProof(),
+		Finalized:      data.Data.FinalizedHeader.withExecProof(),
+		FinalityBranch: data.Data.FinalityBranch,
+		Signature:      data.Data.SyncAggregate,
+		SignatureSlot:  uint64(data.Data.SignatureSlot),
+	}, nil
+}
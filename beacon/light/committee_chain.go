@@ -0,0 +1,180 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package light implements the verification logic of the beacon chain light
+// client sync protocol.
+package light
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	ErrNotInitialized         = errors.New("committee chain not initialized")
+	ErrNeedCommittee          = errors.New("sync committee required")
+	ErrInvalidBootstrap       = errors.New("invalid bootstrap data")
+	ErrInvalidUpdate          = errors.New("invalid light client update")
+	ErrWrongCommitteeRoot     = errors.New("wrong committee root")
+	ErrInsufficientSignatures = errors.New("insufficient signatures")
+	ErrInvalidSignature       = errors.New("invalid sync committee signature")
+)
+
+// committeeRetention is the number of past sync periods whose committees are
+// retained after advancing the chain.
+const committeeRetention = 2
+
+// CommitteeChain tracks the sync committees of consecutive sync periods. It is
+// initialized from a trusted checkpoint and advanced by verified light client
+// updates, each proving the committee of the next period. Beacon headers signed
+// by any of the known committees can be verified.
+//
+// All data is kept in memory only, so the chain needs to be bootstrapped again
+// after a restart.
+type CommitteeChain struct {
+	config          *types.ChainConfig
+	signerThreshold int // Minimum number of signers needed for a header to be accepted
+
+	lock       sync.RWMutex
+	committees map[uint64]*types.SyncCommittee // Verified sync committees by period
+	next       uint64                          // First period whose committee is unknown
+}
+
+// NewCommitteeChain creates an empty committee chain. The signer threshold is
+// the minimum number of sync committee signatures required to accept a header.
+func NewCommitteeChain(config *types.ChainConfig, signerThreshold int) *CommitteeChain {
+	return &CommitteeChain{
+		config:          config,
+		signerThreshold: signerThreshold,
+		committees:      make(map[uint64]*types.SyncCommittee),
+	}
+}
+
+// Initialized returns whether the chain has been bootstrapped.
+func (s *CommitteeChain) Initialized() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.committees) > 0
+}
+
+// NextSyncPeriod returns the first sync period whose committee is not yet known
+// and whether the chain has been bootstrapped at all.
+func (s *CommitteeChain) NextSyncPeriod() (uint64, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.next, len(s.committees) > 0
+}
+
+// Bootstrap initializes the chain from a sync committee proven by a beacon
+// header whose root matches the trusted checkpoint.
+func (s *CommitteeChain) Bootstrap(checkpoint common.Hash, data *types.BootstrapData) error {
+	if hash := data.Header.Hash(); hash != checkpoint {
+		return fmt.Errorf("%w: header root %x does not match checkpoint %x", ErrInvalidBootstrap, hash, checkpoint)
+	}
+	if data.Committee == nil {
+		return fmt.Errorf("%w: missing sync committee", ErrInvalidBootstrap)
+	}
+	if err := data.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBootstrap, err)
+	}
+	committee, err := data.Committee.Deserialize()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBootstrap, err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	period := data.Header.SyncPeriod()
+	s.committees = map[uint64]*types.SyncCommittee{period: committee}
+	s.next = period + 1
+
+	log.Info("Initialized sync committee chain", "period", period, "checkpoint", checkpoint)
+	return nil
+}
+
+// InsertUpdate verifies a light client update signed by the committee of its
+// period and adds the next period's committee proven by it to the chain.
+func (s *CommitteeChain) InsertUpdate(update *types.LightClientUpdate, nextCommittee *types.SerializedSyncCommittee) error {
+	if err := update.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+	if nextCommittee == nil || nextCommittee.Root() != update.NextSyncCommitteeRoot {
+		return ErrWrongCommitteeRoot
+	}
+	if err := s.VerifySignedHeader(update.AttestedHeader); err != nil {
+		return err
+	}
+	committee, err := nextCommittee.Deserialize()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	period := update.AttestedHeader.Header.SyncPeriod()
+	if period+1 != s.next {
+		// The update was verified by a known committee, so it is not invalid,
+		// it just doesn't advance the chain
+		return nil
+	}
+	s.committees[s.next] = committee
+	s.next++
+
+	for p := range s.committees {
+		if p+committeeRetention < s.next-1 {
+			delete(s.committees, p)
+		}
+	}
+	log.Debug("Inserted sync committee", "period", period+1)
+	return nil
+}
+
+// VerifySignedHeader checks whether a beacon header was signed by a sufficient
+// number of members of the sync committee of its signature period.
+func (s *CommitteeChain) VerifySignedHeader(head types.SignedHeader) error {
+	if head.SignatureSlot <= head.Header.Slot {
+		return fmt.Errorf("%w: signature slot %d not newer than header slot %d", ErrInvalidSignature, head.SignatureSlot, head.Header.Slot)
+	}
+	if count := head.Signature.SignerCount(); count < s.signerThreshold {
+		return fmt.Errorf("%w: have %d, want %d", ErrInsufficientSignatures, count, s.signerThreshold)
+	}
+	s.lock.RLock()
+	initialized := len(s.committees) > 0
+	committee := s.committees[types.SyncPeriod(head.SignatureSlot)]
+	s.lock.RUnlock()
+
+	if !initialized {
+		return ErrNotInitialized
+	}
+	if committee == nil {
+		return fmt.Errorf("%w: period %d", ErrNeedCommittee, types.SyncPeriod(head.SignatureSlot))
+	}
+	signingRoot, err := s.config.Forks.SigningRoot(head.Header)
+	if err != nil {
+		return err
+	}
+	if !committee.VerifySignature(signingRoot, &head.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
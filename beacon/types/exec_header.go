@@ -0,0 +1,224 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+//go:generate go run github.com/fjl/gencodec -type ExecutionHeader -field-override executionHeaderMarshaling -out gen_execheader_json.go
+
+// maxExtraDataBytes is the maximum size of the extra data field in an execution
+// payload header.
+const maxExtraDataBytes = 32
+
+// ExecutionHeader is the header of an execution payload, as embedded into the
+// light client headers since the Capella fork. The blob gas fields are only
+// present since the Deneb fork.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/deneb/beacon-chain.md#executionpayloadheader
+type ExecutionHeader struct {
+	ParentHash       common.Hash    `gencodec:"required" json:"parent_hash"`
+	FeeRecipient     common.Address `gencodec:"required" json:"fee_recipient"`
+	StateRoot        common.Hash    `gencodec:"required" json:"state_root"`
+	ReceiptsRoot     common.Hash    `gencodec:"required" json:"receipts_root"`
+	LogsBloom        [256]byte      `gencodec:"required" json:"logs_bloom"`
+	PrevRandao       common.Hash    `gencodec:"required" json:"prev_randao"`
+	BlockNumber      uint64         `gencodec:"required" json:"block_number"`
+	GasLimit         uint64         `gencodec:"required" json:"gas_limit"`
+	GasUsed          uint64         `gencodec:"required" json:"gas_used"`
+	Timestamp        uint64         `gencodec:"required" json:"timestamp"`
+	ExtraData        []byte         `gencodec:"required" json:"extra_data"`
+	BaseFeePerGas    *big.Int       `gencodec:"required" json:"base_fee_per_gas"`
+	BlockHash        common.Hash    `gencodec:"required" json:"block_hash"`
+	TransactionsRoot common.Hash    `gencodec:"required" json:"transactions_root"`
+	WithdrawalsRoot  common.Hash    `gencodec:"required" json:"withdrawals_root"`
+	BlobGasUsed      *uint64        `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas    *uint64        `json:"excess_blob_gas,omitempty"`
+}
+
+// executionHeaderMarshaling is a field type overrides for gencodec.
+type executionHeaderMarshaling struct {
+	LogsBloom     hexutil.Bytes
+	BlockNumber   common.Decimal
+	GasLimit      common.Decimal
+	GasUsed       common.Decimal
+	Timestamp     common.Decimal
+	ExtraData     hexutil.Bytes
+	BaseFeePerGas *math.HexOrDecimal256
+	BlobGasUsed   *common.Decimal
+	ExcessBlobGas *common.Decimal
+}
+
+// Root calculates the SSZ hash tree root of the execution payload header.
+//
+// TODO(zsfelfoldi): Remove this when an SSZ encoder lands.
+func (h *ExecutionHeader) Root() (common.Hash, error) {
+	if len(h.ExtraData) > maxExtraDataBytes {
+		return common.Hash{}, fmt.Errorf("extra data too long: %d bytes", len(h.ExtraData))
+	}
+	if (h.BlobGasUsed == nil) != (h.ExcessBlobGas == nil) {
+		return common.Hash{}, errors.New("incomplete blob gas fields")
+	}
+	var (
+		fields []merkle.Value
+		chunk  merkle.Value
+	)
+	fields = append(fields, merkle.Value(h.ParentHash))
+	copy(chunk[:], h.FeeRecipient[:])
+	fields = append(fields, chunk)
+	fields = append(fields, merkle.Value(h.StateRoot), merkle.Value(h.ReceiptsRoot))
+
+	// The logs bloom is a fixed size byte vector spanning multiple chunks
+	bloom := make([]merkle.Value, len(h.LogsBloom)/32)
+	for i := range bloom {
+		copy(bloom[i][:], h.LogsBloom[i*32:])
+	}
+	fields = append(fields, merkleize(bloom), merkle.Value(h.PrevRandao))
+	fields = append(fields, uint64Value(h.BlockNumber), uint64Value(h.GasLimit), uint64Value(h.GasUsed), uint64Value(h.Timestamp))
+
+	// The extra data is a byte list fitting into a single chunk, mixed in with
+	// its length
+	var extra, length merkle.Value
+	copy(extra[:], h.ExtraData)
+	binary.LittleEndian.PutUint64(length[:8], uint64(len(h.ExtraData)))
+	fields = append(fields, hashPair(extra, length))
+
+	// The base fee is a little endian uint256
+	var baseFee merkle.Value
+	if h.BaseFeePerGas != nil {
+		if h.BaseFeePerGas.Sign() < 0 || h.BaseFeePerGas.BitLen() > 256 {
+			return common.Hash{}, errors.New("invalid base fee")
+		}
+		h.BaseFeePerGas.FillBytes(baseFee[:])
+		for i, j := 0, len(baseFee)-1; i < j; i, j = i+1, j-1 {
+			baseFee[i], baseFee[j] = baseFee[j], baseFee[i]
+		}
+	}
+	fields = append(fields, baseFee, merkle.Value(h.BlockHash), merkle.Value(h.TransactionsRoot), merkle.Value(h.WithdrawalsRoot))
+	if h.BlobGasUsed != nil {
+		fields = append(fields, uint64Value(*h.BlobGasUsed), uint64Value(*h.ExcessBlobGas))
+	}
+	return common.Hash(merkleize(fields)), nil
+}
+
+// BlockHeader reconstructs the execution block header from the payload header.
+// The parent beacon block root is only part of the block header since the Deneb
+// fork, so it is ignored for payloads without blob gas fields. The hash of the
+// returned header matches BlockHash for consistent payload headers.
+func (h *ExecutionHeader) BlockHeader(parentBeaconRoot common.Hash) *ctypes.Header {
+	header := &ctypes.Header{
+		ParentHash:      h.ParentHash,
+		UncleHash:       ctypes.EmptyUncleHash,
+		Coinbase:        h.FeeRecipient,
+		Root:            h.StateRoot,
+		TxHash:          h.TransactionsRoot,
+		ReceiptHash:     h.ReceiptsRoot,
+		Bloom:           ctypes.Bloom(h.LogsBloom),
+		Difficulty:      new(big.Int),
+		Number:          new(big.Int).SetUint64(h.BlockNumber),
+		GasLimit:        h.GasLimit,
+		GasUsed:         h.GasUsed,
+		Time:            h.Timestamp,
+		Extra:           common.CopyBytes(h.ExtraData),
+		MixDigest:       h.PrevRandao,
+		BaseFee:         new(big.Int),
+		WithdrawalsHash: new(common.Hash),
+	}
+	if h.BaseFeePerGas != nil {
+		header.BaseFee.Set(h.BaseFeePerGas)
+	}
+	*header.WithdrawalsHash = h.WithdrawalsRoot
+	if h.BlobGasUsed != nil && h.ExcessBlobGas != nil {
+		blobGasUsed, excessBlobGas := *h.BlobGasUsed, *h.ExcessBlobGas
+		header.BlobGasUsed, header.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+		header.BeaconRoot = &parentBeaconRoot
+	}
+	return header
+}
+
+// uint64Value returns the SSZ chunk representation of an integer.
+func uint64Value(n uint64) (v merkle.Value) {
+	binary.LittleEndian.PutUint64(v[:8], n)
+	return v
+}
+
+// hashPair returns the hash of two concatenated tree nodes.
+func hashPair(left, right merkle.Value) (v merkle.Value) {
+	hasher := sha256.New()
+	hasher.Write(left[:])
+	hasher.Write(right[:])
+	hasher.Sum(v[:0])
+	return v
+}
+
+// merkleize calculates the root of a binary merkle tree built from the given
+// chunks, padded with zero chunks to the next power of two.
+func merkleize(chunks []merkle.Value) merkle.Value {
+	size := 1
+	for size < len(chunks) {
+		size *= 2
+	}
+	nodes := make([]merkle.Value, size)
+	copy(nodes, chunks)
+	for size > 1 {
+		for i := 0; i < size/2; i++ {
+			nodes[i] = hashPair(nodes[i*2], nodes[i*2+1])
+		}
+		size /= 2
+	}
+	return nodes[0]
+}
+
+// HeaderWithExecProof is a beacon header bundled with the header of the
+// execution payload included in the beacon block, along with a merkle proof
+// linking the two.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/capella/light-client/sync-protocol.md#modified-lightclientheader
+type HeaderWithExecProof struct {
+	Header        Header           `json:"beacon"`
+	PayloadHeader *ExecutionHeader `json:"execution"`
+	PayloadBranch merkle.Values    `json:"execution_branch"`
+}
+
+// Validate verifies the merkle proof of the execution payload header.
+func (h *HeaderWithExecProof) Validate() error {
+	if h.PayloadHeader == nil {
+		return errors.New("missing execution payload header")
+	}
+	root, err := h.PayloadHeader.Root()
+	if err != nil {
+		return err
+	}
+	if err := merkle.VerifyProof(h.Header.BodyRoot, params.BodyIndexExecPayload, h.PayloadBranch, merkle.Value(root)); err != nil {
+		return fmt.Errorf("invalid execution payload proof: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the execution block headers reconstructed from the payload headers
+// match the original block headers, both before and after the Deneb fork.
+func TestExecutionBlockHeader(t *testing.T) {
+	var (
+		withdrawals   = common.Hash{0x07}
+		blobGasUsed   = uint64(131072)
+		excessBlobGas = uint64(262144)
+		beaconRoot    = common.Hash{0x08}
+	)
+	capella := &ctypes.Header{
+		ParentHash:      common.Hash{0x01},
+		UncleHash:       ctypes.EmptyUncleHash,
+		Coinbase:        common.Address{0x02},
+		Root:            common.Hash{0x03},
+		TxHash:          common.Hash{0x04},
+		ReceiptHash:     common.Hash{0x05},
+		Bloom:           ctypes.Bloom{0x06},
+		Difficulty:      new(big.Int),
+		Number:          big.NewInt(100),
+		GasLimit:        30_000_000,
+		GasUsed:         21_000,
+		Time:            1_700_000_000,
+		Extra:           []byte("test"),
+		MixDigest:       common.Hash{0x09},
+		BaseFee:         big.NewInt(7),
+		WithdrawalsHash: &withdrawals,
+	}
+	deneb := ctypes.CopyHeader(capella)
+	deneb.BlobGasUsed, deneb.ExcessBlobGas, deneb.BeaconRoot = &blobGasUsed, &excessBlobGas, &beaconRoot
+
+	for i, header := range []*ctypes.Header{capella, deneb} {
+		payload := &ExecutionHeader{
+			ParentHash:       header.ParentHash,
+			FeeRecipient:     header.Coinbase,
+			StateRoot:        header.Root,
+			ReceiptsRoot:     header.ReceiptHash,
+			LogsBloom:        header.Bloom,
+			PrevRandao:       header.MixDigest,
+			BlockNumber:      header.Number.Uint64(),
+			GasLimit:         header.GasLimit,
+			GasUsed:          header.GasUsed,
+			Timestamp:        header.Time,
+			ExtraData:        header.Extra,
+			BaseFeePerGas:    header.BaseFee,
+			BlockHash:        header.Hash(),
+			TransactionsRoot: header.TxHash,
+			WithdrawalsRoot:  *header.WithdrawalsHash,
+			BlobGasUsed:      header.BlobGasUsed,
+			ExcessBlobGas:    header.ExcessBlobGas,
+		}
+		if hash := payload.BlockHeader(beaconRoot).Hash(); hash != payload.BlockHash {
+			t.Errorf("test %d: block hash mismatch: have %x, want %x", i, hash, payload.BlockHash)
+		}
+	}
+}
@@ -17,9 +17,11 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"runtime"
 	"strconv"
@@ -34,11 +36,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/urfave/cli/v2"
 )
 
@@ -51,13 +56,25 @@ var (
 		Flags: flags.Merge([]cli.Flag{
 			utils.CachePreimagesFlag,
 			utils.StateSchemeFlag,
-		}, utils.DatabasePathFlags),
+			utils.CheckpointBlockFlag,
+			utils.CheckpointReceiptsFlag,
+			utils.CheckpointStateFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
 		Description: `
 The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument.
+
+With --checkpoint.block and --checkpoint.state, the chain is additionally started
+at a trusted post-merge block, whose state is imported from a dump produced by
+'geth snapshot dump' instead of being synced from peers. The block must be given
+in full, as returned by eth_getBlockByHash with full transactions, along with its
+receipts as returned by eth_getBlockReceipts via --checkpoint.receipts, unless it
+has no transactions. The genesis file is optional in this mode, defaulting to the
+selected network. The headers preceding the checkpoint are backfilled by the
+beacon syncer once a consensus client is attached.`,
 	}
 	dumpGenesisCommand = &cli.Command{
 		Action:    dumpGenesis,
@@ -68,6 +85,24 @@ It expects the genesis file as argument.`,
 		Description: `
 The dumpgenesis command prints the genesis configuration of the network preset
 if one is set.  Otherwise it prints the genesis from the datadir.`,
+	}
+	replayEngineCommand = &cli.Command{
+		Action:    replayEngine,
+		Name:      "replay-engine",
+		Usage:     "Replay recorded engine API traffic and report diverging responses",
+		ArgsUsage: "<recording>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			utils.GCModeFlag,
+			utils.StateSchemeFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+The replay-engine command executes the engine API calls recorded with the
+--authrpc.record flag, in order, and compares geth's responses with the recorded
+ones, reporting all calls whose responses diverged.
+
+Replaying modifies the database, which should be a copy of the one the recording
+was started on.`,
 	}
 	importCommand = &cli.Command{
 		Action:    importChain,
@@ -176,22 +211,34 @@ This command dumps out the state for a given block (or latest, if none provided)
 // initGenesis will initialise the given JSON format genesis file and writes it as
 // the zero'd block (i.e. genesis) or will fail hard if it can't succeed.
 func initGenesis(ctx *cli.Context) error {
-	if ctx.Args().Len() != 1 {
-		utils.Fatalf("need genesis.json file as the only argument")
-	}
-	genesisPath := ctx.Args().First()
-	if len(genesisPath) == 0 {
-		utils.Fatalf("invalid path to genesis file")
-	}
-	file, err := os.Open(genesisPath)
-	if err != nil {
-		utils.Fatalf("Failed to read genesis file: %v", err)
-	}
-	defer file.Close()
+	checkpoint := ctx.IsSet(utils.CheckpointBlockFlag.Name)
+	if checkpoint != ctx.IsSet(utils.CheckpointStateFlag.Name) {
+		utils.Fatalf("--%s and --%s must be used together", utils.CheckpointBlockFlag.Name, utils.CheckpointStateFlag.Name)
+	}
+	if !checkpoint && ctx.IsSet(utils.CheckpointReceiptsFlag.Name) {
+		utils.Fatalf("--%s requires --%s", utils.CheckpointReceiptsFlag.Name, utils.CheckpointBlockFlag.Name)
+	}
+	var genesis *core.Genesis
+	switch {
+	case ctx.Args().Len() == 1:
+		genesisPath := ctx.Args().First()
+		if len(genesisPath) == 0 {
+			utils.Fatalf("invalid path to genesis file")
+		}
+		file, err := os.Open(genesisPath)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file: %v", err)
+		}
+		defer file.Close()
 
-	genesis := new(core.Genesis)
-	if err := json.NewDecoder(file).Decode(genesis); err != nil {
-		utils.Fatalf("invalid genesis file: %v", err)
+		genesis = new(core.Genesis)
+		if err := json.NewDecoder(file).Decode(genesis); err != nil {
+			utils.Fatalf("invalid genesis file: %v", err)
+		}
+	case ctx.Args().Len() == 0 && checkpoint:
+		genesis = utils.MakeGenesis(ctx)
+	default:
+		utils.Fatalf("need genesis.json file as the only argument")
 	}
 	// Open and initialise both full and light databases
 	stack, _ := makeConfigNode(ctx)
@@ -205,17 +252,117 @@ func initGenesis(ctx *cli.Context) error {
 		defer chaindb.Close()
 
 		triedb := utils.MakeTrieDatabase(ctx, chaindb, ctx.Bool(utils.CachePreimagesFlag.Name), false)
-		defer triedb.Close()
-
+		scheme := triedb.Scheme()
 		_, hash, err := core.SetupGenesisBlock(chaindb, triedb, genesis)
+		triedb.Close()
 		if err != nil {
 			utils.Fatalf("Failed to write genesis block: %v", err)
 		}
 		log.Info("Successfully wrote genesis state", "database", name, "hash", hash)
+
+		// Start the full chain at the checkpoint if requested
+		if checkpoint && name == "chaindata" {
+			initCheckpoint(ctx, chaindb, scheme)
+		}
 	}
 	return nil
 }
 
+// initCheckpoint starts the chain at a trusted post-merge block, importing its
+// state and marking it as the start of the beacon sync.
+func initCheckpoint(ctx *cli.Context, db ethdb.Database, scheme string) {
+	block, td, err := readCheckpoint(ctx.String(utils.CheckpointBlockFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read checkpoint: %v", err)
+	}
+	receipts := make(types.Receipts, 0)
+	if ctx.IsSet(utils.CheckpointReceiptsFlag.Name) {
+		if receipts, err = readCheckpointReceipts(ctx.String(utils.CheckpointReceiptsFlag.Name)); err != nil {
+			utils.Fatalf("Failed to read checkpoint receipts: %v", err)
+		}
+	} else if len(block.Transactions()) > 0 {
+		utils.Fatalf("Checkpoint block has transactions, its receipts are needed via --%s", utils.CheckpointReceiptsFlag.Name)
+	}
+	dump, err := os.Open(ctx.String(utils.CheckpointStateFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to open checkpoint state: %v", err)
+	}
+	defer dump.Close()
+
+	if err := core.WriteCheckpoint(db, scheme, block, receipts, td, bufio.NewReader(dump)); err != nil {
+		utils.Fatalf("Failed to write checkpoint: %v", err)
+	}
+	downloader.WriteCheckpointSyncStatus(db, block.Header())
+	log.Info("Successfully wrote checkpoint", "number", block.Number(), "hash", block.Hash())
+}
+
+// readCheckpoint loads a block in the format returned by eth_getBlockByHash with
+// full transactions, verifying the body against the header. The total difficulty
+// is returned too if present. A header alone is rejected, as the body of the
+// block cannot be retrieved from peers.
+func readCheckpoint(path string) (*types.Block, *big.Int, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := new(types.Header)
+	if err := json.Unmarshal(blob, header); err != nil {
+		return nil, nil, err
+	}
+	var body struct {
+		Transactions    []json.RawMessage   `json:"transactions"`
+		Uncles          []common.Hash       `json:"uncles"`
+		Withdrawals     []*types.Withdrawal `json:"withdrawals"`
+		TotalDifficulty *hexutil.Big        `json:"totalDifficulty"`
+	}
+	if err := json.Unmarshal(blob, &body); err != nil {
+		return nil, nil, err
+	}
+	if body.Transactions == nil {
+		return nil, nil, errors.New("block body missing, a full block with transactions is required")
+	}
+	txs := make([]*types.Transaction, len(body.Transactions))
+	for i, enc := range body.Transactions {
+		txs[i] = new(types.Transaction)
+		if err := json.Unmarshal(enc, txs[i]); err != nil {
+			return nil, nil, fmt.Errorf("invalid transaction %d, full transactions are required: %v", i, err)
+		}
+	}
+	if hash := types.DeriveSha(types.Transactions(txs), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return nil, nil, fmt.Errorf("transaction root mismatch: have %x, want %x", hash, header.TxHash)
+	}
+	if len(body.Uncles) > 0 || header.UncleHash != types.EmptyUncleHash {
+		return nil, nil, errors.New("checkpoint block has uncles")
+	}
+	if header.WithdrawalsHash != nil {
+		if hash := types.DeriveSha(types.Withdrawals(body.Withdrawals), trie.NewStackTrie(nil)); hash != *header.WithdrawalsHash {
+			return nil, nil, fmt.Errorf("withdrawals root mismatch: have %x, want %x", hash, *header.WithdrawalsHash)
+		}
+		if body.Withdrawals == nil {
+			body.Withdrawals = make([]*types.Withdrawal, 0)
+		}
+	} else if body.Withdrawals != nil {
+		return nil, nil, errors.New("unexpected withdrawals")
+	}
+	block := types.NewBlockWithHeader(header).WithBody(txs, nil).WithWithdrawals(body.Withdrawals)
+	return block, (*big.Int)(body.TotalDifficulty), nil
+}
+
+// readCheckpointReceipts loads the receipts of a block in the format returned by
+// eth_getBlockReceipts. They are verified against the checkpoint block when it
+// is written.
+func readCheckpointReceipts(path string) (types.Receipts, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var receipts types.Receipts
+	if err := json.Unmarshal(blob, &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
 func dumpGenesis(ctx *cli.Context) error {
 	// if there is a testnet preset enabled, dump that
 	if utils.IsNetworkPreset(ctx) {
@@ -498,3 +645,39 @@ func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
 	return err != nil
 }
+
+// replayEngine replays a recording of engine API traffic against the database
+// and reports the calls whose responses diverged from the recorded ones.
+func replayEngine(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	calls, err := catalyst.ReadRecording(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read recording: %v", err)
+	}
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	// Run the node in isolation, the recorded calls being its only input
+	server := stack.Server()
+	server.MaxPeers, server.NoDiscovery, server.ListenAddr = 0, true, ""
+
+	_, eth := utils.RegisterEthService(stack, &cfg.Eth)
+	if err := stack.Start(); err != nil {
+		utils.Fatalf("Failed to start node: %v", err)
+	}
+	start := time.Now()
+	diverged, err := catalyst.Replay(catalyst.NewConsensusAPI(eth), calls)
+	for _, d := range diverged {
+		fmt.Println(d)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("Replayed engine API recording", "calls", len(calls), "diverged", len(diverged), "elapsed", common.PrettyDuration(time.Since(start)))
+	if len(diverged) > 0 {
+		return fmt.Errorf("%d of %d engine API calls diverged", len(diverged), len(calls))
+	}
+	return nil
+}
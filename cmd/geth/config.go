@@ -197,6 +197,9 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 
 	// Start the dev mode if requested, or launch the engine API for
 	// interacting with external consensus client.
+	if ctx.IsSet(utils.BeaconApiFlag.Name) && (ctx.IsSet(utils.DeveloperFlag.Name) || cfg.Eth.SyncMode == downloader.LightSync) {
+		utils.Fatalf("Beacon light sync is incompatible with developer mode and LES")
+	}
 	if ctx.IsSet(utils.DeveloperFlag.Name) {
 		simBeacon, err := catalyst.NewSimulatedBeacon(ctx.Uint64(utils.DeveloperPeriodFlag.Name), eth)
 		if err != nil {
@@ -205,10 +208,23 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		catalyst.RegisterSimulatedBeaconAPIs(stack, simBeacon)
 		stack.RegisterLifecycle(simBeacon)
 	} else if cfg.Eth.SyncMode != downloader.LightSync {
-		err := catalyst.Register(stack, eth)
+		var (
+			engineAPI = catalyst.NewConsensusAPI(eth)
+			err       error
+		)
+		if ctx.IsSet(utils.AuthRecordFlag.Name) {
+			err = catalyst.RegisterRecording(stack, engineAPI, ctx.String(utils.AuthRecordFlag.Name))
+		} else {
+			catalyst.RegisterAPI(stack, engineAPI)
+		}
 		if err != nil {
 			utils.Fatalf("failed to register catalyst service: %v", err)
 		}
+		// Follow the beacon chain through a beacon node's light client API instead
+		// of a full consensus client if requested.
+		if ctx.IsSet(utils.BeaconApiFlag.Name) {
+			utils.RegisterBeaconLightClient(stack, engineAPI, utils.MakeBeaconLightConfig(ctx))
+		}
 	}
 	return stack, backend
 }
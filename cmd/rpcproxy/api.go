@@ -0,0 +1,260 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethclient/verified"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// api is the eth namespace served by the proxy. Chain data and state are served
+// only after being verified, while advisory methods whose results cannot be
+// verified (gas prices and estimates) are forwarded to the upstream as is.
+type api struct {
+	client *verified.Client
+	config *params.ChainConfig
+}
+
+func newAPI(client *verified.Client, config *params.ChainConfig) *api {
+	return &api{client: client, config: config}
+}
+
+// resolve returns the hash of the verified block identified by a block number
+// or hash.
+func (api *api) resolve(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (common.Hash, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return hash, nil
+	}
+	number, _ := blockNrOrHash.Number()
+	header, err := api.client.HeaderByNumber(ctx, big.NewInt(number.Int64()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+// ChainId returns the chain ID of the configured network.
+func (api *api) ChainId(ctx context.Context) (*hexutil.Big, error) {
+	id, err := api.client.ChainID(ctx)
+	return (*hexutil.Big)(id), err
+}
+
+// BlockNumber returns the number of the trusted head.
+func (api *api) BlockNumber(ctx context.Context) (hexutil.Uint64, error) {
+	number, err := api.client.BlockNumber(ctx)
+	return hexutil.Uint64(number), err
+}
+
+// GetBlockByNumber returns a verified canonical block.
+func (api *api) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	block, err := api.client.BlockByNumber(ctx, big.NewInt(number.Int64()))
+	if err != nil {
+		return nil, err
+	}
+	return ethapi.RPCMarshalBlock(block, true, fullTx, api.config), nil
+}
+
+// GetBlockByHash returns a verified canonical block.
+func (api *api) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block, err := api.client.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return ethapi.RPCMarshalBlock(block, true, fullTx, api.config), nil
+}
+
+// GetBalance returns the verified balance of an account.
+func (api *api) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	hash, err := api.resolve(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := api.client.BalanceAtHash(ctx, address, hash)
+	return (*hexutil.Big)(balance), err
+}
+
+// GetTransactionCount returns the verified nonce of an account.
+func (api *api) GetTransactionCount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	hash, err := api.resolve(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := api.client.NonceAtHash(ctx, address, hash)
+	return (*hexutil.Uint64)(&nonce), err
+}
+
+// GetCode returns the verified code of an account.
+func (api *api) GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	hash, err := api.resolve(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return api.client.CodeAtHash(ctx, address, hash)
+}
+
+// GetStorageAt returns the verified value of a storage slot of an account.
+func (api *api) GetStorageAt(ctx context.Context, address common.Address, key string, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	slot, err := hexutil.DecodeBig(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage key %q: %v", key, err)
+	}
+	hash, err := api.resolve(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return api.client.StorageAtHash(ctx, address, common.BigToHash(slot), hash)
+}
+
+// callArgs are the arguments of a message call.
+type callArgs struct {
+	From                 *common.Address   `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  *hexutil.Uint64   `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big      `json:"value"`
+	Data                 *hexutil.Bytes    `json:"data"`
+	Input                *hexutil.Bytes    `json:"input"`
+	AccessList           *types.AccessList `json:"accessList"`
+}
+
+// toCallMsg converts the call arguments into a call message.
+func (args *callArgs) toCallMsg() (ethereum.CallMsg, error) {
+	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
+		return ethereum.CallMsg{}, errors.New(`both "data" and "input" are set and not equal`)
+	}
+	msg := ethereum.CallMsg{
+		To:        args.To,
+		GasPrice:  (*big.Int)(args.GasPrice),
+		GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+		GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+		Value:     (*big.Int)(args.Value),
+	}
+	if args.From != nil {
+		msg.From = *args.From
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	if args.Input != nil {
+		msg.Data = *args.Input
+	} else if args.Data != nil {
+		msg.Data = *args.Data
+	}
+	if args.AccessList != nil {
+		msg.AccessList = *args.AccessList
+	}
+	return msg, nil
+}
+
+// revertError is an API error that encompasses an EVM revertal with JSON error
+// code and a binary data blob.
+type revertError struct {
+	error
+	reason string // revert reason hex encoded
+}
+
+// ErrorCode returns the JSON error code for a revertal.
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert reason.
+func (e *revertError) ErrorData() interface{} {
+	return e.reason
+}
+
+// Call executes a message call locally over the verified state.
+func (api *api) Call(ctx context.Context, args callArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	msg, err := args.toCallMsg()
+	if err != nil {
+		return nil, err
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	hash, err := api.resolve(ctx, *blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := api.client.CallContractAtHash(ctx, msg, hash)
+	if errors.Is(err, vm.ErrExecutionReverted) && len(ret) > 0 {
+		err = vm.ErrExecutionReverted
+		if reason, errUnpack := abi.UnpackRevert(ret); errUnpack == nil {
+			err = fmt.Errorf("%w: %v", vm.ErrExecutionReverted, reason)
+		}
+		return nil, &revertError{error: err, reason: hexutil.Encode(ret)}
+	}
+	return ret, err
+}
+
+// SendRawTransaction forwards a signed transaction to the upstream, verifying
+// that it was accepted under the correct hash.
+func (api *api) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), api.client.SendTransaction(ctx, tx)
+}
+
+// forward relays a method call to the upstream without verification.
+func (api *api) forward(ctx context.Context, method string, args ...interface{}) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := api.client.Client().CallContext(ctx, &result, method, args...)
+	return result, err
+}
+
+// GasPrice forwards the gas price suggestion of the upstream.
+func (api *api) GasPrice(ctx context.Context) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_gasPrice")
+}
+
+// MaxPriorityFeePerGas forwards the priority fee suggestion of the upstream.
+func (api *api) MaxPriorityFeePerGas(ctx context.Context) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_maxPriorityFeePerGas")
+}
+
+// FeeHistory forwards the fee history of the upstream.
+func (api *api) FeeHistory(ctx context.Context, blockCount json.RawMessage, lastBlock json.RawMessage, rewardPercentiles []float64) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_feeHistory", blockCount, lastBlock, rewardPercentiles)
+}
+
+// EstimateGas forwards the gas estimate of the upstream.
+func (api *api) EstimateGas(ctx context.Context, args json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	if blockNrOrHash == nil {
+		return api.forward(ctx, "eth_estimateGas", args)
+	}
+	return api.forward(ctx, "eth_estimateGas", args, blockNrOrHash)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/verified"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that transactions sent through the proxy reach the upstream pool.
+func TestSendRawTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	stack, err := node.New(&node.Config{
+		P2P: p2p.Config{NoDiscovery: true, MaxPeers: 0},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+	genesis := core.DeveloperGenesisBlock(10_000_000, addr)
+	backend, err := eth.New(stack, &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync})
+	if err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	client := verified.NewClient(stack.Attach(), genesis.Config)
+	defer client.Close()
+	if err := client.SetCheckpoint(context.Background(), genesis.ToBlock().Hash()); err != nil {
+		t.Fatalf("failed to set checkpoint: %v", err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", newAPI(client, genesis.Config)); err != nil {
+		t.Fatal(err)
+	}
+	proxy := rpc.DialInProc(server)
+	defer proxy.Close()
+
+	tx, err := types.SignNewTx(key, types.LatestSigner(genesis.Config), &types.DynamicFeeTx{
+		ChainID:   genesis.Config.ChainID,
+		Gas:       params.TxGas,
+		GasFeeCap: big.NewInt(params.InitialBaseFee * 2),
+		GasTipCap: big.NewInt(params.GWei),
+		To:        &common.Address{0xff},
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	data, _ := tx.MarshalBinary()
+
+	var hash common.Hash
+	if err := proxy.Call(&hash, "eth_sendRawTransaction", hexutil.Bytes(data)); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if hash != tx.Hash() {
+		t.Fatalf("transaction hash mismatch: have %x, want %x", hash, tx.Hash())
+	}
+	if backend.TxPool().Get(tx.Hash()) == nil {
+		t.Fatal("transaction not added to the upstream pool")
+	}
+}
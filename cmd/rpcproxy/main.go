@@ -0,0 +1,150 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// rpcproxy is a JSON-RPC proxy which verifies the responses of an untrusted
+// provider against a trusted checkpoint or the head followed by a beacon light
+// client, serving only data which could be verified.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/beacon/blsync"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient/verified"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	upstreamFlag = &cli.StringFlag{
+		Name:     "rpc",
+		Usage:    "Untrusted upstream RPC endpoint, which must support eth_getProof",
+		Required: true,
+	}
+	checkpointFlag = &cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "Trusted execution block hash to verify against, if no beacon light client is used",
+	}
+	verbosityFlag = &cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: 3,
+	}
+)
+
+var app = flags.NewApp("verifying Ethereum JSON-RPC proxy")
+
+func init() {
+	app.Flags = []cli.Flag{
+		upstreamFlag,
+		checkpointFlag,
+		verbosityFlag,
+		utils.HTTPListenAddrFlag,
+		utils.HTTPPortFlag,
+		utils.MainnetFlag,
+		utils.SepoliaFlag,
+		utils.BeaconApiFlag,
+		utils.BeaconApiHeaderFlag,
+		utils.BeaconThresholdFlag,
+		utils.BeaconConfigFlag,
+		utils.BeaconGenesisRootFlag,
+		utils.BeaconGenesisTimeFlag,
+		utils.BeaconCheckpointFlag,
+	}
+	app.Action = proxy
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// proxy connects to the upstream provider, establishes the trusted head and
+// serves the verified API until interrupted.
+func proxy(ctx *cli.Context) error {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(true)))
+	glogger.Verbosity(log.Lvl(ctx.Int(verbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	config := params.MainnetChainConfig
+	if ctx.Bool(utils.SepoliaFlag.Name) {
+		config = params.SepoliaChainConfig
+	}
+	upstream, err := rpc.DialContext(ctx.Context, ctx.String(upstreamFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to connect to upstream: %v", err)
+	}
+	client := verified.NewClient(upstream, config)
+	defer client.Close()
+
+	// Establish the trusted head, either statically or via beacon light sync
+	switch {
+	case ctx.IsSet(utils.BeaconApiFlag.Name):
+		blsyncer := blsync.NewClient(utils.MakeBeaconLightConfig(ctx), client)
+		if err := blsyncer.Start(); err != nil {
+			return err
+		}
+		defer blsyncer.Stop()
+
+	case ctx.IsSet(checkpointFlag.Name):
+		hash, err := hexutil.Decode(ctx.String(checkpointFlag.Name))
+		if err != nil || len(hash) != common.HashLength {
+			return fmt.Errorf("invalid checkpoint block hash: %s", ctx.String(checkpointFlag.Name))
+		}
+		if err := client.SetCheckpoint(ctx.Context, common.BytesToHash(hash)); err != nil {
+			return fmt.Errorf("failed to set checkpoint: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("either --%s or --%s is required", checkpointFlag.Name, utils.BeaconApiFlag.Name)
+	}
+	// Serve the verified API over HTTP
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", newAPI(client, config)); err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(ctx.String(utils.HTTPListenAddrFlag.Name), fmt.Sprint(ctx.Int(utils.HTTPPortFlag.Name)))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: server}
+	go httpServer.Serve(listener)
+	defer httpServer.Shutdown(context.Background())
+
+	log.Info("Verifying RPC proxy started", "endpoint", "http://"+listener.Addr().String(), "upstream", ctx.String(upstreamFlag.Name))
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	<-sigc
+	log.Info("Shutting down verifying RPC proxy")
+	return nil
+}
@@ -37,17 +37,20 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/beacon/blsync"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/bumper"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
@@ -173,6 +176,16 @@ var (
 		Value:    11500000,
 		Category: flags.DevCategory,
 	}
+	DeveloperForkFlag = &cli.StringFlag{
+		Name:     "dev.fork",
+		Usage:    "RPC endpoint of a remote chain to lazily fork the developer chain's state from",
+		Category: flags.DevCategory,
+	}
+	DeveloperForkBlockFlag = &cli.Uint64Flag{
+		Name:     "dev.fork.block",
+		Usage:    "Remote block number to fork the state at (default = latest)",
+		Category: flags.DevCategory,
+	}
 
 	IdentityFlag = &cli.StringFlag{
 		Name:     "identity",
@@ -205,6 +218,18 @@ var (
 		Name:  "incompletes",
 		Usage: "Include accounts for which we don't have the address (missing preimage)",
 	}
+	CheckpointBlockFlag = &cli.StringFlag{
+		Name:  "checkpoint.block",
+		Usage: "Path to a trusted post-merge block to start the chain at, as returned by eth_getBlockByHash with full transactions (a header alone is not enough)",
+	}
+	CheckpointReceiptsFlag = &cli.StringFlag{
+		Name:  "checkpoint.receipts",
+		Usage: "Path to the receipts of the checkpoint block, as returned by eth_getBlockReceipts (required if the block has transactions)",
+	}
+	CheckpointStateFlag = &cli.StringFlag{
+		Name:  "checkpoint.state",
+		Usage: "Path to the state of the checkpoint block, as produced by 'geth snapshot dump'",
+	}
 	ExcludeCodeFlag = &cli.BoolFlag{
 		Name:  "nocode",
 		Usage: "Exclude contract code (save db lookups)",
@@ -283,6 +308,37 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "history.logindex",
+		Usage:    "Enable the index of logs by address and topic for eth_getLogs (the bloom bits are still maintained)",
+		Category: flags.StateCategory,
+	}
+	LogHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.logs",
+		Usage:    "Number of recent blocks to maintain log index for (default = about one year, 0 = entire chain)",
+		Value:    ethconfig.Defaults.LogHistory,
+		Category: flags.StateCategory,
+	}
+	TokenIndexFlag = &cli.BoolFlag{
+		Name:     "history.tokens",
+		Usage:    "Enable the index of token transfers, covering the blocks of the log index (implies --history.logindex)",
+		Category: flags.StateCategory,
+	}
+	AddressIndexFlag = &cli.BoolFlag{
+		Name:     "history.addresses",
+		Usage:    "Enable the index of transactions by address, covering the blocks of the transaction index (internal calls only in blocks executed from then on)",
+		Category: flags.StateCategory,
+	}
+	ContractIndexFlag = &cli.BoolFlag{
+		Name:     "history.contracts",
+		Usage:    "Enable the index of contract creators, covering the blocks executed from then on",
+		Category: flags.StateCategory,
+	}
+	SenderIndexFlag = &cli.BoolFlag{
+		Name:     "history.senders",
+		Usage:    "Enable the index of transactions by sender and nonce, covering the blocks of the transaction index",
+		Category: flags.StateCategory,
+	}
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
@@ -318,6 +374,43 @@ var (
 		Usage:    "Enables serving light clients before syncing",
 		Category: flags.LightCategory,
 	}
+	// Beacon client light sync settings
+	BeaconApiFlag = &cli.StringFlag{
+		Name:     "beacon.api",
+		Usage:    "Beacon node light client API URL. This flag enables beacon light client sync (blsync)",
+		Category: flags.BeaconCategory,
+	}
+	BeaconApiHeaderFlag = &cli.StringSliceFlag{
+		Name:     "beacon.api.header",
+		Usage:    "Pass custom HTTP header fields to the beacon node API in \"key:value\" format. This flag can be given multiple times.",
+		Category: flags.BeaconCategory,
+	}
+	BeaconThresholdFlag = &cli.IntFlag{
+		Name:     "beacon.threshold",
+		Usage:    "Beacon sync committee participation threshold",
+		Value:    blsync.DefaultThreshold,
+		Category: flags.BeaconCategory,
+	}
+	BeaconConfigFlag = &cli.StringFlag{
+		Name:     "beacon.config",
+		Usage:    "Beacon chain config YAML file",
+		Category: flags.BeaconCategory,
+	}
+	BeaconGenesisRootFlag = &cli.StringFlag{
+		Name:     "beacon.genesis.gvroot",
+		Usage:    "Beacon chain genesis validators root",
+		Category: flags.BeaconCategory,
+	}
+	BeaconGenesisTimeFlag = &cli.Uint64Flag{
+		Name:     "beacon.genesis.time",
+		Usage:    "Beacon chain genesis time",
+		Category: flags.BeaconCategory,
+	}
+	BeaconCheckpointFlag = &cli.StringFlag{
+		Name:     "beacon.checkpoint",
+		Usage:    "Beacon chain weak subjectivity checkpoint block root",
+		Category: flags.BeaconCategory,
+	}
 	// Transaction pool settings
 	TxPoolLocalsFlag = &cli.StringFlag{
 		Name:     "txpool.locals",
@@ -341,6 +434,17 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Rejournal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolRemoteJournalFlag = &cli.StringFlag{
+		Name:     "txpool.remotejournal",
+		Usage:    "Disk snapshot of remote transactions to survive node restarts (disabled if empty)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolRemoteJournalSlotsFlag = &cli.Uint64Flag{
+		Name:     "txpool.remotejournalslots",
+		Usage:    "Maximum number of remote transaction slots to persist across restarts",
+		Value:    ethconfig.Defaults.TxPool.RemoteJournalSlots,
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolPriceLimitFlag = &cli.Uint64Flag{
 		Name:     "txpool.pricelimit",
 		Usage:    "Minimum gas price tip to enforce for acceptance into the pool",
@@ -383,6 +487,29 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPolicyFlag = &cli.StringFlag{
+		Name:     "txpool.policy",
+		Usage:    "JSON file with the transaction admission policy (reloadable via admin_reloadTxPolicy)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolBumpBlocksFlag = &cli.Uint64Flag{
+		Name:     "txpool.bumpblocks",
+		Usage:    "Number of blocks after which stuck eth_sendTransaction transactions are re-signed with higher fees (0 = disabled)",
+		Value:    ethconfig.Defaults.TxBumper.Blocks,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolBumpPercentFlag = &cli.Uint64Flag{
+		Name:     "txpool.bumppercent",
+		Usage:    "Fee increase percentage applied when re-signing stuck transactions (at least --txpool.pricebump)",
+		Value:    ethconfig.Defaults.TxBumper.Percent,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolBumpCeilingFlag = &flags.BigFlag{
+		Name:     "txpool.bumpceiling",
+		Usage:    "Maximum gas price or fee cap (in wei) stuck transactions may be bumped to",
+		Value:    ethconfig.Defaults.TxBumper.Ceiling,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -402,6 +529,12 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	BlobPoolArchiveFlag = &cli.Uint64Flag{
+		Name:     "blobpool.archive",
+		Usage:    "Number of blocks to archive included blobs for serving over RPC (0 = disabled)",
+		Value:    ethconfig.Defaults.BlobPool.ArchiveRetention,
+		Category: flags.BlobPoolCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -553,6 +686,18 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCGlobalLogQueryLimitFlag = &cli.IntFlag{
+		Name:     "rpc.logquerylimit",
+		Usage:    "Sets the maximum number of logs returned by a log query or page, including eth_getFilterLogs and GraphQL (0 = no limit)",
+		Value:    ethconfig.Defaults.RPCLogQueryLimit,
+		Category: flags.APICategory,
+	}
+	RPCGlobalLogRangeLimitFlag = &cli.Uint64Flag{
+		Name:     "rpc.lograngelimit",
+		Usage:    "Sets the maximum block range of a log query or page, including eth_getFilterLogs and GraphQL (0 = no limit)",
+		Value:    ethconfig.Defaults.RPCLogRangeLimit,
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -577,6 +722,11 @@ var (
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	AuthRecordFlag = &cli.StringFlag{
+		Name:     "authrpc.record",
+		Usage:    "Path to a file to record all engine API calls and responses into, for replaying with 'geth replay-engine'",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -609,6 +759,11 @@ var (
 		Usage:    "Filename for IPC socket/pipe within the datadir (explicit paths escape it)",
 		Category: flags.APICategory,
 	}
+	IPCMethodsFlag = &cli.StringFlag{
+		Name:     "ipc.methods",
+		Usage:    "Comma separated list of methods offered over the IPC-RPC interface. Accepts '*' suffix wildcards, '!' prefix denies methods.",
+		Category: flags.APICategory,
+	}
 	HTTPEnabledFlag = &cli.BoolFlag{
 		Name:     "http",
 		Usage:    "Enable the HTTP-RPC server",
@@ -644,6 +799,11 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTPMethodsFlag = &cli.StringFlag{
+		Name:     "http.methods",
+		Usage:    "Comma separated list of methods offered over the HTTP-RPC interface. Accepts '*' suffix wildcards, '!' prefix denies methods.",
+		Category: flags.APICategory,
+	}
 	HTTPPathPrefixFlag = &cli.StringFlag{
 		Name:     "http.rpcprefix",
 		Usage:    "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
@@ -690,6 +850,11 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSMethodsFlag = &cli.StringFlag{
+		Name:     "ws.methods",
+		Usage:    "Comma separated list of methods offered over the WS-RPC interface. Accepts '*' suffix wildcards, '!' prefix denies methods.",
+		Category: flags.APICategory,
+	}
 	WSAllowedOriginsFlag = &cli.StringFlag{
 		Name:     "ws.origins",
 		Usage:    "Origins from which to accept websockets requests",
@@ -729,6 +894,27 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCJWTKeysFlag = &cli.StringFlag{
+		Name:     "rpc.jwtkeys",
+		Usage:    "Comma separated list of named JWT keys (name=path) required to access the HTTP-RPC and WS-RPC interfaces, with tokens carrying the permissions of the client",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimits",
+		Usage:    "Comma separated per-client call limits of the HTTP and WS endpoints (e.g. 'debug_trace*|eth_getLogs=5:10:2' for 5 calls/s, bursts of 10 and 2 concurrent calls)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitIdentityFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimits.identity",
+		Usage:    "How clients are identified for rate limiting (ip, jwt, header:<name>), jwt requires --rpc.jwtkeys and header --rpc.ratelimits.keys",
+		Value:    "ip",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitKeysFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimits.keys",
+		Usage:    "Path to the file listing the API keys accepted as client identities for rate limiting, one per line",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -1144,6 +1330,10 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		cfg.HTTPModules = SplitAndTrim(ctx.String(HTTPApiFlag.Name))
 	}
 
+	if ctx.IsSet(HTTPMethodsFlag.Name) {
+		cfg.HTTPMethods = SplitAndTrim(ctx.String(HTTPMethodsFlag.Name))
+	}
+
 	if ctx.IsSet(HTTPVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = SplitAndTrim(ctx.String(HTTPVirtualHostsFlag.Name))
 	}
@@ -1162,6 +1352,64 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCRateLimitsFlag.Name) {
+		limits, err := parseRateLimits(ctx.String(RPCRateLimitsFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", RPCRateLimitsFlag.Name, err)
+		}
+		cfg.RPCRateLimits = limits
+	}
+	if ctx.IsSet(RPCRateLimitIdentityFlag.Name) {
+		cfg.RPCRateLimitIdentity = ctx.String(RPCRateLimitIdentityFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitKeysFlag.Name) {
+		cfg.RPCRateLimitKeyFile = ctx.String(RPCRateLimitKeysFlag.Name)
+	}
+	if ctx.IsSet(RPCJWTKeysFlag.Name) {
+		cfg.RPCJWTKeys = make(map[string]string)
+		for _, entry := range SplitAndTrim(ctx.String(RPCJWTKeysFlag.Name)) {
+			name, path, ok := strings.Cut(entry, "=")
+			if !ok || name == "" || path == "" {
+				Fatalf("Option %q: invalid key %q", RPCJWTKeysFlag.Name, entry)
+			}
+			cfg.RPCJWTKeys[name] = path
+		}
+	}
+}
+
+// parseRateLimits parses a comma separated list of RPC rate limits, each in the
+// form methods=rate:burst:concurrency with the methods separated by '|'. Trailing
+// fields may be omitted, and zero disables the limit.
+func parseRateLimits(input string) ([]rpc.RateLimit, error) {
+	var limits []rpc.RateLimit
+	for _, rule := range SplitAndTrim(input) {
+		methods, values, ok := strings.Cut(rule, "=")
+		if !ok || methods == "" {
+			return nil, fmt.Errorf("invalid rate limit %q", rule)
+		}
+		limit := rpc.RateLimit{Methods: strings.Split(methods, "|")}
+		fields := strings.Split(values, ":")
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("invalid rate limit %q", rule)
+		}
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(fields[0], 64); err != nil || limit.Rate < 0 {
+			return nil, fmt.Errorf("invalid rate in %q", rule)
+		}
+		if len(fields) > 1 {
+			if limit.Burst, err = strconv.Atoi(fields[1]); err != nil || limit.Burst < 0 {
+				return nil, fmt.Errorf("invalid burst in %q", rule)
+			}
+		}
+		if len(fields) > 2 {
+			if limit.Concurrent, err = strconv.Atoi(fields[2]); err != nil || limit.Concurrent < 0 {
+				return nil, fmt.Errorf("invalid concurrency in %q", rule)
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1196,6 +1444,10 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 		cfg.WSModules = SplitAndTrim(ctx.String(WSApiFlag.Name))
 	}
 
+	if ctx.IsSet(WSMethodsFlag.Name) {
+		cfg.WSMethods = SplitAndTrim(ctx.String(WSMethodsFlag.Name))
+	}
+
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
@@ -1211,6 +1463,9 @@ func setIPC(ctx *cli.Context, cfg *node.Config) {
 	case ctx.IsSet(IPCPathFlag.Name):
 		cfg.IPCPath = ctx.String(IPCPathFlag.Name)
 	}
+	if ctx.IsSet(IPCMethodsFlag.Name) {
+		cfg.IPCMethods = SplitAndTrim(ctx.String(IPCMethodsFlag.Name))
+	}
 }
 
 // setLes configures the les server and ultra light client settings from the command line flags.
@@ -1523,6 +1778,12 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.IsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.String(TxPoolRemoteJournalFlag.Name)
+	}
+	if ctx.IsSet(TxPoolRemoteJournalSlotsFlag.Name) {
+		cfg.RemoteJournalSlots = ctx.Uint64(TxPoolRemoteJournalSlotsFlag.Name)
+	}
 	if ctx.IsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.Uint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1546,6 +1807,33 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	}
 }
 
+func setTxBumper(ctx *cli.Context, cfg *bumper.Config) {
+	if ctx.IsSet(TxPoolBumpBlocksFlag.Name) {
+		cfg.Blocks = ctx.Uint64(TxPoolBumpBlocksFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBumpPercentFlag.Name) {
+		cfg.Percent = ctx.Uint64(TxPoolBumpPercentFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBumpCeilingFlag.Name) {
+		cfg.Ceiling = flags.GlobalBig(ctx, TxPoolBumpCeilingFlag.Name)
+	}
+}
+
+func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
+	if ctx.IsSet(BlobPoolDataDirFlag.Name) {
+		cfg.Datadir = ctx.String(BlobPoolDataDirFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolDataCapFlag.Name) {
+		cfg.Datacap = ctx.Uint64(BlobPoolDataCapFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolPriceBumpFlag.Name) {
+		cfg.PriceBump = ctx.Uint64(BlobPoolPriceBumpFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolArchiveFlag.Name) {
+		cfg.ArchiveRetention = ctx.Uint64(BlobPoolArchiveFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.String(MinerExtraDataFlag.Name))
@@ -1644,6 +1932,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO, ctx.String(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
+	if ctx.IsSet(TxPoolPolicyFlag.Name) {
+		cfg.TxPoolPolicy = ctx.String(TxPoolPolicyFlag.Name)
+	}
+	setTxBumper(ctx, &cfg.TxBumper)
+	setBlobPool(ctx, &cfg.BlobPool)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1725,6 +2018,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		cfg.TransactionHistory = 0
 		log.Warn("Disabled transaction unindexing for archive node")
 	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
+	if ctx.IsSet(LogHistoryFlag.Name) {
+		cfg.LogHistory = ctx.Uint64(LogHistoryFlag.Name)
+	}
+	if ctx.IsSet(TokenIndexFlag.Name) {
+		cfg.TokenIndex = ctx.Bool(TokenIndexFlag.Name)
+	}
+	if ctx.IsSet(AddressIndexFlag.Name) {
+		cfg.AddressIndex = ctx.Bool(AddressIndexFlag.Name)
+	}
+	if ctx.IsSet(ContractIndexFlag.Name) {
+		cfg.ContractIndex = ctx.Bool(ContractIndexFlag.Name)
+	}
+	if ctx.IsSet(SenderIndexFlag.Name) {
+		cfg.SenderIndex = ctx.Bool(SenderIndexFlag.Name)
+	}
 	if ctx.IsSet(LightServeFlag.Name) && cfg.TransactionHistory != 0 {
 		log.Warn("LES server cannot serve old transaction status and cannot connect below les/4 protocol version if transaction lookup index is limited")
 	}
@@ -1771,6 +2082,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCGlobalLogQueryLimitFlag.Name) {
+		cfg.RPCLogQueryLimit = ctx.Int(RPCGlobalLogQueryLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCGlobalLogRangeLimitFlag.Name) {
+		cfg.RPCLogRangeLimit = ctx.Uint64(RPCGlobalLogRangeLimitFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1861,6 +2178,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		if !ctx.IsSet(MinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
+		// Fork the state of a remote chain if requested
+		if ctx.IsSet(DeveloperForkFlag.Name) {
+			if cfg.StateScheme != rawdb.HashScheme {
+				Fatalf("--%s requires the %s state scheme", DeveloperForkFlag.Name, rawdb.HashScheme)
+			}
+			cfg.DevFork = ctx.String(DeveloperForkFlag.Name)
+			if ctx.IsSet(DeveloperForkBlockFlag.Name) {
+				number := ctx.Uint64(DeveloperForkBlockFlag.Name)
+				cfg.DevForkBlock = &number
+			}
+			cfg.SnapshotCache = 0
+		}
 	default:
 		if cfg.NetworkId == 1 {
 			SetDNSDiscoveryDefaults(cfg, params.MainnetGenesisHash)
@@ -1937,7 +2266,9 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	isLightClient := ethcfg.SyncMode == downloader.LightSync
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize: ethcfg.FilterLogCacheSize,
+		LogCacheSize:  ethcfg.FilterLogCacheSize,
+		LogQueryLimit: ethcfg.RPCLogQueryLimit,
+		LogRangeLimit: ethcfg.RPCLogRangeLimit,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -1946,6 +2277,64 @@ func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconf
 	return filterSystem
 }
 
+// RegisterBeaconLightClient adds the beacon light client into the node, driving
+// the execution client through the given engine API instance. The instance is
+// shared with the engine API served over RPC, so that both operate on the same
+// payload and forkchoice state.
+func RegisterBeaconLightClient(stack *node.Node, api *catalyst.ConsensusAPI, config *blsync.Config) {
+	stack.RegisterLifecycle(blsync.NewClient(config, catalyst.NewHeaderEngine(api)))
+}
+
+// MakeBeaconLightConfig assembles the beacon light client configuration from
+// the command line flags.
+func MakeBeaconLightConfig(ctx *cli.Context) *blsync.Config {
+	var config blsync.Config
+	switch {
+	case ctx.IsSet(BeaconConfigFlag.Name):
+		if !ctx.IsSet(BeaconGenesisRootFlag.Name) || !ctx.IsSet(BeaconGenesisTimeFlag.Name) {
+			Fatalf("Custom beacon chain config requires both --%s and --%s", BeaconGenesisRootFlag.Name, BeaconGenesisTimeFlag.Name)
+		}
+		root, err := hexutil.Decode(ctx.String(BeaconGenesisRootFlag.Name))
+		if err != nil || len(root) != common.HashLength {
+			Fatalf("Invalid beacon genesis validators root: %s", ctx.String(BeaconGenesisRootFlag.Name))
+		}
+		config.GenesisValidatorsRoot = common.BytesToHash(root)
+		config.GenesisTime = ctx.Uint64(BeaconGenesisTimeFlag.Name)
+		if err := config.LoadForks(ctx.String(BeaconConfigFlag.Name)); err != nil {
+			Fatalf("Failed to load beacon chain config: %v", err)
+		}
+	case ctx.Bool(SepoliaFlag.Name):
+		config.ChainConfig = *blsync.SepoliaConfig
+	case ctx.Bool(GoerliFlag.Name):
+		Fatalf("Beacon light sync is not supported on the selected network, use --%s", BeaconConfigFlag.Name)
+	default:
+		config.ChainConfig = *blsync.MainnetConfig
+	}
+	if !ctx.IsSet(BeaconCheckpointFlag.Name) {
+		Fatalf("Beacon light sync requires a checkpoint, use --%s", BeaconCheckpointFlag.Name)
+	}
+	checkpoint, err := hexutil.Decode(ctx.String(BeaconCheckpointFlag.Name))
+	if err != nil || len(checkpoint) != common.HashLength {
+		Fatalf("Invalid beacon checkpoint block root: %s", ctx.String(BeaconCheckpointFlag.Name))
+	}
+	config.Checkpoint = common.BytesToHash(checkpoint)
+	config.ApiURL = ctx.String(BeaconApiFlag.Name)
+	config.Threshold = ctx.Int(BeaconThresholdFlag.Name)
+
+	headers := ctx.StringSlice(BeaconApiHeaderFlag.Name)
+	if len(headers) > 0 {
+		config.CustomHeaders = make(map[string]string)
+		for _, header := range headers {
+			key, value, ok := strings.Cut(header, ":")
+			if !ok {
+				Fatalf("Invalid beacon API header: %q", header)
+			}
+			config.CustomHeaders[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return &config
+}
+
 // RegisterFullSyncTester adds the full-sync tester service into node.
 func RegisterFullSyncTester(stack *node.Node, eth *eth.Ethereum, path string) {
 	blob, err := os.ReadFile(path)
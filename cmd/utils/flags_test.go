@@ -18,8 +18,13 @@
 package utils
 
 import (
+	"flag"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -62,3 +67,60 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestSetBlobPool(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range []cli.Flag{BlobPoolDataDirFlag, BlobPoolDataCapFlag, BlobPoolPriceBumpFlag, BlobPoolArchiveFlag} {
+		if err := f.Apply(set); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"--blobpool.datadir", "blobs", "--blobpool.datacap", "1024", "--blobpool.pricebump", "50", "--blobpool.archive", "64"}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	cfg := blobpool.DefaultConfig
+	setBlobPool(cli.NewContext(nil, set, nil), &cfg)
+
+	want := blobpool.Config{Datadir: "blobs", Datacap: 1024, PriceBump: 50, ArchiveRetention: 64}
+	if cfg != want {
+		t.Errorf("blob pool config mismatch: have %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []rpc.RateLimit
+		fail  bool
+	}{
+		{input: "", want: nil},
+		{input: "eth_call=10", want: []rpc.RateLimit{{Methods: []string{"eth_call"}, Rate: 10}}},
+		{
+			input: "debug_trace*|eth_getLogs=0.5:10:2, eth_call=0:0:4",
+			want: []rpc.RateLimit{
+				{Methods: []string{"debug_trace*", "eth_getLogs"}, Rate: 0.5, Burst: 10, Concurrent: 2},
+				{Methods: []string{"eth_call"}, Concurrent: 4},
+			},
+		},
+		{input: "eth_call", fail: true},
+		{input: "=1", fail: true},
+		{input: "eth_call=x", fail: true},
+		{input: "eth_call=1:-1", fail: true},
+		{input: "eth_call=1:1:1:1", fail: true},
+	}
+	for _, tt := range tests {
+		have, err := parseRateLimits(tt.input)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%q: expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+		} else if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%q: limits mismatch: have %+v, want %+v", tt.input, have, tt.want)
+		}
+	}
+}
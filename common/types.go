@@ -454,13 +454,19 @@ func (addr AddressEIP55) MarshalJSON() ([]byte, error) {
 	return json.Marshal(addr.String())
 }
 
+// Decimal is an unsigned integer encoded as a quoted decimal string in JSON.
 type Decimal uint64
 
 func isString(input []byte) bool {
 	return len(input) >= 2 && input[0] == '"' && input[len(input)-1] == '"'
 }
 
-// UnmarshalJSON parses a hash in hex syntax.
+// MarshalJSON encodes the integer as a quoted decimal string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(d), 10))
+}
+
+// UnmarshalJSON parses a quoted decimal string.
 func (d *Decimal) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return &json.UnmarshalTypeError{Value: "non-string", Type: reflect.TypeOf(uint64(0))}
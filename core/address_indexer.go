@@ -0,0 +1,220 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// addressThrottling is the time to wait between processing two consecutive
+	// address index sections. Sections are small, so it's lower than for blooms.
+	addressThrottling = 10 * time.Millisecond
+)
+
+// Roles an address can have in a transaction, combined as a bitmask in the
+// address activity index.
+const (
+	AddressRoleSender    byte = 1 << iota // Address signed the transaction
+	AddressRoleRecipient                  // Address is the recipient of the transaction
+	AddressRoleCreated                    // Address is the contract created by the transaction
+	AddressRoleEmitter                    // Address emitted a log, e.g. when reached via an internal call
+	AddressRoleCalled                     // Address was called internally, by a contract
+)
+
+// TransactionActivity returns the addresses active in an executed transaction,
+// along with their roles. The accounts called internally are the ones recorded
+// when the transaction was executed on import, see CacheConfig.InternalCalls.
+// If they were not recorded, contracts reached through internal calls are only
+// included if they emitted a log.
+func TransactionActivity(signer types.Signer, tx *types.Transaction, logs []*types.Log, calls []common.Address) (map[common.Address]byte, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	active := map[common.Address]byte{from: AddressRoleSender}
+	if to := tx.To(); to != nil {
+		active[*to] |= AddressRoleRecipient
+	} else {
+		active[crypto.CreateAddress(from, tx.Nonce())] |= AddressRoleCreated
+	}
+	for _, entry := range logs {
+		active[entry.Address] |= AddressRoleEmitter
+	}
+	for _, address := range calls {
+		active[address] |= AddressRoleCalled
+	}
+	return active, nil
+}
+
+// AddressIndexer implements a core.ChainIndexer, building up an index of the
+// transactions each address was active in, for the blocks within the transaction
+// lookup limit of the chain. Internal calls are indexed for the blocks executed
+// with CacheConfig.InternalCalls enabled, the recorded calls are pruned along
+// with the index.
+type AddressIndexer struct {
+	chain   *BlockChain                 // blockchain to retrieve the configuration and lookup limit from
+	db      ethdb.Database              // database instance to write index data and metadata into
+	size    uint64                      // section size to generate the index for
+	section uint64                      // Section is the section number being processed currently
+	batch   ethdb.Batch                 // batch of index entries of the current section
+	active  map[common.Address]struct{} // addresses active in the current section
+	tail    *uint64                     // new index tail if blocks of the current section were skipped
+}
+
+// NewAddressIndexer returns a chain indexer that generates the address activity
+// index for the canonical chain. If nothing was indexed yet, indexing starts at
+// the transaction lookup limit of the chain.
+func NewAddressIndexer(chain *BlockChain, size, confirms uint64) *ChainIndexer {
+	backend := &AddressIndexer{
+		chain: chain,
+		db:    chain.db,
+		size:  size,
+	}
+	table := rawdb.NewTable(chain.db, string(rawdb.AddressIndexPrefix))
+	indexer := NewChainIndexer(chain.db, table, backend, size, confirms, addressThrottling, "addresses")
+
+	// Skip the sections outside of the lookup limit for fresh indexes
+	head, limit := chain.CurrentBlock().Number.Uint64(), chain.TxLookupLimit()
+	if sections, _, _ := indexer.Sections(); sections == 0 && limit > 0 && head > limit+size {
+		section := (head-limit)/size - 1
+		indexer.AddCheckpoint(section, rawdb.ReadCanonicalHash(chain.db, (section+1)*size-1))
+		rawdb.WriteAddressIndexTail(chain.db, (section+1)*size)
+	}
+	return indexer
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new address index
+// section and dropping any stale data left by a reorg.
+func (b *AddressIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	rawdb.DeleteAddressSection(b.db, section, section*b.size, (section+1)*b.size-1)
+
+	b.section, b.batch, b.active, b.tail = section, b.db.NewBatch(), make(map[common.Address]struct{}), nil
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the activity of a new
+// block's transactions into the index.
+func (b *AddressIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+	if limit := b.chain.TxLookupLimit(); limit > 0 && number+limit <= b.chain.CurrentBlock().Number.Uint64() {
+		next := number + 1
+		b.tail = &next
+		rawdb.DeleteInternalCalls(b.db, number, number)
+		return nil
+	}
+	hash := header.Hash()
+	body := rawdb.ReadBody(b.db, hash, number)
+	if body == nil {
+		return errors.New("block body missing")
+	}
+	if len(body.Transactions) == 0 {
+		return nil
+	}
+	receipts := rawdb.ReadRawReceipts(b.db, hash, number)
+	if len(receipts) != len(body.Transactions) {
+		return errors.New("block receipts missing")
+	}
+	var (
+		signer = types.MakeSigner(b.chain.Config(), header.Number, header.Time)
+		calls  = rawdb.ReadInternalCalls(b.db, hash, number)
+	)
+	for i, tx := range body.Transactions {
+		var called []common.Address
+		if i < len(calls) {
+			called = calls[i]
+		}
+		active, err := TransactionActivity(signer, tx, receipts[i].Logs, called)
+		if err != nil {
+			return err
+		}
+		for address, roles := range active {
+			rawdb.WriteAddressActivity(b.batch, address, number, uint32(i), roles)
+			b.active[address] = struct{}{}
+		}
+	}
+	if b.batch.ValueSize() > ethdb.IdealBatchSize {
+		if err := b.batch.Write(); err != nil {
+			return err
+		}
+		b.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, finalizing the address index
+// section and pruning the sections which fell out of the lookup limit.
+func (b *AddressIndexer) Commit() error {
+	addresses := make([]common.Address, 0, len(b.active))
+	for address := range b.active {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	if len(addresses) > 0 {
+		rawdb.WriteAddressSection(b.batch, b.section, addresses)
+	}
+	if err := b.batch.Write(); err != nil {
+		return err
+	}
+	if limit, head := b.chain.TxLookupLimit(), b.chain.CurrentBlock().Number.Uint64(); limit > 0 && head >= limit {
+		if err := b.Prune(head - limit + 1); err != nil {
+			return err
+		}
+	}
+	// Move the tail past any skipped blocks, or initialize it with the section
+	tail := rawdb.ReadAddressIndexTail(b.db)
+	switch {
+	case b.tail != nil && (tail == nil || *tail < *b.tail):
+		rawdb.WriteAddressIndexTail(b.db, *b.tail)
+	case tail == nil:
+		rawdb.WriteAddressIndexTail(b.db, b.section*b.size)
+	}
+	return nil
+}
+
+// Prune implements core.ChainIndexerBackend, deleting the address index sections
+// entirely below the given threshold.
+func (b *AddressIndexer) Prune(threshold uint64) error {
+	tail := rawdb.ReadAddressIndexTail(b.db)
+	if tail == nil {
+		return nil
+	}
+	start := *tail / b.size
+	section := start
+	for ; (section+1)*b.size <= threshold && section < b.section; section++ {
+		rawdb.DeleteAddressSection(b.db, section, section*b.size, (section+1)*b.size-1)
+		rawdb.DeleteInternalCalls(b.db, section*b.size, (section+1)*b.size-1)
+	}
+	if section > start {
+		rawdb.WriteAddressIndexTail(b.db, section*b.size)
+		log.Debug("Pruned address index", "sections", section-start, "tail", section*b.size)
+	}
+	return nil
+}
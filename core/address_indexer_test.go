@@ -0,0 +1,170 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newAddressIndexTestChain creates a chain of n blocks with the given transaction
+// lookup limit. Every block sends a transaction from the funded account to a
+// recipient, the third block creates a contract, the sixth block calls a contract
+// calling another account and every fourth block calls a contract emitting a log.
+func newAddressIndexTestChain(t *testing.T, n int, limit uint64) (*BlockChain, common.Address, common.Address, common.Address, common.Address) {
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{0x01}
+		emitter   = common.Address{0x02}
+		caller    = common.Address{0x03}
+		created   = crypto.CreateAddress(sender, 2)
+		gspec     = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sender:  {Balance: big.NewInt(params.Ether)},
+				emitter: {Balance: common.Big0, Code: common.FromHex("0x60003560006000a100")},
+				caller:  {Balance: common.Big0, Code: common.FromHex("0x6000600060006000600060045af100")}, // CALL(gas, 0x04, 0, 0, 0, 0, 0)
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, n, func(i int, block *BlockGen) {
+		var tx *types.Transaction
+		switch {
+		case i == 2:
+			tx = types.NewContractCreation(block.TxNonce(sender), common.Big0, 100000, block.BaseFee(), nil)
+		case i == 5:
+			tx = types.NewTransaction(block.TxNonce(sender), caller, common.Big0, 100000, block.BaseFee(), nil)
+		case i%4 == 3:
+			tx = types.NewTransaction(block.TxNonce(sender), emitter, common.Big0, 100000, block.BaseFee(), common.Hash{0x01}.Bytes())
+		default:
+			tx = types.NewTransaction(block.TxNonce(sender), recipient, common.Big1, params.TxGas, block.BaseFee(), nil)
+		}
+		tx, _ = types.SignTx(tx, signer, key)
+		block.AddTx(tx)
+	})
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.InternalCalls = true
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, nil, engine, vm.Config{}, nil, &limit)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain, sender, recipient, emitter, created
+}
+
+// waitAddressSections waits until the address indexer processed the given
+// number of sections.
+func waitAddressSections(t *testing.T, indexer *ChainIndexer, sections uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if have, _, _ := indexer.Sections(); have == sections {
+			return
+		}
+	}
+	have, _, _ := indexer.Sections()
+	t.Fatalf("indexed sections mismatch: have %d, want %d", have, sections)
+}
+
+// addressActivity returns the indexed activity of an address as block numbers
+// and roles.
+func addressActivity(t *testing.T, chain *BlockChain, address common.Address) map[uint64]byte {
+	t.Helper()
+
+	activity := make(map[uint64]byte)
+	err := rawdb.IterateAddressActivity(chain.db, address, 0, 0, chain.CurrentBlock().Number.Uint64(), func(number uint64, index uint32, roles byte) bool {
+		activity[number] = roles
+		return true
+	})
+	if err != nil {
+		t.Fatalf("failed to iterate address activity: %v", err)
+	}
+	return activity
+}
+
+// Tests that the address indexer records the senders, recipients, created
+// contracts, log emitters and internally called accounts of the transactions in
+// complete sections.
+func TestAddressIndexer(t *testing.T) {
+	chain, sender, recipient, emitter, created := newAddressIndexTestChain(t, 10, 0)
+	defer chain.Stop()
+
+	indexer := NewAddressIndexer(chain, 4, 0)
+	defer indexer.Close()
+	indexer.Start(chain)
+	waitAddressSections(t, indexer, 2)
+
+	if tail := rawdb.ReadAddressIndexTail(chain.db); tail == nil || *tail != 0 {
+		t.Fatalf("index tail mismatch: have %v, want 0", tail)
+	}
+	for _, tt := range []struct {
+		address common.Address
+		want    map[uint64]byte
+	}{
+		{sender, map[uint64]byte{1: AddressRoleSender, 2: AddressRoleSender, 3: AddressRoleSender, 4: AddressRoleSender, 5: AddressRoleSender, 6: AddressRoleSender, 7: AddressRoleSender}},
+		{recipient, map[uint64]byte{1: AddressRoleRecipient, 2: AddressRoleRecipient, 5: AddressRoleRecipient, 7: AddressRoleRecipient}},
+		{created, map[uint64]byte{3: AddressRoleCreated}},
+		{emitter, map[uint64]byte{4: AddressRoleRecipient | AddressRoleEmitter}},
+		{common.Address{0x03}, map[uint64]byte{6: AddressRoleRecipient}},
+		{common.BytesToAddress([]byte{0x04}), map[uint64]byte{6: AddressRoleCalled}},
+	} {
+		if have := addressActivity(t, chain, tt.address); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("activity of %x mismatch: have %v, want %v", tt.address, have, tt.want)
+		}
+	}
+}
+
+// Tests that the address indexer skips and prunes the blocks outside of the
+// transaction lookup limit.
+func TestAddressIndexerLimit(t *testing.T) {
+	chain, sender, _, _, _ := newAddressIndexTestChain(t, 12, 6)
+	defer chain.Stop()
+
+	indexer := NewAddressIndexer(chain, 4, 0)
+	defer indexer.Close()
+	indexer.Start(chain)
+	waitAddressSections(t, indexer, 3)
+
+	if tail := rawdb.ReadAddressIndexTail(chain.db); tail == nil || *tail != 7 {
+		t.Fatalf("index tail mismatch: have %v, want 7", tail)
+	}
+	want := map[uint64]byte{7: AddressRoleSender, 8: AddressRoleSender, 9: AddressRoleSender, 10: AddressRoleSender, 11: AddressRoleSender}
+	if have := addressActivity(t, chain, sender); !reflect.DeepEqual(have, want) {
+		t.Errorf("activity of sender mismatch: have %v, want %v", have, want)
+	}
+	// Ensure the internal calls recorded for the skipped blocks are dropped
+	if block := chain.GetBlockByNumber(6); rawdb.ReadInternalCalls(chain.db, block.Hash(), 6) != nil {
+		t.Errorf("internal calls of skipped block retained")
+	}
+}
@@ -142,8 +142,14 @@ type CacheConfig struct {
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
 
+	ContractIndex bool // Whether to index the creator of every contract created by imported blocks
+	SenderIndex   bool // Whether to index the transactions by sender and nonce, within the transaction index
+	InternalCalls bool // Whether to record the accounts called internally by imported blocks, for the address index
+
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+
+	StateWrapper func(state.Database) state.Database // Optional wrapper around the state database (e.g. to fetch missing state remotely)
 }
 
 // triedbConfig derives the configures for trie database.
@@ -224,6 +230,8 @@ type BlockChain struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	finalityFeed  event.Feed
+	finalityCh    chan struct{} // Notifies the finality event loop of updates
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -290,6 +298,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		triedb:        triedb,
 		triegc:        prque.New[int64, common.Hash](nil),
 		quit:          make(chan struct{}),
+		finalityCh:    make(chan struct{}, 1),
 		chainmu:       syncx.NewClosableMutex(),
 		bodyCache:     lru.NewCache[common.Hash, *types.Body](bodyCacheLimit),
 		bodyRLPCache:  lru.NewCache[common.Hash, rlp.RawValue](bodyCacheLimit),
@@ -303,6 +312,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
+	if cacheConfig.StateWrapper != nil {
+		bc.stateCache = cacheConfig.StateWrapper(bc.stateCache)
+	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
@@ -438,6 +450,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	bc.wg.Add(1)
 	go bc.updateFutureBlocks()
 
+	// Start the finality event poster.
+	bc.wg.Add(1)
+	go bc.updateFinality()
+
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -603,6 +619,7 @@ func (bc *BlockChain) SetFinalized(header *types.Header) {
 		rawdb.WriteFinalizedBlockHash(bc.db, common.Hash{})
 		headFinalizedBlockGauge.Update(0)
 	}
+	bc.notifyFinality()
 }
 
 // SetSafe sets the safe block.
@@ -613,6 +630,16 @@ func (bc *BlockChain) SetSafe(header *types.Header) {
 	} else {
 		headSafeBlockGauge.Update(0)
 	}
+	bc.notifyFinality()
+}
+
+// notifyFinality schedules posting a finality event, without waiting for the
+// subscribers so that forkchoice updates are never held up by them.
+func (bc *BlockChain) notifyFinality() {
+	select {
+	case bc.finalityCh <- struct{}{}:
+	default:
+	}
 }
 
 // setHeadBeyondRoot rewinds the local chain to a new head with the extra condition
@@ -931,6 +958,7 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	bc.writeSenderNonceEntries(batch, block)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// Flush the whole batch into the disk, exit the node if failed
@@ -1112,6 +1140,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		}
 	}
 
+	// Start the parallel sender recoverer if the sender index needs the senders
+	if bc.cacheConfig.SenderIndex && len(blockChain) > 0 {
+		SenderCacher.RecoverFromBlocks(types.MakeSigner(bc.chainConfig, blockChain[0].Number(), blockChain[0].Time()), blockChain)
+	}
 	var (
 		stats = struct{ processed, ignored int32 }{}
 		start = time.Now()
@@ -1195,8 +1227,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		for i, block := range blockChain {
 			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64() >= ancientLimit-bc.txLookupLimit {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+				bc.writeSenderNonceEntries(batch, block)
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
 				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+				bc.writeSenderNonceEntries(batch, block)
 			}
 			stats.processed++
 
@@ -1280,6 +1314,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
 			rawdb.WriteTxLookupEntriesByBlock(batch, block) // Always write tx indices for live blocks, we assume they are needed
+			bc.writeSenderNonceEntries(batch, block)
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1480,6 +1515,33 @@ func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types
 	return bc.writeBlockAndSetHead(block, receipts, logs, state, emitHeadEvent)
 }
 
+// writeSenderNonceEntries stores the sender and nonce lookups of the transactions
+// of a block, if the sender index is enabled, initializing the sender index tail
+// at the first indexed block. The senders are usually cached on the transactions
+// already, by block processing or by the sender cacher.
+func (bc *BlockChain) writeSenderNonceEntries(db ethdb.KeyValueWriter, block *types.Block) {
+	if bc.cacheConfig.SenderIndex {
+		rawdb.WriteSenderNonceEntriesByBlock(db, types.MakeSigner(bc.chainConfig, block.Number(), block.Time()), block)
+		if rawdb.ReadSenderIndexTail(bc.db) == nil {
+			rawdb.WriteSenderIndexTail(db, block.NumberU64())
+		}
+	}
+}
+
+// writeContractCreations stores the contract creations recorded while processing
+// a block, initializing the creation index tail at the first indexed block.
+// Blocks imported without execution, e.g. through snap sync, are not indexed.
+func (bc *BlockChain) writeContractCreations(block *types.Block, recorder *creationRecorder) {
+	batch := bc.db.NewBatch()
+	recorder.write(batch, block)
+	if rawdb.ReadContractIndexTail(bc.db) == nil {
+		rawdb.WriteContractIndexTail(batch, block.NumberU64())
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write contract creations", "err", err)
+	}
+}
+
 // writeBlockAndSetHead is the internal implementation of WriteBlockAndSetHead.
 // This function expects the chain mutex to be held.
 func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
@@ -1803,9 +1865,23 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 			}
 		}
 
-		// Process block using the parent state as reference point
+		// Process block using the parent state as reference point, recording the
+		// contracts created and the internal calls if their indexes are enabled
+		var (
+			vmConfig = bc.vmConfig
+			recorder *creationRecorder
+			calls    *callRecorder
+		)
+		if bc.cacheConfig.ContractIndex {
+			recorder = newCreationRecorder(vmConfig.Tracer)
+			vmConfig.Tracer = recorder
+		}
+		if bc.cacheConfig.InternalCalls {
+			calls = newCallRecorder(vmConfig.Tracer)
+			vmConfig.Tracer = calls
+		}
 		pstart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			followupInterrupt.Store(true)
@@ -1853,6 +1929,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		if err != nil {
 			return it.index, err
 		}
+		if recorder != nil {
+			bc.writeContractCreations(block, recorder)
+		}
+		if calls != nil {
+			calls.write(bc.db, block)
+		}
 		// Update the metrics touched during block commit
 		accountCommitTimer.Update(statedb.AccountCommits)   // Account commits are complete, we can mark them
 		storageCommitTimer.Update(statedb.StorageCommits)   // Storage commits are complete, we can mark them
@@ -2243,8 +2325,29 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 	// Delete useless indexes right now which includes the non-canonical
 	// transaction indexes, canonical chain indexes which above the head.
 	indexesBatch := bc.db.NewBatch()
+	removedTxs := make(map[common.Hash]struct{})
 	for _, tx := range types.HashDifference(deletedTxs, addedTxs) {
 		rawdb.DeleteTxLookupEntry(indexesBatch, tx)
+		removedTxs[tx] = struct{}{}
+	}
+	// Delete the sender and nonce lookups of the removed transactions, unless
+	// they were already taken over by a transaction of the new chain.
+	if bc.cacheConfig.SenderIndex {
+		for _, block := range oldChain {
+			signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+			for _, tx := range block.Transactions() {
+				if _, ok := removedTxs[tx.Hash()]; !ok {
+					continue
+				}
+				sender, err := types.Sender(signer, tx)
+				if err != nil {
+					continue
+				}
+				if hash := rawdb.ReadSenderNonceEntry(bc.db, sender, tx.Nonce()); hash != nil && *hash == tx.Hash() {
+					rawdb.DeleteSenderNonceEntry(indexesBatch, sender, tx.Nonce())
+				}
+			}
+		}
 	}
 
 	// Delete all hash markers that are not part of the new canonical chain.
@@ -2380,6 +2483,21 @@ func (bc *BlockChain) updateFutureBlocks() {
 	}
 }
 
+// updateFinality posts the finality events. Updates made while the subscribers
+// are busy are coalesced into a single event of the latest safe and finalized
+// blocks.
+func (bc *BlockChain) updateFinality() {
+	defer bc.wg.Done()
+	for {
+		select {
+		case <-bc.finalityCh:
+			bc.finalityFeed.Send(FinalityEvent{Safe: bc.CurrentSafeBlock(), Finalized: bc.CurrentFinalBlock()})
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
 // skipBlock returns 'true', if the block being imported can be skipped over, meaning
 // that the block does not need to be processed but can be considered already fully 'done'.
 func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
@@ -2438,7 +2556,7 @@ func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{})
 		if bc.txLookupLimit != 0 && head >= bc.txLookupLimit {
 			from = head - bc.txLookupLimit + 1
 		}
-		rawdb.IndexTransactions(bc.db, from, head+1, bc.quit)
+		rawdb.IndexTransactions(bc.db, from, head+1, bc.cacheConfig.SenderIndex, bc.quit)
 		return
 	}
 	// The tail flag is existent, but the whole chain is required to be indexed.
@@ -2451,17 +2569,17 @@ func (bc *BlockChain) indexBlocks(tail *uint64, head uint64, done chan struct{})
 			if end > head+1 {
 				end = head + 1
 			}
-			rawdb.IndexTransactions(bc.db, 0, end, bc.quit)
+			rawdb.IndexTransactions(bc.db, 0, end, bc.cacheConfig.SenderIndex, bc.quit)
 		}
 		return
 	}
 	// Update the transaction index to the new chain state
 	if head-bc.txLookupLimit+1 < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(bc.db, head-bc.txLookupLimit+1, *tail, bc.quit)
+		rawdb.IndexTransactions(bc.db, head-bc.txLookupLimit+1, *tail, bc.cacheConfig.SenderIndex, bc.quit)
 	} else {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(bc.db, *tail, head-bc.txLookupLimit+1, bc.quit)
+		rawdb.UnindexTransactions(bc.db, *tail, head-bc.txLookupLimit+1, bc.cacheConfig.SenderIndex, bc.quit)
 	}
 }
 
@@ -412,6 +412,11 @@ func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscript
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
 }
 
+// SubscribeFinalityEvent registers a subscription of FinalityEvent.
+func (bc *BlockChain) SubscribeFinalityEvent(ch chan<- FinalityEvent) event.Subscription {
+	return bc.scope.Track(bc.finalityFeed.Subscribe(ch))
+}
+
 // SubscribeBlockProcessingEvent registers a subscription of bool where true means
 // block processing has started while false means it has stopped.
 func (bc *BlockChain) SubscribeBlockProcessingEvent(ch chan<- bool) event.Subscription {
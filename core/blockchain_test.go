@@ -4716,3 +4716,167 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that the sender and nonce lookups follow reorgs, pointing to the
+// transactions of the new canonical chain and dropping the removed ones.
+func TestSenderNonceLookupReorg(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: big.NewInt(100000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	transfer := func(block *BlockGen, to common.Address) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(tx)
+		return tx
+	}
+	// The original chain uses nonces 0 and 1, the longer fork only nonce 0
+	var original, fork []*types.Transaction
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, engine, 3, func(i int, block *BlockGen) {
+		if i < 2 {
+			original = append(original, transfer(block, common.Address{0x01}))
+		}
+	})
+	forkBlocks, _ := GenerateChain(gspec.Config, gspec.ToBlock(), engine, genDb, 5, func(i int, block *BlockGen) {
+		if i == 1 {
+			fork = append(fork, transfer(block, common.Address{0x02}))
+		}
+	})
+	// A chain without the sender index must not store any lookups
+	plain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := plain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert original chain: %v", err)
+	}
+	if hash := rawdb.ReadSenderNonceEntry(plain.db, address, 0); hash != nil {
+		t.Fatalf("sender index disabled, but lookup stored: %x", *hash)
+	}
+	if tail := rawdb.ReadSenderIndexTail(plain.db); tail != nil {
+		t.Fatalf("sender index disabled, but tail stored: %d", *tail)
+	}
+	plain.Stop()
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.SenderIndex = true
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert original chain: %v", err)
+	}
+	for nonce, want := range original {
+		if tx, _, _, _ := rawdb.ReadTransactionBySenderAndNonce(chain.db, address, uint64(nonce)); tx == nil || tx.Hash() != want.Hash() {
+			t.Fatalf("nonce %d lookup mismatch before reorg: have %v, want %x", nonce, tx, want.Hash())
+		}
+	}
+	if tail := rawdb.ReadSenderIndexTail(chain.db); tail == nil {
+		t.Fatal("sender index enabled, but tail missing")
+	}
+	if _, err := chain.InsertChain(forkBlocks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if tx, _, number, _ := rawdb.ReadTransactionBySenderAndNonce(chain.db, address, 0); tx == nil || tx.Hash() != fork[0].Hash() || number != 2 {
+		t.Fatalf("nonce 0 lookup mismatch after reorg: have %v in block %d, want %x in block 2", tx, number, fork[0].Hash())
+	}
+	if hash := rawdb.ReadSenderNonceEntry(chain.db, address, 1); hash != nil {
+		t.Fatalf("nonce 1 lookup not deleted after reorg: %x", *hash)
+	}
+}
+
+// Tests that the contract creation index records top level and internal contract
+// creations, but not the ones reverted.
+func TestContractCreationIndex(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		factory  = common.HexToAddress("0xaaaa")
+		reverter = common.HexToAddress("0xbbbb")
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(100000000000000000)},
+				// CREATE of an empty contract
+				factory: {Code: common.FromHex("0x600060006000f05000"), Nonce: 1, Balance: common.Big0},
+				// CREATE of an empty contract, then REVERT
+				reverter: {Code: common.FromHex("0x600060006000f05060006000fd"), Nonce: 1, Balance: common.Big0},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	var txs []*types.Transaction
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, block *BlockGen) {
+		for _, to := range []*common.Address{nil, &factory, &reverter} {
+			tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    block.TxNonce(address),
+				To:       to,
+				Gas:      100000,
+				GasPrice: block.header.BaseFee,
+			}), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.AddTx(tx)
+			txs = append(txs, tx)
+		}
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &CacheConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  5 * time.Minute,
+		SnapshotLimit:  256,
+		StateScheme:    rawdb.HashScheme,
+		ContractIndex:  true,
+	}, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if tail := rawdb.ReadContractIndexTail(chain.db); tail == nil || *tail != 1 {
+		t.Fatalf("contract index tail mismatch: have %v, want 1", tail)
+	}
+	tests := []struct {
+		contract common.Address
+		creator  common.Address
+		tx       *types.Transaction
+	}{
+		{crypto.CreateAddress(address, 0), address, txs[0]},
+		{crypto.CreateAddress(factory, 1), factory, txs[1]},
+		{crypto.CreateAddress(reverter, 1), common.Address{}, nil},
+	}
+	for i, tt := range tests {
+		creation := rawdb.ReadContractCreation(chain.db, tt.contract)
+		if tt.tx == nil {
+			if creation != nil {
+				t.Errorf("test %d: reverted creation indexed: %+v", i, creation)
+			}
+			continue
+		}
+		if creation == nil {
+			t.Errorf("test %d: creation not indexed", i)
+			continue
+		}
+		if creation.Creator != tt.creator || creation.TxHash != tt.tx.Hash() || creation.BlockHash != blocks[0].Hash() || creation.BlockNumber != 1 {
+			t.Errorf("test %d: creation mismatch: have %+v, want creator %x in tx %x", i, creation, tt.creator, tt.tx.Hash())
+		}
+	}
+}
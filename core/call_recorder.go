@@ -0,0 +1,121 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// callRecorder is an EVM logger collecting the accounts called internally by the
+// transactions of a block, through any of the call and create opcodes. Calls are
+// recorded even if they were reverted, since the accounts were still reached.
+// All events are forwarded to an optional wrapped logger.
+type callRecorder struct {
+	inner vm.EVMLogger
+	inTx  bool                        // Whether a transaction is being executed, as opposed to system calls
+	seen  map[common.Address]struct{} // Accounts already called by the transaction being executed
+	calls [][]common.Address          // Accounts called internally, per transaction
+}
+
+// newCallRecorder creates an internal call recorder, forwarding the events to
+// the given logger if it's not nil.
+func newCallRecorder(inner vm.EVMLogger) *callRecorder {
+	return &callRecorder{inner: inner}
+}
+
+// write stores the recorded internal calls of a processed block, if there were
+// any at all.
+func (r *callRecorder) write(db ethdb.KeyValueWriter, block *types.Block) {
+	for _, calls := range r.calls {
+		if len(calls) > 0 {
+			rawdb.WriteInternalCalls(db, block.Hash(), block.NumberU64(), r.calls)
+			return
+		}
+	}
+}
+
+// CaptureTxStart implements vm.EVMLogger, starting a new transaction.
+func (r *callRecorder) CaptureTxStart(gasLimit uint64) {
+	r.inTx, r.seen = true, make(map[common.Address]struct{})
+	r.calls = append(r.calls, nil)
+	if r.inner != nil {
+		r.inner.CaptureTxStart(gasLimit)
+	}
+}
+
+// CaptureTxEnd implements vm.EVMLogger, finishing the current transaction.
+func (r *callRecorder) CaptureTxEnd(restGas uint64) {
+	r.inTx = false
+	if r.inner != nil {
+		r.inner.CaptureTxEnd(restGas)
+	}
+}
+
+// CaptureStart implements vm.EVMLogger, forwarding the top call frame.
+func (r *callRecorder) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	if r.inner != nil {
+		r.inner.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+// CaptureEnd implements vm.EVMLogger, forwarding the top call frame's end.
+func (r *callRecorder) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if r.inner != nil {
+		r.inner.CaptureEnd(output, gasUsed, err)
+	}
+}
+
+// CaptureEnter implements vm.EVMLogger, recording the account of an inner call
+// frame.
+func (r *callRecorder) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if r.inTx {
+		if _, ok := r.seen[to]; !ok {
+			r.seen[to] = struct{}{}
+			r.calls[len(r.calls)-1] = append(r.calls[len(r.calls)-1], to)
+		}
+	}
+	if r.inner != nil {
+		r.inner.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+// CaptureExit implements vm.EVMLogger, forwarding the inner call frame's end.
+func (r *callRecorder) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if r.inner != nil {
+		r.inner.CaptureExit(output, gasUsed, err)
+	}
+}
+
+// CaptureState implements vm.EVMLogger, forwarding the step to the wrapped logger.
+func (r *callRecorder) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if r.inner != nil {
+		r.inner.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+// CaptureFault implements vm.EVMLogger, forwarding the fault to the wrapped logger.
+func (r *callRecorder) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if r.inner != nil {
+		r.inner.CaptureFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// WriteCheckpoint initializes a database containing only the genesis block to
+// start at a trusted post-merge block, without syncing the chain up to it. The
+// state of the block is imported from a dump in the format produced by 'geth
+// snapshot dump' and verified against the state root of the block. The receipts
+// of the block are verified against its receipt root and stored along with it.
+//
+// The blocks between the genesis and the checkpoint are left missing, it's up
+// to the beacon syncer to backfill the headers preceding the checkpoint.
+//
+// Both state schemes are supported. With the path scheme, the imported trie nodes
+// replace the genesis state in the persistent disk layer, so any trie database
+// opened on the genesis state is stale afterwards.
+func WriteCheckpoint(db ethdb.Database, scheme string, block *types.Block, receipts types.Receipts, td *big.Int, dump io.Reader) error {
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return errors.New("genesis block missing")
+	}
+	config := rawdb.ReadChainConfig(db, genesis)
+	if config == nil {
+		return errors.New("chain config missing")
+	}
+	if config.TerminalTotalDifficulty == nil {
+		return errors.New("checkpoints are only supported on post-merge chains")
+	}
+	if head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db)); head == nil || *head != 0 {
+		return errors.New("database already contains a chain")
+	}
+	if block.NumberU64() == 0 || block.Difficulty().Sign() != 0 {
+		return fmt.Errorf("checkpoint block %d is not a post-merge block", block.NumberU64())
+	}
+	if td == nil {
+		td = config.TerminalTotalDifficulty
+	}
+	if td.Cmp(config.TerminalTotalDifficulty) < 0 {
+		return fmt.Errorf("checkpoint total difficulty %v below terminal total difficulty %v", td, config.TerminalTotalDifficulty)
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != block.ReceiptHash() {
+		return fmt.Errorf("receipt root mismatch: have %x, want %x", hash, block.ReceiptHash())
+	}
+	// Import the state and ensure it matches the checkpoint
+	root, err := importStateDump(db, scheme, dump)
+	if err != nil {
+		return err
+	}
+	if root != block.Root() {
+		return fmt.Errorf("state root mismatch: have %x, want %x", root, block.Root())
+	}
+	// Write the block and mark it as the head of the chain
+	batch := db.NewBatch()
+	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), td)
+	rawdb.WriteBlock(batch, block)
+	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+	rawdb.WriteHeadHeaderHash(batch, block.Hash())
+	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
+	rawdb.WriteHeadBlockHash(batch, block.Hash())
+	rawdb.WriteFinalizedBlockHash(batch, block.Hash())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Wrote checkpoint block", "number", block.NumberU64(), "hash", block.Hash(), "root", root)
+	return nil
+}
+
+// importStateDump writes the state contained in a dump produced by 'geth
+// snapshot dump' into the database, returning the root hash of the rebuilt
+// account trie.
+//
+// The dump starts with an object holding the state root, followed by the
+// accounts ordered by the hash of their address, each including its code and
+// storage.
+func importStateDump(db ethdb.Database, scheme string, dump io.Reader) (common.Hash, error) {
+	dec := json.NewDecoder(dump)
+
+	var head struct {
+		Root common.Hash `json:"root"`
+	}
+	if err := dec.Decode(&head); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid state dump header: %v", err)
+	}
+	var (
+		batch   = db.NewBatch()
+		writeFn = func(owner common.Hash, path []byte, hash common.Hash, blob []byte) {
+			rawdb.WriteTrieNode(batch, owner, path, hash, blob, scheme)
+		}
+		accTrie = trie.NewStackTrie(writeFn)
+
+		last     []byte
+		accounts int
+		slots    int
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	for {
+		var account state.DumpAccount
+		if err := dec.Decode(&account); err == io.EOF {
+			break
+		} else if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid state dump account %d: %v", accounts, err)
+		}
+		key := []byte(account.SecureKey)
+		if len(key) == 0 && account.Address != nil {
+			key = crypto.Keccak256(account.Address.Bytes())
+		}
+		if len(key) != common.HashLength {
+			return common.Hash{}, fmt.Errorf("state dump account %d has no key", accounts)
+		}
+		if last != nil && bytes.Compare(key, last) <= 0 {
+			return common.Hash{}, fmt.Errorf("state dump accounts out of order at %x", key)
+		}
+		last = key
+
+		// Rebuild the storage trie of the account and ensure it matches
+		storageRoot, err := importStorage(batch, writeFn, common.BytesToHash(key), account.Storage)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("account %x: %v", key, err)
+		}
+		if storageRoot != common.BytesToHash(account.Root) {
+			return common.Hash{}, fmt.Errorf("account %x: storage root mismatch: have %x, want %x", key, storageRoot, account.Root)
+		}
+		// Import the code of the account, which must be present in the dump
+		codeHash := common.BytesToHash(account.CodeHash)
+		if codeHash != types.EmptyCodeHash {
+			if hash := crypto.Keccak256Hash(account.Code); hash != codeHash {
+				return common.Hash{}, fmt.Errorf("account %x: code hash mismatch: have %x, want %x", key, hash, codeHash)
+			}
+			rawdb.WriteCode(batch, codeHash, account.Code)
+		}
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("account %x: invalid balance %q", key, account.Balance)
+		}
+		blob, err := rlp.EncodeToBytes(&types.StateAccount{
+			Nonce:    account.Nonce,
+			Balance:  balance,
+			Root:     storageRoot,
+			CodeHash: codeHash.Bytes(),
+		})
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := accTrie.Update(key, blob); err != nil {
+			return common.Hash{}, err
+		}
+		accounts++
+		slots += len(account.Storage)
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return common.Hash{}, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing checkpoint state", "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	root, err := accTrie.Commit()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := batch.Write(); err != nil {
+		return common.Hash{}, err
+	}
+	if root != head.Root {
+		return common.Hash{}, fmt.Errorf("state dump root mismatch: have %x, want %x", root, head.Root)
+	}
+	log.Info("Imported checkpoint state", "root", root, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return root, nil
+}
+
+// importStorage writes the storage trie of an account into the database, with
+// the slots being keyed by their hashes and holding their RLP encoded values.
+func importStorage(batch ethdb.Batch, writeFn trie.NodeWriteFunc, owner common.Hash, storage map[common.Hash]string) (common.Hash, error) {
+	if len(storage) == 0 {
+		return types.EmptyRootHash, nil
+	}
+	keys := make([]common.Hash, 0, len(storage))
+	for key := range storage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+	tr := trie.NewStackTrieWithOwner(writeFn, owner)
+	for _, key := range keys {
+		if err := tr.Update(key[:], common.FromHex(storage[key])); err != nil {
+			return common.Hash{}, err
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return common.Hash{}, err
+			}
+			batch.Reset()
+		}
+	}
+	return tr.Commit()
+}
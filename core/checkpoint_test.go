@@ -0,0 +1,208 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// dumpState writes the state with the given root in the format of 'geth snapshot
+// dump', iterating the tries directly.
+func dumpState(t *testing.T, db ethdb.Database, root common.Hash) []byte {
+	t.Helper()
+
+	triedb := trie.NewDatabase(db, nil)
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), triedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.Encode(struct {
+		Root common.Hash `json:"root"`
+	}{root})
+
+	it := trie.NewIterator(tr.MustNodeIterator(nil))
+	for it.Next() {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &account); err != nil {
+			t.Fatal(err)
+		}
+		da := &state.DumpAccount{
+			Balance:   account.Balance.String(),
+			Nonce:     account.Nonce,
+			Root:      account.Root.Bytes(),
+			CodeHash:  account.CodeHash,
+			SecureKey: it.Key,
+		}
+		if !bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
+			da.Code = rawdb.ReadCode(db, common.BytesToHash(account.CodeHash))
+		}
+		if account.Root != types.EmptyRootHash {
+			st, err := trie.NewStateTrie(trie.StorageTrieID(root, common.BytesToHash(it.Key), account.Root), triedb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			da.Storage = make(map[common.Hash]string)
+			stIt := trie.NewIterator(st.MustNodeIterator(nil))
+			for stIt.Next() {
+				da.Storage[common.BytesToHash(stIt.Key)] = common.Bytes2Hex(stIt.Value)
+			}
+		}
+		enc.Encode(da)
+	}
+	return buf.Bytes()
+}
+
+// Tests that a chain can be started at a checkpoint with imported state, and
+// that mismatching state is rejected.
+func TestWriteCheckpoint(t *testing.T) {
+	testWriteCheckpoint(t, rawdb.HashScheme)
+	testWriteCheckpoint(t, rawdb.PathScheme)
+}
+
+func testWriteCheckpoint(t *testing.T, scheme string) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.AllEthashProtocolChanges
+		gspec   = &Genesis{
+			Config:  &config,
+			Alloc:   GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}, {0xaa}: {Balance: common.Big1, Code: []byte{0x60, 0x42}, Storage: map[common.Hash]common.Hash{{0x01}: {0x01}}}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.TerminalTotalDifficultyPassed = true
+
+	srcdb, blocks, receipts := GenerateChainWithGenesis(gspec, beacon.NewFaker(), 4, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0xbb}, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	checkpoint := blocks[len(blocks)-1]
+	dump := dumpState(t, srcdb, checkpoint.Root())
+
+	// Round trip the receipts through JSON, as they are loaded by 'geth init'
+	// from the output of eth_getBlockReceipts, which never has nil logs
+	for _, receipt := range receipts[len(receipts)-1] {
+		receipt.Logs = []*types.Log{}
+	}
+	var checkpointReceipts types.Receipts
+	blob, _ := json.Marshal(receipts[len(receipts)-1])
+	if err := json.Unmarshal(blob, &checkpointReceipts); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+
+	// Ensure mismatching state is rejected
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db, trie.NewDatabase(db, newDbConfig(scheme)))
+	if err := WriteCheckpoint(db, scheme, blocks[0], receipts[0], nil, bytes.NewReader(dump)); err == nil {
+		t.Fatalf("mismatching checkpoint state accepted")
+	}
+	// Ensure missing or mismatching receipts are rejected
+	if err := WriteCheckpoint(db, scheme, checkpoint, nil, nil, bytes.NewReader(dump)); err == nil {
+		t.Fatalf("checkpoint without receipts accepted")
+	}
+	failed := *checkpointReceipts[0]
+	failed.Status = types.ReceiptStatusFailed
+	if err := WriteCheckpoint(db, scheme, checkpoint, types.Receipts{&failed}, nil, bytes.NewReader(dump)); err == nil {
+		t.Fatalf("checkpoint with mismatching receipts accepted")
+	}
+	// Start a chain at the checkpoint and ensure the state is accessible
+	db = rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db, trie.NewDatabase(db, newDbConfig(scheme)))
+	if err := WriteCheckpoint(db, scheme, checkpoint, checkpointReceipts, nil, bytes.NewReader(dump)); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+	chain, err := NewBlockChain(db, DefaultCacheConfigWithScheme(scheme), gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to open chain: %v", err)
+	}
+
+	if head := chain.CurrentBlock(); head.Hash() != checkpoint.Hash() {
+		t.Fatalf("head mismatch: have %d %x, want %d %x", head.Number, head.Hash(), checkpoint.Number(), checkpoint.Hash())
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open checkpoint state: %v", err)
+	}
+	if balance := statedb.GetBalance(common.Address{0xbb}); balance.Cmp(big.NewInt(4000)) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", balance, 4000)
+	}
+	if value := statedb.GetState(common.Address{0xaa}, common.Hash{0x01}); value != (common.Hash{0x01}) {
+		t.Errorf("storage mismatch: have %x, want %x", value, common.Hash{0x01})
+	}
+	if code := statedb.GetCode(common.Address{0xaa}); !bytes.Equal(code, []byte{0x60, 0x42}) {
+		t.Errorf("code mismatch: have %x, want %x", code, []byte{0x60, 0x42})
+	}
+	// Ensure the receipts of the checkpoint are available
+	have := chain.GetReceiptsByHash(checkpoint.Hash())
+	if len(have) != 1 || have[0].TxHash != checkpoint.Transactions()[0].Hash() || have[0].GasUsed != params.TxGas {
+		t.Fatalf("checkpoint receipts mismatch: have %v", have)
+	}
+	if lookup := rawdb.ReadTxLookupEntry(db, checkpoint.Transactions()[0].Hash()); lookup == nil || *lookup != checkpoint.NumberU64() {
+		t.Fatalf("checkpoint transaction lookup mismatch: have %v, want %d", lookup, checkpoint.NumberU64())
+	}
+	// Ensure the chain can be extended on top of the checkpoint
+	_, more, _ := GenerateChainWithGenesis(gspec, beacon.NewFaker(), 6, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0xbb}, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(more[4:]); err != nil {
+		t.Fatalf("failed to extend checkpoint: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Number.Uint64() != 6 {
+		t.Fatalf("extended head mismatch: have %d, want %d", head.Number, 6)
+	}
+	// Ensure the extended chain and its state survive a restart
+	chain.Stop()
+	chain, err = NewBlockChain(db, DefaultCacheConfigWithScheme(scheme), gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if head := chain.CurrentBlock(); head.Hash() != more[5].Hash() {
+		t.Fatalf("reopened head mismatch: have %d %x, want %d %x", head.Number, head.Hash(), more[5].Number(), more[5].Hash())
+	}
+	if statedb, err = chain.State(); err != nil {
+		t.Fatalf("failed to open reopened state: %v", err)
+	}
+	if balance := statedb.GetBalance(common.Address{0xbb}); balance.Cmp(big.NewInt(6000)) != 0 {
+		t.Errorf("reopened balance mismatch: have %v, want %v", balance, 6000)
+	}
+	// Ensure a second checkpoint can't be written over the chain
+	if err := WriteCheckpoint(db, scheme, checkpoint, checkpointReceipts, nil, bytes.NewReader(dump)); err == nil {
+		t.Fatalf("checkpoint written over existing chain")
+	}
+}
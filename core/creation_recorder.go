@@ -0,0 +1,163 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// contractCreation is a contract created during block processing.
+type contractCreation struct {
+	contract common.Address
+	creator  common.Address
+	txIndex  int
+}
+
+// creationFrame is a call frame of the transaction being executed, collecting
+// the contracts created within it until the frame is known to succeed.
+type creationFrame struct {
+	create    bool           // Whether the frame is a contract creation
+	creator   common.Address // Account executing the frame
+	contract  common.Address // Account the frame is executed on
+	creations []contractCreation
+}
+
+// creationRecorder is an EVM logger collecting the contracts created by the
+// transactions of a block, including the ones created internally through the
+// CREATE and CREATE2 opcodes. Creations within reverted call frames are dropped.
+// All events are forwarded to an optional wrapped logger.
+type creationRecorder struct {
+	inner     vm.EVMLogger
+	txIndex   int              // Index of the transaction being executed, -1 before the first
+	inTx      bool             // Whether a transaction is being executed, as opposed to system calls
+	frames    []*creationFrame // Call frames of the transaction being executed
+	creations []contractCreation
+}
+
+// newCreationRecorder creates a contract creation recorder, forwarding the
+// events to the given logger if it's not nil.
+func newCreationRecorder(inner vm.EVMLogger) *creationRecorder {
+	return &creationRecorder{inner: inner, txIndex: -1}
+}
+
+// write stores the recorded contract creations of a processed block.
+func (r *creationRecorder) write(db ethdb.KeyValueWriter, block *types.Block) {
+	txs := block.Transactions()
+	for _, creation := range r.creations {
+		rawdb.WriteContractCreation(db, creation.contract, block.NumberU64(), block.Hash(), &rawdb.ContractCreation{
+			Creator: creation.creator,
+			TxHash:  txs[creation.txIndex].Hash(),
+		})
+	}
+}
+
+// enter opens a new call frame.
+func (r *creationRecorder) enter(create bool, from, to common.Address) {
+	if r.inTx {
+		r.frames = append(r.frames, &creationFrame{create: create, creator: from, contract: to})
+	}
+}
+
+// exit closes the current call frame, merging its creations into the parent
+// frame, or into the block's creations for the top frame, unless it failed.
+func (r *creationRecorder) exit(err error) {
+	if !r.inTx || len(r.frames) == 0 {
+		return
+	}
+	frame := r.frames[len(r.frames)-1]
+	r.frames = r.frames[:len(r.frames)-1]
+	if err != nil {
+		return
+	}
+	if frame.create {
+		frame.creations = append([]contractCreation{{contract: frame.contract, creator: frame.creator, txIndex: r.txIndex}}, frame.creations...)
+	}
+	if len(r.frames) == 0 {
+		r.creations = append(r.creations, frame.creations...)
+		return
+	}
+	parent := r.frames[len(r.frames)-1]
+	parent.creations = append(parent.creations, frame.creations...)
+}
+
+// CaptureTxStart implements vm.EVMLogger, starting a new transaction.
+func (r *creationRecorder) CaptureTxStart(gasLimit uint64) {
+	r.txIndex, r.inTx, r.frames = r.txIndex+1, true, nil
+	if r.inner != nil {
+		r.inner.CaptureTxStart(gasLimit)
+	}
+}
+
+// CaptureTxEnd implements vm.EVMLogger, finishing the current transaction.
+func (r *creationRecorder) CaptureTxEnd(restGas uint64) {
+	r.inTx = false
+	if r.inner != nil {
+		r.inner.CaptureTxEnd(restGas)
+	}
+}
+
+// CaptureStart implements vm.EVMLogger, opening the top call frame.
+func (r *creationRecorder) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	r.enter(create, from, to)
+	if r.inner != nil {
+		r.inner.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+// CaptureEnd implements vm.EVMLogger, closing the top call frame.
+func (r *creationRecorder) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	r.exit(err)
+	if r.inner != nil {
+		r.inner.CaptureEnd(output, gasUsed, err)
+	}
+}
+
+// CaptureEnter implements vm.EVMLogger, opening an inner call frame.
+func (r *creationRecorder) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	r.enter(typ == vm.CREATE || typ == vm.CREATE2, from, to)
+	if r.inner != nil {
+		r.inner.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+// CaptureExit implements vm.EVMLogger, closing an inner call frame.
+func (r *creationRecorder) CaptureExit(output []byte, gasUsed uint64, err error) {
+	r.exit(err)
+	if r.inner != nil {
+		r.inner.CaptureExit(output, gasUsed, err)
+	}
+}
+
+// CaptureState implements vm.EVMLogger, forwarding the step to the wrapped logger.
+func (r *creationRecorder) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if r.inner != nil {
+		r.inner.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+// CaptureFault implements vm.EVMLogger, forwarding the fault to the wrapped logger.
+func (r *creationRecorder) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if r.inner != nil {
+		r.inner.CaptureFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
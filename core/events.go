@@ -41,3 +41,9 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// FinalityEvent is posted when the safe or finalized block of the chain is set.
+type FinalityEvent struct {
+	Safe      *types.Header // Current safe block, nil if unknown
+	Finalized *types.Header // Current finalized block, nil if unknown
+}
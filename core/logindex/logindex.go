@@ -0,0 +1,508 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package logindex implements an index of the logs in the canonical chain keyed
+// by their emitting address and topics.
+//
+// For every address and topic value occurring in a block, the index stores the
+// positions of the logs containing it within the block. Unlike bloom filters the
+// index has no false positives, so a query only ever touches blocks that really
+// contain matching logs.
+//
+// The index is optional and complements the bloom bits, which are still maintained
+// and serve the queries outside of the indexed range.
+//
+// Optionally, the standard token transfer events are additionally indexed by the
+// token contract and by the holders whose balances they change.
+package logindex
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// kindAddress is the index entry kind of log addresses, topics are stored
+	// with the kind kindTopic plus their position within the log.
+	kindAddress = 0
+	kindTopic   = 1
+
+	// maxTopics is the maximum number of topics a log can have.
+	maxTopics = 4
+
+	// kindToken and kindHolder are the index entry kinds of token transfers,
+	// keyed by the token contract and by the sending and receiving holders.
+	kindToken  = kindTopic + maxTopics
+	kindHolder = kindToken + 1
+
+	// batchBlocks is the maximum number of blocks indexed or unindexed in a
+	// single database batch.
+	batchBlocks = 1024
+)
+
+// Chain is the subset of the blockchain methods needed to maintain the index.
+type Chain interface {
+	// CurrentBlock retrieves the head of the canonical chain.
+	CurrentBlock() *types.Header
+
+	// SubscribeChainHeadEvent subscribes to canonical head changes.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// status is the persisted range of blocks covered by the index.
+type status struct {
+	Tail     uint64      // Oldest indexed block
+	Head     uint64      // Newest indexed block
+	HeadHash common.Hash // Hash of the newest indexed block, to detect reorgs
+	Tokens   bool        `rlp:"optional"` // Whether token transfers are indexed
+}
+
+// Index maintains the log index of the canonical chain, following its head
+// and rolling back blocks that are reorged out.
+type Index struct {
+	db      ethdb.Database
+	chain   Chain
+	history uint64 // Number of recent blocks to index, zero means the entire chain
+	tokens  bool   // Whether to index token transfers too
+
+	status *status // Indexed block range, nil if nothing is indexed yet
+	lock   sync.RWMutex
+
+	headCh  chan core.ChainHeadEvent
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New creates a log index over the canonical chain and starts indexing it in
+// the background. Only the logs of the most recent history blocks are indexed,
+// or the entire chain if history is zero. If tokens is set, token transfers are
+// indexed too, toggling it reindexes the logs from scratch.
+func New(db ethdb.Database, chain Chain, history uint64, tokens bool) *Index {
+	idx := &Index{
+		db:      db,
+		chain:   chain,
+		history: history,
+		tokens:  tokens,
+		headCh:  make(chan core.ChainHeadEvent, 10),
+		closeCh: make(chan struct{}),
+	}
+	if blob := rawdb.ReadLogIndexStatus(db); len(blob) > 0 {
+		idx.status = new(status)
+		if err := rlp.DecodeBytes(blob, idx.status); err != nil {
+			log.Warn("Corrupted log index status, reindexing", "err", err)
+			idx.status = nil
+			rawdb.DeleteLogIndex(db)
+		} else if idx.status.Tokens != tokens {
+			log.Info("Token transfer indexing toggled, reindexing logs", "tokens", tokens)
+			idx.status = nil
+			rawdb.DeleteLogIndex(db)
+		}
+	}
+	idx.wg.Add(1)
+	go idx.loop()
+	return idx
+}
+
+// Close stops the indexer and waits for it to terminate.
+func (idx *Index) Close() {
+	close(idx.closeCh)
+	idx.wg.Wait()
+}
+
+// Range returns the range of blocks covered by the index. The returned flag is
+// false if no blocks have been indexed yet.
+func (idx *Index) Range() (tail uint64, head uint64, ok bool) {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	if idx.status == nil {
+		return 0, 0, false
+	}
+	return idx.status.Tail, idx.status.Head, true
+}
+
+// loop follows the head of the chain, keeping the index in sync with it.
+func (idx *Index) loop() {
+	defer idx.wg.Done()
+
+	sub := idx.chain.SubscribeChainHeadEvent(idx.headCh)
+	defer sub.Unsubscribe()
+
+	// Keep indexing until the index is in sync with the chain, checking for new
+	// heads and retrying missing data periodically.
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-idx.headCh:
+		case <-retry.C:
+		case <-sub.Err():
+			return
+		case <-idx.closeCh:
+			return
+		}
+		done, err := idx.update()
+		if err != nil {
+			if errors.Is(err, errClosed) {
+				return
+			}
+			log.Error("Failed to update log index", "err", err)
+		}
+		if !done {
+			retry.Reset(time.Second)
+		}
+	}
+}
+
+var (
+	// errClosed is returned if the index is closed while updating.
+	errClosed = errors.New("log index closed")
+
+	// errMissingData is returned if the receipts of a canonical block are not
+	// available yet, e.g. while the chain is syncing.
+	errMissingData = errors.New("missing receipts")
+)
+
+// update brings the index in sync with the head of the chain, processing at
+// most a batch of blocks at a time. The returned flag reports whether the index
+// is fully in sync with the head.
+func (idx *Index) update() (bool, error) {
+	for {
+		// Drain head events while indexing, the chain head is re-read on every
+		// batch anyway and the event feed must not be blocked.
+		select {
+		case <-idx.closeCh:
+			return false, errClosed
+		case <-idx.headCh:
+		default:
+		}
+		head := idx.chain.CurrentBlock()
+		if head == nil {
+			return true, nil
+		}
+		number := head.Number.Uint64()
+
+		idx.lock.RLock()
+		current := idx.status
+		idx.lock.RUnlock()
+
+		// Index the head block if nothing is indexed yet
+		if current == nil {
+			if err := idx.extendHead(nil, number); err != nil {
+				return false, ignoreMissing(err)
+			}
+			continue
+		}
+		// Roll back any reorged or rewound blocks from the head of the index
+		if current.Head > number || rawdb.ReadCanonicalHash(idx.db, current.Head) != current.HeadHash {
+			if err := idx.rollback(current, number); err != nil {
+				return false, err
+			}
+			continue
+		}
+		// Extend the index to the new head of the chain
+		if current.Head < number {
+			if err := idx.extendHead(current, number); err != nil {
+				return false, ignoreMissing(err)
+			}
+			continue
+		}
+		// Extend or prune the tail to cover the configured history
+		var tail uint64
+		if idx.history != 0 && number+1 > idx.history {
+			tail = number + 1 - idx.history
+		}
+		if current.Tail > tail {
+			if err := idx.extendTail(current, tail); err != nil {
+				return false, ignoreMissing(err)
+			}
+			continue
+		}
+		if current.Tail < tail {
+			if err := idx.pruneTail(current, tail); err != nil {
+				return false, err
+			}
+			continue
+		}
+		return true, nil
+	}
+}
+
+// ignoreMissing swallows errors caused by missing chain data, which the index
+// simply retries later on.
+func ignoreMissing(err error) error {
+	if errors.Is(err, errMissingData) {
+		return nil
+	}
+	return err
+}
+
+// extendHead indexes the canonical blocks following the current head of the
+// index, up to the given block number. If nothing is indexed yet, the index is
+// started at the given block.
+func (idx *Index) extendHead(current *status, number uint64) error {
+	if current == nil {
+		hash := rawdb.ReadCanonicalHash(idx.db, number)
+		if hash == (common.Hash{}) {
+			return errMissingData
+		}
+		batch := idx.db.NewBatch()
+		if err := idx.indexBlock(batch, hash, number, false); err != nil {
+			return err
+		}
+		log.Info("Started log index", "number", number, "hash", hash)
+		return idx.commit(batch, &status{Tail: number, Head: number, HeadHash: hash, Tokens: idx.tokens})
+	}
+	var (
+		next  = *current
+		batch = idx.db.NewBatch()
+		err   error
+	)
+	for next.Head < number && next.Head-current.Head < batchBlocks {
+		hash := rawdb.ReadCanonicalHash(idx.db, next.Head+1)
+		if hash == (common.Hash{}) {
+			err = errMissingData
+			break
+		}
+		// Ensure the block extends the indexed chain, the canonical chain
+		// may be in the middle of a reorg
+		if header := rawdb.ReadHeader(idx.db, hash, next.Head+1); header == nil || header.ParentHash != next.HeadHash {
+			err = errMissingData
+			break
+		}
+		if err = idx.indexBlock(batch, hash, next.Head+1, false); err != nil {
+			break
+		}
+		next.Head, next.HeadHash = next.Head+1, hash
+	}
+	if next == *current {
+		return err
+	}
+	return idx.commit(batch, &next)
+}
+
+// extendTail indexes the canonical blocks preceding the current tail of the
+// index, down to the given block number.
+func (idx *Index) extendTail(current *status, tail uint64) error {
+	var (
+		next  = *current
+		batch = idx.db.NewBatch()
+		err   error
+	)
+	for next.Tail > tail && current.Tail-next.Tail < batchBlocks {
+		hash := rawdb.ReadCanonicalHash(idx.db, next.Tail-1)
+		if hash == (common.Hash{}) {
+			err = errMissingData
+			break
+		}
+		if err = idx.indexBlock(batch, hash, next.Tail-1, false); err != nil {
+			break
+		}
+		next.Tail--
+	}
+	if next == *current {
+		return err
+	}
+	if next.Tail == tail {
+		log.Info("Log index tail reached", "tail", next.Tail, "head", next.Head)
+	} else {
+		log.Debug("Extended log index tail", "tail", next.Tail, "head", next.Head)
+	}
+	return idx.commit(batch, &next)
+}
+
+// pruneTail removes the blocks preceding the given block number from the index.
+func (idx *Index) pruneTail(current *status, tail uint64) error {
+	var (
+		next  = *current
+		batch = idx.db.NewBatch()
+	)
+	for next.Tail < tail && next.Tail-current.Tail < batchBlocks {
+		hash := rawdb.ReadCanonicalHash(idx.db, next.Tail)
+		if err := idx.indexBlock(batch, hash, next.Tail, true); err != nil && !errors.Is(err, errMissingData) {
+			return err
+		}
+		next.Tail++
+	}
+	return idx.commit(batch, &next)
+}
+
+// rollback removes the blocks from the head of the index which are not part of
+// the canonical chain anymore, or are above the given head block number.
+func (idx *Index) rollback(current *status, number uint64) error {
+	var (
+		next  = *current
+		batch = idx.db.NewBatch()
+	)
+	for current.Head-next.Head < batchBlocks {
+		if next.Head <= number && rawdb.ReadCanonicalHash(idx.db, next.Head) == next.HeadHash {
+			break
+		}
+		header := rawdb.ReadHeader(idx.db, next.HeadHash, next.Head)
+		if header == nil {
+			// The reorged block is gone, so its entries can't be found
+			// anymore, drop the entire index and start over.
+			log.Warn("Reorged block missing, resetting log index", "number", next.Head, "hash", next.HeadHash)
+			return idx.reset()
+		}
+		if err := idx.indexBlock(batch, next.HeadHash, next.Head, true); err != nil {
+			if !errors.Is(err, errMissingData) {
+				return err
+			}
+			return idx.reset()
+		}
+		if next.Head == next.Tail {
+			// Everything indexed was reorged out, start over from the new head
+			return idx.reset()
+		}
+		next.Head, next.HeadHash = next.Head-1, header.ParentHash
+	}
+	log.Debug("Rolled back log index", "from", current.Head, "to", next.Head)
+	return idx.commit(batch, &next)
+}
+
+// reset removes all data from the index.
+func (idx *Index) reset() error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	rawdb.DeleteLogIndex(idx.db)
+	idx.status = nil
+	return nil
+}
+
+// commit writes the index changes accumulated in the batch along with the new
+// status of the index.
+func (idx *Index) commit(batch ethdb.Batch, next *status) error {
+	blob, err := rlp.EncodeToBytes(next)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteLogIndexStatus(batch, blob)
+
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	idx.status = next
+	return nil
+}
+
+// indexBlock adds the logs of the given block to the index, or removes them if
+// the remove flag is set.
+func (idx *Index) indexBlock(batch ethdb.KeyValueWriter, hash common.Hash, number uint64, remove bool) error {
+	receipts := rawdb.ReadRawReceipts(idx.db, hash, number)
+	if receipts == nil {
+		return errMissingData
+	}
+	var (
+		entries = make(map[string][]uint32)
+		keys    []string
+		add     = func(kind byte, value []byte, position uint32) {
+			key := string(append([]byte{kind}, value...))
+			positions, ok := entries[key]
+			if !ok {
+				keys = append(keys, key)
+			}
+			// Logs are processed in order, so only the last position
+			// needs checking to avoid duplicates
+			if len(positions) == 0 || positions[len(positions)-1] != position {
+				entries[key] = append(positions, position)
+			}
+		}
+		position uint32
+	)
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			add(kindAddress, log.Address.Bytes(), position)
+			for i, topic := range log.Topics {
+				if i >= maxTopics {
+					break
+				}
+				add(kindTopic+byte(i), topic.Bytes(), position)
+			}
+			if idx.tokens {
+				if transfers := DecodeTransfers(log); len(transfers) > 0 {
+					add(kindToken, log.Address.Bytes(), position)
+					for _, holder := range transferHolders(transfers) {
+						add(kindHolder, holder.Bytes(), position)
+					}
+				}
+			}
+			position++
+		}
+	}
+	for _, key := range keys {
+		if remove {
+			rawdb.DeleteLogIndexEntry(batch, key[0], []byte(key[1:]), number)
+		} else {
+			rawdb.WriteLogIndexEntry(batch, key[0], []byte(key[1:]), number, encodePositions(entries[key]))
+		}
+	}
+	return nil
+}
+
+// encodePositions encodes an ascending list of log positions as uvarint deltas.
+func encodePositions(positions []uint32) []byte {
+	var (
+		blob = make([]byte, 0, len(positions))
+		last uint32
+	)
+	for i, position := range positions {
+		if i == 0 {
+			blob = binary.AppendUvarint(blob, uint64(position))
+		} else {
+			blob = binary.AppendUvarint(blob, uint64(position-last))
+		}
+		last = position
+	}
+	return blob
+}
+
+// decodePositions decodes a list of log positions encoded by encodePositions.
+func decodePositions(blob []byte) ([]uint32, error) {
+	var (
+		positions []uint32
+		last      uint64
+	)
+	for len(blob) > 0 {
+		delta, n := binary.Uvarint(blob)
+		if n <= 0 {
+			return nil, errors.New("invalid log index entry")
+		}
+		blob = blob[n:]
+		if len(positions) > 0 {
+			delta += last
+		}
+		positions = append(positions, uint32(delta))
+		last = delta
+	}
+	return positions, nil
+}
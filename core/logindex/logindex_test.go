@@ -0,0 +1,225 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _  = crypto.GenerateKey()
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// logger1 and logger2 emit a log with the call data as its only topic
+	logger1 = common.Address{0x01, 0x01}
+	logger2 = common.Address{0x02, 0x02}
+	logCode = common.FromHex("0x60003560006000a100")
+)
+
+// newTestChain creates a chain of n blocks, using gen to select the topics of
+// the logs emitted by the two logger contracts in each block.
+func newTestChain(t *testing.T, n int, gen func(i int) (topic1, topic2 byte)) (ethdb.Database, *core.BlockChain, func(parent *types.Block, n int, gen func(i int) (byte, byte)) []*types.Block) {
+	var (
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddress: {Balance: big.NewInt(params.Ether)},
+				logger1:     {Balance: common.Big0, Code: logCode},
+				logger2:     {Balance: common.Big0, Code: logCode},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	genFn := func(gen func(i int) (byte, byte)) func(int, *core.BlockGen) {
+		return func(i int, block *core.BlockGen) {
+			topic1, topic2 := gen(i)
+			for _, call := range []struct {
+				to    common.Address
+				topic byte
+			}{{logger1, topic1}, {logger2, topic2}} {
+				if call.topic == 0 {
+					continue
+				}
+				tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddress), call.to, common.Big0, 100000, block.BaseFee(), common.Hash{call.topic}.Bytes()), signer, testKey)
+				block.AddTx(tx)
+			}
+		}
+	}
+	genDb, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, n, genFn(gen))
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	fork := func(parent *types.Block, n int, gen func(i int) (byte, byte)) []*types.Block {
+		blocks, _ := core.GenerateChain(gspec.Config, parent, engine, genDb, n, genFn(gen))
+		return blocks
+	}
+	return db, chain, fork
+}
+
+// waitIndexed waits until the index covers the given block range.
+func waitIndexed(t *testing.T, idx *Index, tail, head uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if haveTail, haveHead, ok := idx.Range(); ok && haveTail == tail && haveHead == head {
+			return
+		}
+	}
+	haveTail, haveHead, _ := idx.Range()
+	t.Fatalf("index range mismatch: have [%d, %d], want [%d, %d]", haveTail, haveHead, tail, head)
+}
+
+// checkMatches checks that a query returns logs in the expected blocks.
+func checkMatches(t *testing.T, idx *Index, from, to uint64, addresses []common.Address, topics [][]common.Hash, want []uint64) {
+	t.Helper()
+
+	matches, err := idx.Matches(context.Background(), from, to, addresses, topics)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	have := []uint64{}
+	for _, match := range matches {
+		have = append(have, match.Number)
+	}
+	if want == nil {
+		want = []uint64{}
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("matched blocks mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the logs of the chain are indexed and that queries combine address
+// and topic criteria correctly.
+func TestIndexQuery(t *testing.T) {
+	// Block i (numbered i+1) emits topic i%3+1 from logger1, and even numbered
+	// blocks also emit topic 7 from logger2.
+	db, chain, _ := newTestChain(t, 12, func(i int) (byte, byte) {
+		if (i+1)%2 == 0 {
+			return byte(i%3 + 1), 7
+		}
+		return byte(i%3 + 1), 0
+	})
+	defer chain.Stop()
+
+	idx := New(db, chain, 0, false)
+	defer idx.Close()
+	waitIndexed(t, idx, 0, 12)
+
+	var (
+		topic1 = common.Hash{1}
+		topic2 = common.Hash{2}
+		topic7 = common.Hash{7}
+	)
+	checkMatches(t, idx, 0, 12, []common.Address{logger1}, nil, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	checkMatches(t, idx, 0, 12, []common.Address{logger2}, nil, []uint64{2, 4, 6, 8, 10, 12})
+	checkMatches(t, idx, 0, 12, nil, [][]common.Hash{{topic1}}, []uint64{1, 4, 7, 10})
+	checkMatches(t, idx, 0, 12, nil, [][]common.Hash{{topic1, topic2}}, []uint64{1, 2, 4, 5, 7, 8, 10, 11})
+	checkMatches(t, idx, 3, 8, nil, [][]common.Hash{{topic1, topic2}}, []uint64{4, 5, 7, 8})
+	checkMatches(t, idx, 0, 12, []common.Address{logger2}, [][]common.Hash{{topic1}}, nil)
+	checkMatches(t, idx, 0, 12, []common.Address{logger1, logger2}, [][]common.Hash{{topic7}}, []uint64{2, 4, 6, 8, 10, 12})
+	checkMatches(t, idx, 0, 12, nil, [][]common.Hash{nil, {topic1}}, nil)
+
+	// Ensure the positions point to the matching logs of the block
+	matches, _ := idx.Matches(context.Background(), 2, 2, []common.Address{logger2}, nil)
+	if len(matches) != 1 || !reflect.DeepEqual(matches[0].Positions, []uint32{1}) {
+		t.Fatalf("log positions mismatch: have %v, want block 2 position 1", matches)
+	}
+	// Ensure queries beyond the indexed range are rejected
+	if _, err := idx.Matches(context.Background(), 0, 13, []common.Address{logger1}, nil); err != ErrOutOfRange {
+		t.Fatalf("out of range error mismatch: have %v, want %v", err, ErrOutOfRange)
+	}
+}
+
+// Tests that reorged blocks are rolled back from the index and replaced by the
+// new canonical ones.
+func TestIndexReorg(t *testing.T) {
+	db, chain, fork := newTestChain(t, 8, func(i int) (byte, byte) {
+		return 1, 0
+	})
+	defer chain.Stop()
+
+	idx := New(db, chain, 0, false)
+	defer idx.Close()
+	waitIndexed(t, idx, 0, 8)
+
+	// Reorg the chain to a longer fork emitting different logs from block 5
+	blocks := fork(chain.GetBlockByNumber(4), 6, func(i int) (byte, byte) {
+		return 0, 2
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitIndexed(t, idx, 0, 10)
+
+	checkMatches(t, idx, 0, 10, []common.Address{logger1}, nil, []uint64{1, 2, 3, 4})
+	checkMatches(t, idx, 0, 10, []common.Address{logger2}, [][]common.Hash{{{2}}}, []uint64{5, 6, 7, 8, 9, 10})
+
+	// Rewind the chain and ensure the index follows
+	if err := chain.SetHead(6); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	waitIndexed(t, idx, 0, 6)
+	checkMatches(t, idx, 0, 6, []common.Address{logger2}, nil, []uint64{5, 6})
+}
+
+// Tests that only the configured number of recent blocks is indexed.
+func TestIndexHistory(t *testing.T) {
+	db, chain, fork := newTestChain(t, 8, func(i int) (byte, byte) {
+		return 1, 1
+	})
+	defer chain.Stop()
+	idx := New(db, chain, 4, false)
+	waitIndexed(t, idx, 5, 8)
+	checkMatches(t, idx, 5, 8, []common.Address{logger1}, nil, []uint64{5, 6, 7, 8})
+
+	// Extend the chain and ensure the tail is pruned
+	if _, err := chain.InsertChain(fork(chain.GetBlockByNumber(8), 2, func(i int) (byte, byte) { return 1, 1 })); err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	waitIndexed(t, idx, 7, 10)
+	idx.Close()
+
+	// Restart the index covering the entire chain, ensuring the tail is
+	// extended from the persisted range
+	idx = New(db, chain, 0, false)
+	defer idx.Close()
+	waitIndexed(t, idx, 0, 10)
+	checkMatches(t, idx, 0, 10, []common.Address{logger1}, nil, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+}
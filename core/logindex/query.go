@@ -0,0 +1,197 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// ErrOutOfRange is returned if a query is not fully covered by the index.
+var ErrOutOfRange = errors.New("block range not indexed")
+
+// Match is a block containing logs matching a query.
+type Match struct {
+	Number    uint64   // Number of the block containing the logs
+	Positions []uint32 // Positions of the matching logs within the block
+}
+
+// criterion is a set of index values of the same kind, any of which a log needs
+// to contain to match a query.
+type criterion struct {
+	kind   byte
+	values [][]byte
+}
+
+// criteria converts the address and topic filters of a query into the index
+// values to look up. Wildcard positions are omitted.
+func criteria(addresses []common.Address, topics [][]common.Hash) []criterion {
+	var crits []criterion
+	if len(addresses) > 0 {
+		crit := criterion{kind: kindAddress}
+		for _, address := range addresses {
+			crit.values = append(crit.values, address.Bytes())
+		}
+		crits = append(crits, crit)
+	}
+	for i, sub := range topics {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		crit := criterion{kind: kindTopic + byte(i)}
+		for _, topic := range sub {
+			crit.values = append(crit.values, topic.Bytes())
+		}
+		crits = append(crits, crit)
+	}
+	return crits
+}
+
+// Indexable reports whether a query with the given filters can be served by the
+// index. Queries without any address or topic criteria match every log, which
+// the index has no use for.
+func Indexable(addresses []common.Address, topics [][]common.Hash) bool {
+	return len(criteria(addresses, topics)) > 0
+}
+
+// Matches returns the blocks within the range [from, to] containing logs that
+// are emitted by one of the given addresses and carry one of the given topics at
+// each position, in ascending block order. Empty address or topic lists match
+// anything, same as for log filters.
+//
+// The range must be covered by the index, otherwise ErrOutOfRange is returned.
+func (idx *Index) Matches(ctx context.Context, from, to uint64, addresses []common.Address, topics [][]common.Hash) ([]Match, error) {
+	tail, head, ok := idx.Range()
+	if !ok || from < tail || to > head {
+		return nil, ErrOutOfRange
+	}
+	if len(topics) > maxTopics {
+		return nil, nil // No log has that many topics
+	}
+	crits := criteria(addresses, topics)
+	if len(crits) == 0 {
+		return nil, errors.New("query has no indexable criteria")
+	}
+	return idx.match(ctx, from, to, crits)
+}
+
+// match returns the blocks within the range [from, to] containing logs which
+// satisfy all the given criteria, in ascending block order.
+func (idx *Index) match(ctx context.Context, from, to uint64, crits []criterion) ([]Match, error) {
+	var matches map[uint64][]uint32
+	for _, crit := range crits {
+		found, err := idx.lookup(ctx, crit, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if matches == nil {
+			matches = found
+		} else {
+			matches = intersect(matches, found)
+		}
+		if len(matches) == 0 {
+			return nil, nil
+		}
+	}
+	result := make([]Match, 0, len(matches))
+	for number, positions := range matches {
+		result = append(result, Match{Number: number, Positions: positions})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+	return result, nil
+}
+
+// lookup retrieves the positions of the logs matching any of the values of the
+// criterion within the given block range, grouped by block number.
+func (idx *Index) lookup(ctx context.Context, crit criterion, from, to uint64) (map[uint64][]uint32, error) {
+	found := make(map[uint64][]uint32)
+	for _, value := range crit.values {
+		var err error
+		iterErr := rawdb.IterateLogIndexEntries(idx.db, crit.kind, value, from, to, func(number uint64, blob []byte) bool {
+			if err = ctx.Err(); err != nil {
+				return false
+			}
+			var positions []uint32
+			if positions, err = decodePositions(blob); err != nil {
+				return false
+			}
+			found[number] = union(found[number], positions)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if iterErr != nil {
+			return nil, iterErr
+		}
+	}
+	return found, nil
+}
+
+// intersect returns the positions present in both match sets.
+func intersect(a, b map[uint64][]uint32) map[uint64][]uint32 {
+	result := make(map[uint64][]uint32)
+	for number, positions := range a {
+		var shared []uint32
+		for i, j := 0, 0; i < len(positions) && j < len(b[number]); {
+			switch {
+			case positions[i] < b[number][j]:
+				i++
+			case positions[i] > b[number][j]:
+				j++
+			default:
+				shared = append(shared, positions[i])
+				i, j = i+1, j+1
+			}
+		}
+		if len(shared) > 0 {
+			result[number] = shared
+		}
+	}
+	return result
+}
+
+// union merges two ascending position lists into an ascending list without
+// duplicates.
+func union(a, b []uint32) []uint32 {
+	if len(a) == 0 {
+		return b
+	}
+	result := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i, j = i+1, j+1
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Token standards of the transfer events.
+const (
+	ERC20   = "erc20"
+	ERC721  = "erc721"
+	ERC1155 = "erc1155"
+)
+
+var (
+	// TransferTopic is the event signature of ERC-20 and ERC-721 transfers.
+	TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// TransferSingleTopic is the event signature of single ERC-1155 transfers.
+	TransferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+
+	// TransferBatchTopic is the event signature of batched ERC-1155 transfers.
+	TransferBatchTopic = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// ErrNoTransfers is returned for token transfer queries if the index was not
+// configured to index token transfers.
+var ErrNoTransfers = errors.New("token transfers not indexed")
+
+// Transfer is a token transfer decoded from a standard transfer event.
+type Transfer struct {
+	Standard string         // Token standard of the event, one of ERC20, ERC721 or ERC1155
+	Token    common.Address // Token contract emitting the event
+	Operator common.Address // Account executing the transfer, ERC-1155 only
+	From     common.Address // Sending holder, zero for mints
+	To       common.Address // Receiving holder, zero for burns
+	ID       *big.Int       // Identifier of the transferred token, nil for ERC-20
+	Value    *big.Int       // Amount of tokens transferred, nil for ERC-721
+}
+
+// DecodeTransfers decodes the token transfers of a standard ERC-20, ERC-721 or
+// ERC-1155 transfer event. Nil is returned for any other or malformed log. The
+// ERC-20 and ERC-721 events share their signature and are told apart by the
+// number of indexed fields.
+func DecodeTransfers(log *types.Log) []Transfer {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	switch log.Topics[0] {
+	case TransferTopic:
+		switch {
+		case len(log.Topics) == 3 && len(log.Data) == 32:
+			return []Transfer{{
+				Standard: ERC20,
+				Token:    log.Address,
+				From:     common.BytesToAddress(log.Topics[1].Bytes()),
+				To:       common.BytesToAddress(log.Topics[2].Bytes()),
+				Value:    new(big.Int).SetBytes(log.Data),
+			}}
+		case len(log.Topics) == 4 && len(log.Data) == 0:
+			return []Transfer{{
+				Standard: ERC721,
+				Token:    log.Address,
+				From:     common.BytesToAddress(log.Topics[1].Bytes()),
+				To:       common.BytesToAddress(log.Topics[2].Bytes()),
+				ID:       log.Topics[3].Big(),
+			}}
+		}
+	case TransferSingleTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil
+		}
+		return []Transfer{{
+			Standard: ERC1155,
+			Token:    log.Address,
+			Operator: common.BytesToAddress(log.Topics[1].Bytes()),
+			From:     common.BytesToAddress(log.Topics[2].Bytes()),
+			To:       common.BytesToAddress(log.Topics[3].Bytes()),
+			ID:       new(big.Int).SetBytes(log.Data[:32]),
+			Value:    new(big.Int).SetBytes(log.Data[32:]),
+		}}
+	case TransferBatchTopic:
+		if len(log.Topics) != 4 || len(log.Data) < 64 {
+			return nil
+		}
+		ids := decodeWords(log.Data, log.Data[:32])
+		values := decodeWords(log.Data, log.Data[32:64])
+		if ids == nil || len(ids) != len(values) {
+			return nil
+		}
+		transfers := make([]Transfer, len(ids))
+		for i := range ids {
+			transfers[i] = Transfer{
+				Standard: ERC1155,
+				Token:    log.Address,
+				Operator: common.BytesToAddress(log.Topics[1].Bytes()),
+				From:     common.BytesToAddress(log.Topics[2].Bytes()),
+				To:       common.BytesToAddress(log.Topics[3].Bytes()),
+				ID:       new(big.Int).SetBytes(ids[i]),
+				Value:    new(big.Int).SetBytes(values[i]),
+			}
+		}
+		return transfers
+	}
+	return nil
+}
+
+// decodeWords decodes an ABI encoded dynamic array of 32 byte words from the
+// data, located at the given offset word. Nil is returned if it's malformed.
+func decodeWords(data []byte, offset []byte) [][]byte {
+	start := new(big.Int).SetBytes(offset)
+	if !start.IsUint64() || start.Uint64() > uint64(len(data))-32 {
+		return nil
+	}
+	pos := start.Uint64()
+	length := new(big.Int).SetBytes(data[pos : pos+32])
+	pos += 32
+	if !length.IsUint64() || length.Uint64() > (uint64(len(data))-pos)/32 {
+		return nil
+	}
+	words := make([][]byte, length.Uint64())
+	for i := range words {
+		words[i] = data[pos : pos+32]
+		pos += 32
+	}
+	return words
+}
+
+// transferHolders returns the distinct holders whose balances the transfers of
+// a single event change. The zero address of mints and burns is omitted.
+func transferHolders(transfers []Transfer) []common.Address {
+	var holders []common.Address
+	for _, holder := range []common.Address{transfers[0].From, transfers[0].To} {
+		if holder != (common.Address{}) && (len(holders) == 0 || holders[0] != holder) {
+			holders = append(holders, holder)
+		}
+	}
+	return holders
+}
+
+// Transfers returns the blocks within the range [from, to] containing token
+// transfer events of the given token contract and changing the balance of the
+// given holder, in ascending block order. Either filter may be nil, but not
+// both of them.
+//
+// The range must be covered by the index, otherwise ErrOutOfRange is returned.
+func (idx *Index) Transfers(ctx context.Context, from, to uint64, token *common.Address, holder *common.Address) ([]Match, error) {
+	idx.lock.RLock()
+	current := idx.status
+	idx.lock.RUnlock()
+
+	if current == nil || from < current.Tail || to > current.Head {
+		return nil, ErrOutOfRange
+	}
+	if !current.Tokens {
+		return nil, ErrNoTransfers
+	}
+	var crits []criterion
+	if token != nil {
+		crits = append(crits, criterion{kind: kindToken, values: [][]byte{token.Bytes()}})
+	}
+	if holder != nil {
+		crits = append(crits, criterion{kind: kindHolder, values: [][]byte{holder.Bytes()}})
+	}
+	if len(crits) == 0 {
+		return nil, errors.New("query has no token or holder")
+	}
+	return idx.match(ctx, from, to, crits)
+}
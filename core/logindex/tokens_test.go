@@ -0,0 +1,193 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the standard token transfer events are decoded and others ignored.
+func TestDecodeTransfers(t *testing.T) {
+	var (
+		token    = common.Address{0xaa}
+		operator = common.BytesToHash(common.Address{0x01}.Bytes())
+		from     = common.BytesToHash(common.Address{0x02}.Bytes())
+		to       = common.BytesToHash(common.Address{0x03}.Bytes())
+		word     = func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+		concat   = func(words ...[]byte) []byte {
+			var data []byte
+			for _, w := range words {
+				data = append(data, w...)
+			}
+			return data
+		}
+	)
+	tests := []struct {
+		log  *types.Log
+		want []Transfer
+	}{
+		// ERC-20 transfer
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferTopic, from, to}, Data: word(100)},
+			[]Transfer{{Standard: ERC20, Token: token, From: common.Address{0x02}, To: common.Address{0x03}, Value: big.NewInt(100)}},
+		},
+		// ERC-721 transfer
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferTopic, from, to, common.BigToHash(big.NewInt(7))}},
+			[]Transfer{{Standard: ERC721, Token: token, From: common.Address{0x02}, To: common.Address{0x03}, ID: big.NewInt(7)}},
+		},
+		// ERC-1155 single transfer
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferSingleTopic, operator, from, to}, Data: concat(word(7), word(100))},
+			[]Transfer{{Standard: ERC1155, Token: token, Operator: common.Address{0x01}, From: common.Address{0x02}, To: common.Address{0x03}, ID: big.NewInt(7), Value: big.NewInt(100)}},
+		},
+		// ERC-1155 batch transfer
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferBatchTopic, operator, from, to}, Data: concat(word(64), word(160), word(2), word(7), word(8), word(2), word(100), word(200))},
+			[]Transfer{
+				{Standard: ERC1155, Token: token, Operator: common.Address{0x01}, From: common.Address{0x02}, To: common.Address{0x03}, ID: big.NewInt(7), Value: big.NewInt(100)},
+				{Standard: ERC1155, Token: token, Operator: common.Address{0x01}, From: common.Address{0x02}, To: common.Address{0x03}, ID: big.NewInt(8), Value: big.NewInt(200)},
+			},
+		},
+		// Batch transfer with mismatching array lengths
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferBatchTopic, operator, from, to}, Data: concat(word(64), word(160), word(2), word(7), word(8), word(1), word(100))},
+			nil,
+		},
+		// Batch transfer with an out of bounds offset
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferBatchTopic, operator, from, to}, Data: concat(word(1024), word(64))},
+			nil,
+		},
+		// Transfer with unexpected indexed fields
+		{
+			&types.Log{Address: token, Topics: []common.Hash{TransferTopic, from}, Data: concat(word(1), word(100))},
+			nil,
+		},
+		// Unrelated event
+		{
+			&types.Log{Address: token, Topics: []common.Hash{{0x01}, from, to}, Data: word(100)},
+			nil,
+		},
+	}
+	for i, tt := range tests {
+		if have := DecodeTransfers(tt.log); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: transfers mismatch: have %+v, want %+v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that token transfers are indexed by token and holder if enabled.
+func TestIndexTransfers(t *testing.T) {
+	var (
+		// token emits an ERC-20 Transfer event with the topics and value taken
+		// from the call data
+		token1   = common.Address{0x0a}
+		token2   = common.Address{0x0b}
+		code     = common.FromHex("0x60603560005260403560203560003560206000a300")
+		alice    = common.Address{0x01}
+		bob      = common.Address{0x02}
+		carol    = common.Address{0x03}
+		addrHash = func(addr common.Address) []byte { return common.BytesToHash(addr.Bytes()).Bytes() }
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddress: {Balance: big.NewInt(params.Ether)},
+				token1:      {Balance: common.Big0, Code: code},
+				token2:      {Balance: common.Big0, Code: code},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(gspec.Config)
+		engine = ethash.NewFaker()
+	)
+	// Block 1 transfers token1 from alice to bob, block 2 token2 from bob to
+	// carol, block 3 mints token1 to carol
+	transfers := []struct {
+		token    common.Address
+		from, to common.Address
+	}{
+		{token1, alice, bob},
+		{token2, bob, carol},
+		{token1, common.Address{}, carol},
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, len(transfers), func(i int, block *core.BlockGen) {
+		var data []byte
+		data = append(data, TransferTopic.Bytes()...)
+		data = append(data, addrHash(transfers[i].from)...)
+		data = append(data, addrHash(transfers[i].to)...)
+		data = append(data, common.BigToHash(big.NewInt(100)).Bytes()...)
+
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testAddress), transfers[i].token, common.Big0, 100000, block.BaseFee(), data), signer, testKey)
+		block.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Token transfer queries are rejected if they're not indexed
+	idx := New(db, chain, 0, false)
+	waitIndexed(t, idx, 0, 3)
+	if _, err := idx.Transfers(context.Background(), 0, 3, &token1, nil); err != ErrNoTransfers {
+		t.Fatalf("unindexed transfers error mismatch: have %v, want %v", err, ErrNoTransfers)
+	}
+	idx.Close()
+
+	// Enable token transfer indexing, ensuring the index is rebuilt
+	idx = New(db, chain, 0, true)
+	defer idx.Close()
+	waitIndexed(t, idx, 0, 3)
+
+	check := func(token, holder *common.Address, want []uint64) {
+		t.Helper()
+
+		matches, err := idx.Transfers(context.Background(), 0, 3, token, holder)
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		have := []uint64{}
+		for _, match := range matches {
+			have = append(have, match.Number)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("matched blocks mismatch: have %v, want %v", have, want)
+		}
+	}
+	check(&token1, nil, []uint64{1, 3})
+	check(&token2, nil, []uint64{2})
+	check(nil, &alice, []uint64{1})
+	check(nil, &bob, []uint64{1, 2})
+	check(nil, &carol, []uint64{2, 3})
+	check(&token1, &carol, []uint64{3})
+	check(nil, &common.Address{}, []uint64{})
+}
@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -93,6 +94,78 @@ func DeleteTxLookupEntries(db ethdb.KeyValueWriter, hashes []common.Hash) {
 	}
 }
 
+// ReadSenderIndexTail retrieves the number of the oldest block whose transactions
+// are indexed by sender and nonce, or nil if the sender index was never enabled.
+func ReadSenderIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(senderIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteSenderIndexTail stores the number of the oldest block whose transactions
+// are indexed by sender and nonce.
+func WriteSenderIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(senderIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store sender index tail", "err", err)
+	}
+}
+
+// ReadSenderNonceEntry retrieves the hash of the transaction with the given
+// sender and nonce.
+func ReadSenderNonceEntry(db ethdb.KeyValueReader, sender common.Address, nonce uint64) *common.Hash {
+	data, _ := db.Get(senderNonceKey(sender, nonce))
+	if len(data) != common.HashLength {
+		return nil
+	}
+	hash := common.BytesToHash(data)
+	return &hash
+}
+
+// writeSenderNonceEntry stores the hash of the transaction with the given sender
+// and nonce, enabling sender and nonce based transaction lookups.
+func writeSenderNonceEntry(db ethdb.KeyValueWriter, sender common.Address, nonce uint64, hash common.Hash) {
+	if err := db.Put(senderNonceKey(sender, nonce), hash.Bytes()); err != nil {
+		log.Crit("Failed to store sender nonce lookup entry", "err", err)
+	}
+}
+
+// WriteSenderNonceEntriesByBlock stores the sender and nonce lookup entries of
+// every transaction from a block. Transactions whose sender cannot be derived
+// with the given signer are skipped.
+func WriteSenderNonceEntriesByBlock(db ethdb.KeyValueWriter, signer types.Signer, block *types.Block) {
+	for _, tx := range block.Transactions() {
+		if sender, err := types.Sender(signer, tx); err == nil {
+			writeSenderNonceEntry(db, sender, tx.Nonce(), tx.Hash())
+		}
+	}
+}
+
+// DeleteSenderNonceEntry removes the sender and nonce lookup entry of a
+// transaction.
+func DeleteSenderNonceEntry(db ethdb.KeyValueWriter, sender common.Address, nonce uint64) {
+	if err := db.Delete(senderNonceKey(sender, nonce)); err != nil {
+		log.Crit("Failed to delete sender nonce lookup entry", "err", err)
+	}
+}
+
+// ReadTransactionBySenderAndNonce retrieves the canonical transaction with the
+// given sender and nonce from the database, along with its added positional
+// metadata.
+func ReadTransactionBySenderAndNonce(db ethdb.Reader, sender common.Address, nonce uint64) (*types.Transaction, common.Hash, uint64, uint64) {
+	hash := ReadSenderNonceEntry(db, sender, nonce)
+	if hash == nil {
+		return nil, common.Hash{}, 0, 0
+	}
+	tx, blockHash, number, index := ReadTransaction(db, *hash)
+	if tx == nil || tx.Nonce() != nonce {
+		return nil, common.Hash{}, 0, 0
+	}
+	return tx, blockHash, number, index
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadTransaction(db ethdb.Reader, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -179,3 +252,318 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// ReadLogIndexStatus retrieves the serialized log index status.
+func ReadLogIndexStatus(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(logIndexStatusKey)
+	return data
+}
+
+// WriteLogIndexStatus stores the serialized log index status.
+func WriteLogIndexStatus(db ethdb.KeyValueWriter, status []byte) {
+	if err := db.Put(logIndexStatusKey, status); err != nil {
+		log.Crit("Failed to store log index status", "err", err)
+	}
+}
+
+// DeleteLogIndexStatus deletes the serialized log index status.
+func DeleteLogIndexStatus(db ethdb.KeyValueWriter) {
+	if err := db.Delete(logIndexStatusKey); err != nil {
+		log.Crit("Failed to remove log index status", "err", err)
+	}
+}
+
+// WriteLogIndexEntry stores the encoded positions of the logs within a block
+// that contain the given address or topic value.
+func WriteLogIndexEntry(db ethdb.KeyValueWriter, kind byte, value []byte, number uint64, positions []byte) {
+	if err := db.Put(logIndexKey(kind, value, number), positions); err != nil {
+		log.Crit("Failed to store log index entry", "err", err)
+	}
+}
+
+// DeleteLogIndexEntry removes the log index entry of an address or topic value
+// within a block.
+func DeleteLogIndexEntry(db ethdb.KeyValueWriter, kind byte, value []byte, number uint64) {
+	if err := db.Delete(logIndexKey(kind, value, number)); err != nil {
+		log.Crit("Failed to delete log index entry", "err", err)
+	}
+}
+
+// IterateLogIndexEntries calls fn with the block number and encoded positions of
+// every log index entry of the given address or topic value within the block
+// range [from, to], in ascending block order, stopping early if fn returns false.
+func IterateLogIndexEntries(db ethdb.Iteratee, kind byte, value []byte, from, to uint64, fn func(number uint64, positions []byte) bool) error {
+	prefix := logIndexKey(kind, value, 0)
+	prefix = prefix[:len(prefix)-8]
+
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		if !fn(number, it.Value()) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+// DeleteLogIndex removes all log index entries along with the index status.
+func DeleteLogIndex(db ethdb.KeyValueStore) {
+	it := db.NewIterator(logIndexPrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete log index", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if it.Error() != nil {
+		log.Crit("Failed to iterate log index", "err", it.Error())
+	}
+	DeleteLogIndexStatus(batch)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete log index", "err", err)
+	}
+}
+
+// ReadAddressIndexTail retrieves the number of the oldest block whose address
+// activity is indexed.
+func ReadAddressIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(addressIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteAddressIndexTail stores the number of the oldest block whose address
+// activity is indexed.
+func WriteAddressIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(addressIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store address index tail", "err", err)
+	}
+}
+
+// WriteAddressActivity stores the roles an address had in a transaction.
+func WriteAddressActivity(db ethdb.KeyValueWriter, address common.Address, number uint64, index uint32, roles byte) {
+	if err := db.Put(addressActivityKey(address, number, index), []byte{roles}); err != nil {
+		log.Crit("Failed to store address activity", "err", err)
+	}
+}
+
+// IterateAddressActivity calls fn with the block number, transaction index and
+// roles of every transaction the given address was active in, starting at the
+// given position and ending with block to, in ascending order. The iteration
+// stops early if fn returns false.
+func IterateAddressActivity(db ethdb.Iteratee, address common.Address, from uint64, index uint32, to uint64, fn func(number uint64, index uint32, roles byte) bool) error {
+	prefix := addressActivityKey(address, 0, 0)
+	prefix = prefix[:len(prefix)-12]
+
+	start := addressActivityKey(address, from, index)
+	it := db.NewIterator(prefix, start[len(prefix):])
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+12 || len(it.Value()) != 1 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		if !fn(number, binary.BigEndian.Uint32(key[len(prefix)+8:]), it.Value()[0]) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+// ReadAddressSection retrieves the addresses active within an address index
+// section.
+func ReadAddressSection(db ethdb.KeyValueReader, section uint64) []common.Address {
+	data, _ := db.Get(addressSectionKey(section))
+	if len(data) == 0 {
+		return nil
+	}
+	var addresses []common.Address
+	if err := rlp.DecodeBytes(data, &addresses); err != nil {
+		log.Error("Invalid address index section", "section", section, "err", err)
+		return nil
+	}
+	return addresses
+}
+
+// WriteAddressSection stores the addresses active within an address index
+// section, allowing the section to be removed later.
+func WriteAddressSection(db ethdb.KeyValueWriter, section uint64, addresses []common.Address) {
+	data, err := rlp.EncodeToBytes(addresses)
+	if err != nil {
+		log.Crit("Failed to encode address index section", "err", err)
+	}
+	if err := db.Put(addressSectionKey(section), data); err != nil {
+		log.Crit("Failed to store address index section", "err", err)
+	}
+}
+
+// DeleteAddressSection removes all address activity of the blocks [from, to]
+// belonging to an address index section, along with the section itself.
+func DeleteAddressSection(db ethdb.KeyValueStore, section uint64, from, to uint64) {
+	batch := db.NewBatch()
+	for _, address := range ReadAddressSection(db, section) {
+		prefix := append(append([]byte{}, addressActivityPrefix...), address.Bytes()...)
+
+		it := db.NewIterator(prefix, encodeBlockNumber(from))
+		for it.Next() {
+			key := it.Key()
+			if len(key) != len(prefix)+12 {
+				continue
+			}
+			if binary.BigEndian.Uint64(key[len(prefix):]) > to {
+				break
+			}
+			batch.Delete(key)
+		}
+		it.Release()
+	}
+	batch.Delete(addressSectionKey(section))
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete address index section", "err", err)
+	}
+}
+
+// ReadInternalCalls retrieves the accounts called internally by each transaction
+// of a block, as recorded when the block was executed. Nil is returned if the
+// calls of the block were not recorded.
+func ReadInternalCalls(db ethdb.KeyValueReader, hash common.Hash, number uint64) [][]common.Address {
+	data, _ := db.Get(internalCallsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var calls [][]common.Address
+	if err := rlp.DecodeBytes(data, &calls); err != nil {
+		log.Error("Invalid internal calls RLP", "hash", hash, "number", number, "err", err)
+		return nil
+	}
+	return calls
+}
+
+// WriteInternalCalls stores the accounts called internally by each transaction
+// of a block.
+func WriteInternalCalls(db ethdb.KeyValueWriter, hash common.Hash, number uint64, calls [][]common.Address) {
+	data, err := rlp.EncodeToBytes(calls)
+	if err != nil {
+		log.Crit("Failed to encode internal calls", "err", err)
+	}
+	if err := db.Put(internalCallsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store internal calls", "err", err)
+	}
+}
+
+// DeleteInternalCalls removes the recorded internal calls of all the blocks in
+// the range [from, to], canonical or not.
+func DeleteInternalCalls(db ethdb.KeyValueStore, from, to uint64) {
+	batch := db.NewBatch()
+	it := db.NewIterator(internalCallsPrefix, encodeBlockNumber(from))
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(internalCallsPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(internalCallsPrefix):]) > to {
+			break
+		}
+		batch.Delete(key)
+	}
+	it.Release()
+
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete internal calls", "err", err)
+	}
+}
+
+// ContractCreation is the creator and creating transaction of a contract.
+type ContractCreation struct {
+	Creator     common.Address // Account executing the creation, an external account or a contract
+	TxHash      common.Hash    // Transaction within which the contract was created
+	BlockHash   common.Hash    `rlp:"-"`
+	BlockNumber uint64         `rlp:"-"`
+}
+
+// ReadContractIndexTail retrieves the number of the oldest block whose contract
+// creations are indexed.
+func ReadContractIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(contractIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteContractIndexTail stores the number of the oldest block whose contract
+// creations are indexed.
+func WriteContractIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(contractIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store contract index tail", "err", err)
+	}
+}
+
+// WriteContractCreation stores the creation of a contract within a block. The
+// block doesn't need to be canonical, lookups only consider canonical blocks.
+func WriteContractCreation(db ethdb.KeyValueWriter, contract common.Address, number uint64, hash common.Hash, creation *ContractCreation) {
+	data, err := rlp.EncodeToBytes(creation)
+	if err != nil {
+		log.Crit("Failed to encode contract creation", "err", err)
+	}
+	if err := db.Put(contractCreatorKey(contract, number, hash), data); err != nil {
+		log.Crit("Failed to store contract creation", "err", err)
+	}
+}
+
+// ReadContractCreation retrieves the most recent creation of a contract within
+// the canonical chain. Contracts might be created multiple times at the same
+// address if they self-destructed in between.
+func ReadContractCreation(db ethdb.Database, contract common.Address) *ContractCreation {
+	prefix := append(append([]byte{}, contractCreatorPrefix...), contract.Bytes()...)
+
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var result *ContractCreation
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+common.HashLength {
+			continue
+		}
+		var (
+			number = binary.BigEndian.Uint64(key[len(prefix):])
+			hash   = common.BytesToHash(key[len(prefix)+8:])
+		)
+		if ReadCanonicalHash(db, number) != hash {
+			continue
+		}
+		creation := new(ContractCreation)
+		if err := rlp.DecodeBytes(it.Value(), creation); err != nil {
+			log.Error("Invalid contract creation RLP", "contract", contract, "number", number, "err", err)
+			continue
+		}
+		creation.BlockHash, creation.BlockNumber = hash, number
+		result = creation
+	}
+	return result
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -84,15 +85,26 @@ func InitDatabaseFromFreezer(db ethdb.Database) {
 }
 
 type blockTxHashes struct {
-	number uint64
-	hashes []common.Hash
+	number  uint64
+	hashes  []common.Hash
+	senders []txSender // Senders and nonces of the transactions, if derivable
+}
+
+// txSender is the sender and nonce of a transaction.
+type txSender struct {
+	sender common.Address
+	nonce  uint64
+	hash   common.Hash
 }
 
 // iterateTransactions iterates over all transactions in the (canon) block
 // number(s) given, and yields the hashes on a channel. If there is a signal
 // received from interrupt channel, the iteration will be aborted and result
 // channel will be closed.
-func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockTxHashes {
+//
+// If senders is set, the senders of the transactions are derived too, provided
+// the chain configuration is available in the database.
+func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, senders bool, interrupt chan struct{}) chan *blockTxHashes {
 	// One thread sequentially reads data from db
 	type numberRlp struct {
 		number uint64
@@ -108,7 +120,11 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 	var (
 		rlpCh    = make(chan *numberRlp, threads*2)     // we send raw rlp over this channel
 		hashesCh = make(chan *blockTxHashes, threads*2) // send hashes over hashesCh
+		config   *params.ChainConfig
 	)
+	if senders {
+		config = ReadChainConfig(db, ReadCanonicalHash(db, 0))
+	}
 	// lookup runs in one instance
 	lookup := func() {
 		n, end := from, to
@@ -147,13 +163,28 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 				log.Warn("Failed to decode block body", "block", data.number, "error", err)
 				return
 			}
-			var hashes []common.Hash
+			var (
+				hashes  []common.Hash
+				senders []txSender
+				signer  types.Signer
+			)
+			if config != nil {
+				if header := ReadHeader(db, ReadCanonicalHash(db, data.number), data.number); header != nil {
+					signer = types.MakeSigner(config, header.Number, header.Time)
+				}
+			}
 			for _, tx := range body.Transactions {
 				hashes = append(hashes, tx.Hash())
+				if signer != nil {
+					if sender, err := types.Sender(signer, tx); err == nil {
+						senders = append(senders, txSender{sender, tx.Nonce(), tx.Hash()})
+					}
+				}
 			}
 			result := &blockTxHashes{
-				hashes: hashes,
-				number: data.number,
+				hashes:  hashes,
+				senders: senders,
+				number:  data.number,
 			}
 			// Feed the block to the aggregator, or abort on interrupt
 			select {
@@ -178,13 +209,13 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
+func indexTransactions(db ethdb.Database, from uint64, to uint64, senders bool, interrupt chan struct{}, hook func(uint64) bool) {
 	// short circuit for invalid range
 	if from >= to {
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, true, interrupt)
+		hashesCh = iterateTransactions(db, from, to, true, senders, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -214,6 +245,9 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 			delivery := queue.PopItem()
 			lastNum = delivery.number
 			WriteTxLookupEntries(batch, delivery.number, delivery.hashes)
+			for _, sender := range delivery.senders {
+				writeSenderNonceEntry(batch, sender.sender, sender.nonce, sender.hash)
+			}
 			blocks++
 			txs += len(delivery.hashes)
 			// If enough data was accumulated in memory or we're at the last block, dump to disk
@@ -236,6 +270,11 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 	// that the last batch is empty because nothing to index, but the tail has to
 	// be flushed anyway.
 	WriteTxIndexTail(batch, lastNum)
+	if senders {
+		if tail := ReadSenderIndexTail(db); tail == nil || *tail > lastNum {
+			WriteSenderIndexTail(batch, lastNum)
+		}
+	}
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed writing batch to db", "error", err)
 		return
@@ -257,26 +296,28 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func IndexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}) {
-	indexTransactions(db, from, to, interrupt, nil)
+//
+// If senders is set, the transactions are indexed by sender and nonce too.
+func IndexTransactions(db ethdb.Database, from uint64, to uint64, senders bool, interrupt chan struct{}) {
+	indexTransactions(db, from, to, senders, interrupt, nil)
 }
 
 // indexTransactionsForTesting is the internal debug version with an additional hook.
 func indexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
-	indexTransactions(db, from, to, interrupt, hook)
+	indexTransactions(db, from, to, false, interrupt, hook)
 }
 
 // unindexTransactions removes txlookup indices of the specified block range.
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
+func unindexTransactions(db ethdb.Database, from uint64, to uint64, senders bool, interrupt chan struct{}, hook func(uint64) bool) {
 	// short circuit for invalid range
 	if from >= to {
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, false, interrupt)
+		hashesCh = iterateTransactions(db, from, to, false, senders, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -303,6 +344,9 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 			delivery := queue.PopItem()
 			nextNum = delivery.number + 1
 			DeleteTxLookupEntries(batch, delivery.hashes)
+			for _, sender := range delivery.senders {
+				DeleteSenderNonceEntry(batch, sender.sender, sender.nonce)
+			}
 			txs += len(delivery.hashes)
 			blocks++
 
@@ -328,6 +372,11 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 	// that the last batch is empty because nothing to unindex, but the tail has to
 	// be flushed anyway.
 	WriteTxIndexTail(batch, nextNum)
+	if senders {
+		if tail := ReadSenderIndexTail(db); tail != nil && *tail < nextNum {
+			WriteSenderIndexTail(batch, nextNum)
+		}
+	}
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed writing batch to db", "error", err)
 		return
@@ -345,11 +394,13 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}) {
-	unindexTransactions(db, from, to, interrupt, nil)
+//
+// If senders is set, the sender and nonce indices are removed too.
+func UnindexTransactions(db ethdb.Database, from uint64, to uint64, senders bool, interrupt chan struct{}) {
+	unindexTransactions(db, from, to, senders, interrupt, nil)
 }
 
 // unindexTransactionsForTesting is the internal debug version with an additional hook.
 func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
-	unindexTransactions(db, from, to, interrupt, hook)
+	unindexTransactions(db, from, to, false, interrupt, hook)
 }
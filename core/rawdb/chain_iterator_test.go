@@ -25,6 +25,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestChainIterator(t *testing.T) {
@@ -80,7 +82,7 @@ func TestChainIterator(t *testing.T) {
 	}
 	for i, c := range cases {
 		var numbers []int
-		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, nil)
+		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, false, nil)
 		if hashCh != nil {
 			for h := range hashCh {
 				numbers = append(numbers, int(h.number))
@@ -162,18 +164,18 @@ func TestIndexTransactions(t *testing.T) {
 			t.Fatalf("Transaction tail mismatch")
 		}
 	}
-	IndexTransactions(chainDb, 5, 11, nil)
+	IndexTransactions(chainDb, 5, 11, false, nil)
 	verify(5, 11, true, 5)
 	verify(0, 5, false, 5)
 
-	IndexTransactions(chainDb, 0, 5, nil)
+	IndexTransactions(chainDb, 0, 5, false, nil)
 	verify(0, 11, true, 0)
 
-	UnindexTransactions(chainDb, 0, 5, nil)
+	UnindexTransactions(chainDb, 0, 5, false, nil)
 	verify(5, 11, true, 5)
 	verify(0, 5, false, 5)
 
-	UnindexTransactions(chainDb, 5, 11, nil)
+	UnindexTransactions(chainDb, 5, 11, false, nil)
 	verify(0, 11, false, 11)
 
 	// Testing corner cases
@@ -190,7 +192,7 @@ func TestIndexTransactions(t *testing.T) {
 	})
 	verify(9, 11, true, 9)
 	verify(0, 9, false, 9)
-	IndexTransactions(chainDb, 0, 9, nil)
+	IndexTransactions(chainDb, 0, 9, false, nil)
 
 	signal = make(chan struct{})
 	var once2 sync.Once
@@ -206,3 +208,54 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestIndexSenderNonces(t *testing.T) {
+	// Construct test chain db with signed transactions
+	var (
+		chainDb = NewMemoryDatabase()
+		key, _  = crypto.GenerateKey()
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.LatestSigner(params.TestChainConfig)
+		to      = common.BytesToAddress([]byte{0x11})
+		txs     []*types.Transaction
+	)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(0)}, nil, nil, nil, newTestHasher())
+	WriteBlock(chainDb, block)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	WriteChainConfig(chainDb, block.Hash(), params.TestChainConfig)
+
+	for i := uint64(1); i <= 6; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(i-1, to, big.NewInt(111), 21000, big.NewInt(1), nil), signer, key)
+		txs = append(txs, tx)
+		block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newTestHasher())
+		WriteBlock(chainDb, block)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	}
+	// verify checks whether the sender and nonce lookups of the blocks in the
+	// range [from, to) are as expected.
+	verify := func(from, to int, exist bool) {
+		for i := from; i < to; i++ {
+			tx, _, number, _ := ReadTransactionBySenderAndNonce(chainDb, sender, uint64(i-1))
+			if exist && (tx == nil || tx.Hash() != txs[i-1].Hash() || number != uint64(i)) {
+				t.Fatalf("Sender nonce lookup %d mismatch: have %v in block %d", i, tx, number)
+			}
+			if !exist && ReadSenderNonceEntry(chainDb, sender, uint64(i-1)) != nil {
+				t.Fatalf("Sender nonce lookup %d is not deleted", i)
+			}
+		}
+	}
+	if tail := ReadSenderIndexTail(chainDb); tail != nil {
+		t.Fatalf("Sender index tail set before indexing: %d", *tail)
+	}
+	IndexTransactions(chainDb, 1, 7, true, nil)
+	verify(1, 7, true)
+	if tail := ReadSenderIndexTail(chainDb); tail == nil || *tail != 1 {
+		t.Fatalf("Sender index tail mismatch after indexing: have %v, want %d", tail, 1)
+	}
+	UnindexTransactions(chainDb, 1, 4, true, nil)
+	verify(1, 4, false)
+	verify(4, 7, true)
+	if tail := ReadSenderIndexTail(chainDb); tail == nil || *tail != 4 {
+		t.Fatalf("Sender index tail mismatch after unindexing: have %v, want %d", tail, 4)
+	}
+}
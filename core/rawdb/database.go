@@ -476,6 +476,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageSnaps    stat
 		preimages       stat
 		bloomBits       stat
+		logIndex        stat
+		addressIndex    stat
+		contractIndex   stat
 		beaconHeaders   stat
 		cliqueSnaps     stat
 
@@ -522,6 +525,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
 			txLookups.Add(size)
+		case bytes.HasPrefix(key, senderNoncePrefix) && len(key) == (len(senderNoncePrefix)+common.AddressLength+8):
+			txLookups.Add(size)
 		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
 			accountSnaps.Add(size)
 		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
@@ -536,6 +541,18 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
 			bloomBits.Add(size)
+		case bytes.HasPrefix(key, logIndexPrefix) && (len(key) == len(logIndexPrefix)+1+common.AddressLength+8 || len(key) == len(logIndexPrefix)+1+common.HashLength+8):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, addressActivityPrefix) && len(key) == len(addressActivityPrefix)+common.AddressLength+12:
+			addressIndex.Add(size)
+		case bytes.HasPrefix(key, addressSectionPrefix) && len(key) == len(addressSectionPrefix)+8:
+			addressIndex.Add(size)
+		case bytes.HasPrefix(key, internalCallsPrefix) && len(key) == len(internalCallsPrefix)+8+common.HashLength:
+			addressIndex.Add(size)
+		case bytes.HasPrefix(key, contractCreatorPrefix) && len(key) == len(contractCreatorPrefix)+common.AddressLength+8+common.HashLength:
+			contractIndex.Add(size)
+		case bytes.HasPrefix(key, AddressIndexPrefix):
+			addressIndex.Add(size)
 		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
 			beaconHeaders.Add(size)
 		case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
@@ -555,7 +572,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
-				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey,
+				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, logIndexStatusKey,
+				addressIndexTailKey, contractIndexTailKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -583,6 +601,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
+		{"Key-Value store", "Address index", addressIndex.Size(), addressIndex.Count()},
+		{"Key-Value store", "Contract creation index", contractIndex.Size(), contractIndex.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
 		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
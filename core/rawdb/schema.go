@@ -76,6 +76,19 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// logIndexStatusKey tracks the range of blocks whose logs have been indexed.
+	logIndexStatusKey = []byte("LogIndexStatus")
+
+	// contractIndexTailKey tracks the oldest block whose contract creations are indexed.
+	contractIndexTailKey = []byte("ContractIndexTail")
+
+	// senderIndexTailKey tracks the oldest block whose transactions are indexed
+	// by sender and nonce.
+	senderIndexTailKey = []byte("SenderIndexTail")
+
+	// addressIndexTailKey tracks the oldest block whose address activity is indexed.
+	addressIndexTailKey = []byte("AddressIndexTail")
+
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
@@ -101,7 +114,13 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	senderNoncePrefix     = []byte("N") // senderNoncePrefix + sender + nonce (uint64 big endian) -> transaction hash
+	contractCreatorPrefix = []byte("C") // contractCreatorPrefix + contract + num (uint64 big endian) + hash -> contract creation
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	logIndexPrefix        = []byte("g") // logIndexPrefix + kind + address/topic + num (uint64 big endian) -> log positions
+	addressActivityPrefix = []byte("x") // addressActivityPrefix + address + num (uint64 big endian) + tx index (uint32 big endian) -> roles
+	addressSectionPrefix  = []byte("X") // addressSectionPrefix + section (uint64 big endian) -> addresses active in the section
+	internalCallsPrefix   = []byte("k") // internalCallsPrefix + num (uint64 big endian) + hash -> accounts called internally by each transaction
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
@@ -119,6 +138,9 @@ var (
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
+	// AddressIndexPrefix is the data table of the address activity chain indexer
+	AddressIndexPrefix = []byte("iA")
+
 	ChtPrefix           = []byte("chtRootV2-") // ChtPrefix + chtNum (uint64 big endian) -> trie root hash
 	ChtTablePrefix      = []byte("cht-")
 	ChtIndexTablePrefix = []byte("chtIndexV2-")
@@ -188,6 +210,23 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// senderNonceKey = senderNoncePrefix + sender + nonce (uint64 big endian)
+func senderNonceKey(sender common.Address, nonce uint64) []byte {
+	key := make([]byte, 0, len(senderNoncePrefix)+common.AddressLength+8)
+	key = append(key, senderNoncePrefix...)
+	key = append(key, sender.Bytes()...)
+	return binary.BigEndian.AppendUint64(key, nonce)
+}
+
+// contractCreatorKey = contractCreatorPrefix + contract + num (uint64 big endian) + hash
+func contractCreatorKey(contract common.Address, number uint64, hash common.Hash) []byte {
+	key := make([]byte, 0, len(contractCreatorPrefix)+common.AddressLength+8+common.HashLength)
+	key = append(key, contractCreatorPrefix...)
+	key = append(key, contract.Bytes()...)
+	key = append(key, encodeBlockNumber(number)...)
+	return append(key, hash.Bytes()...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -217,6 +256,37 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexKey = logIndexPrefix + kind + value + num (uint64 big endian)
+func logIndexKey(kind byte, value []byte, number uint64) []byte {
+	key := make([]byte, 0, len(logIndexPrefix)+1+len(value)+8)
+	key = append(key, logIndexPrefix...)
+	key = append(key, kind)
+	key = append(key, value...)
+	return append(key, encodeBlockNumber(number)...)
+}
+
+// addressActivityKey = addressActivityPrefix + address + num (uint64 big endian) + tx index (uint32 big endian)
+func addressActivityKey(address common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, 0, len(addressActivityPrefix)+common.AddressLength+12)
+	key = append(key, addressActivityPrefix...)
+	key = append(key, address.Bytes()...)
+	key = append(key, encodeBlockNumber(number)...)
+	return binary.BigEndian.AppendUint32(key, index)
+}
+
+// addressSectionKey = addressSectionPrefix + section (uint64 big endian)
+func addressSectionKey(section uint64) []byte {
+	return append(append([]byte{}, addressSectionPrefix...), encodeBlockNumber(section)...)
+}
+
+// internalCallsKey = internalCallsPrefix + num (uint64 big endian) + hash
+func internalCallsKey(number uint64, hash common.Hash) []byte {
+	key := make([]byte, 0, len(internalCallsPrefix)+8+common.HashLength)
+	key = append(key, internalCallsPrefix...)
+	key = append(key, encodeBlockNumber(number)...)
+	return append(key, hash.Bytes()...)
+}
+
 // skeletonHeaderKey = skeletonHeaderPrefix + num (uint64 big endian)
 func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
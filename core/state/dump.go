@@ -19,6 +19,7 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -124,6 +125,42 @@ func (d iterativeDump) OnRoot(root common.Hash) {
 	}{root})
 }
 
+// streamDump is a DumpCollector-implementation which writes the accounts in the
+// JSON format of Dump as they are iterated.
+type streamDump struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int   // number of accounts written
+	err   error // first write error, skipping any further writes
+}
+
+// write writes a part of the dump unless a previous write failed.
+func (d *streamDump) write(s string) {
+	if d.err == nil {
+		_, d.err = io.WriteString(d.w, s)
+	}
+}
+
+// OnRoot implements DumpCollector interface
+func (d *streamDump) OnRoot(root common.Hash) {
+	d.write(fmt.Sprintf(`{"root":"%x","accounts":{`, root))
+}
+
+// OnAccount implements DumpCollector interface
+func (d *streamDump) OnAccount(addr *common.Address, account DumpAccount) {
+	if addr == nil {
+		return
+	}
+	if d.count > 0 {
+		d.write(",")
+	}
+	d.write(fmt.Sprintf(`"%s":`, hexutil.Encode(addr[:])))
+	if d.err == nil {
+		d.err = d.enc.Encode(account)
+	}
+	d.count++
+}
+
 // DumpToCollector iterates the state according to the given options and inserts
 // the items into a collector for aggregation or serialization.
 func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []byte) {
@@ -239,6 +276,15 @@ func (s *StateDB) Dump(opts *DumpConfig) []byte {
 	return json
 }
 
+// StreamDump writes the state to w as a single json-object in the format of Dump,
+// holding only one account in memory at a time.
+func (s *StateDB) StreamDump(opts *DumpConfig, w io.Writer) error {
+	dump := &streamDump{w: w, enc: json.NewEncoder(w)}
+	s.DumpToCollector(dump, opts)
+	dump.write("}}")
+	return dump.err
+}
+
 // IterativeDump dumps out accounts as json-objects, delimited by linebreaks on stdout
 func (s *StateDB) IterativeDump(opts *DumpConfig, output *json.Encoder) {
 	s.DumpToCollector(iterativeDump{output}, opts)
@@ -0,0 +1,158 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkstate
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// database is a state database which falls back to retrieving trie nodes and
+// contract code from a remote chain if they are missing locally.
+type database struct {
+	state.Database
+	fork *Fork
+}
+
+// OpenTrie opens the main account trie at a specific root hash.
+func (db *database) OpenTrie(root common.Hash) (state.Trie, error) {
+	tr, err := db.Database.OpenTrie(root)
+	if isMissingNode(err) {
+		if err = db.fork.fetchAccount(common.Address{}); err == nil {
+			tr, err = db.Database.OpenTrie(root)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &forkTrie{Trie: tr, fork: db.fork}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account.
+func (db *database) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash) (state.Trie, error) {
+	tr, err := db.Database.OpenStorageTrie(stateRoot, address, root)
+	if isMissingNode(err) {
+		if err = db.fork.fetchStorage(address, common.Hash{}); err == nil {
+			tr, err = db.Database.OpenStorageTrie(stateRoot, address, root)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &forkTrie{Trie: tr, fork: db.fork}, nil
+}
+
+// CopyTrie returns an independent copy of the given trie.
+func (db *database) CopyTrie(t state.Trie) state.Trie {
+	if tr, ok := t.(*forkTrie); ok {
+		return &forkTrie{Trie: db.Database.CopyTrie(tr.Trie), fork: db.fork}
+	}
+	return db.Database.CopyTrie(t)
+}
+
+// ContractCode retrieves a particular contract's code.
+func (db *database) ContractCode(addr common.Address, codeHash common.Hash) ([]byte, error) {
+	code, err := db.Database.ContractCode(addr, codeHash)
+	if err != nil {
+		return db.fork.fetchCode(addr, codeHash)
+	}
+	return code, nil
+}
+
+// ContractCodeSize retrieves a particular contracts code's size.
+func (db *database) ContractCodeSize(addr common.Address, codeHash common.Hash) (int, error) {
+	size, err := db.Database.ContractCodeSize(addr, codeHash)
+	if err != nil {
+		code, err := db.fork.fetchCode(addr, codeHash)
+		return len(code), err
+	}
+	return size, nil
+}
+
+// forkTrie is a trie which retrieves the remote Merkle proofs of any accessed
+// item whose trie path is missing locally, retrying the access afterwards.
+type forkTrie struct {
+	state.Trie
+	fork *Fork
+}
+
+// GetStorage returns the value for key stored in the trie.
+func (t *forkTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	value, err := t.Trie.GetStorage(addr, key)
+	if isMissingNode(err) {
+		if err = t.fork.fetchStorage(addr, common.BytesToHash(key)); err == nil {
+			value, err = t.Trie.GetStorage(addr, key)
+		}
+	}
+	return value, err
+}
+
+// GetAccount abstracts an account read from the trie.
+func (t *forkTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	account, err := t.Trie.GetAccount(address)
+	if isMissingNode(err) {
+		if err = t.fork.fetchAccount(address); err == nil {
+			account, err = t.Trie.GetAccount(address)
+		}
+	}
+	return account, err
+}
+
+// UpdateStorage associates key with value in the trie.
+func (t *forkTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	err := t.Trie.UpdateStorage(addr, key, value)
+	if isMissingNode(err) {
+		if err = t.fork.fetchStorage(addr, common.BytesToHash(key)); err == nil {
+			err = t.Trie.UpdateStorage(addr, key, value)
+		}
+	}
+	return err
+}
+
+// UpdateAccount abstracts an account write to the trie.
+func (t *forkTrie) UpdateAccount(address common.Address, account *types.StateAccount) error {
+	err := t.Trie.UpdateAccount(address, account)
+	if isMissingNode(err) {
+		if err = t.fork.fetchAccount(address); err == nil {
+			err = t.Trie.UpdateAccount(address, account)
+		}
+	}
+	return err
+}
+
+// DeleteStorage removes any existing value for key from the trie.
+func (t *forkTrie) DeleteStorage(addr common.Address, key []byte) error {
+	err := t.Trie.DeleteStorage(addr, key)
+	if isMissingNode(err) {
+		if err = t.fork.fetchStorage(addr, common.BytesToHash(key)); err == nil {
+			err = t.Trie.DeleteStorage(addr, key)
+		}
+	}
+	return err
+}
+
+// DeleteAccount abstracts an account deletion from the trie.
+func (t *forkTrie) DeleteAccount(address common.Address) error {
+	err := t.Trie.DeleteAccount(address)
+	if isMissingNode(err) {
+		if err = t.fork.fetchAccount(address); err == nil {
+			err = t.Trie.DeleteAccount(address)
+		}
+	}
+	return err
+}
@@ -0,0 +1,277 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package forkstate implements a state database which lazily pulls the state of
+// a remote chain, allowing a local development chain to be forked off of it.
+//
+// State is retrieved via eth_getProof, whose Merkle proofs contain exactly the
+// trie nodes needed to access an account or storage slot. Fetched nodes are
+// persisted into the local database keyed by their hash, after which the local
+// tries can read and modify them as if the entire remote state was present.
+//
+// Deleting state might need sibling trie nodes which are not part of any proof
+// of the deleted item, so such modifications might fail with missing trie nodes.
+package forkstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// fetchTimeout is the maximum time allowed for a single remote state retrieval.
+const fetchTimeout = 30 * time.Second
+
+// Fork is a connection to a remote chain, pinned to a specific block, whose
+// state is used as the base of a local chain.
+type Fork struct {
+	client *rpc.Client         // RPC client to retrieve the remote state through
+	disk   ethdb.KeyValueStore // Local database to persist the retrieved state into
+	block  common.Hash         // Hash of the remote block whose state is forked
+	root   common.Hash         // State root of the remote block whose state is forked
+}
+
+// New creates a fork of the remote chain's state, retrieved through the given
+// RPC client. If the local database is empty, a genesis block is committed with
+// its state consisting of the remote state at the requested block (or latest if
+// nil) with the genesis allocation applied on top. The genesis block's parent
+// hash is set to the remote block hash, making the fork point retrievable when
+// reopening an existing database.
+func New(db ethdb.Database, client *rpc.Client, number *uint64, genesis *core.Genesis) (*Fork, error) {
+	// Resolve the remote block to fork from
+	var (
+		remote *types.Header
+		err    error
+	)
+	if stored := rawdb.ReadCanonicalHash(db, 0); stored != (common.Hash{}) {
+		local := rawdb.ReadHeader(db, stored, 0)
+		if local == nil {
+			return nil, errors.New("missing genesis header")
+		}
+		if remote, err = headerByHash(client, local.ParentHash); err != nil {
+			return nil, fmt.Errorf("failed to retrieve fork block: %v", err)
+		}
+		if number != nil && remote.Number.Uint64() != *number {
+			return nil, fmt.Errorf("database forked at block %d, requested %d", remote.Number, *number)
+		}
+		log.Info("Reusing forked remote state", "number", remote.Number, "hash", remote.Hash(), "root", remote.Root)
+		return &Fork{client: client, disk: db, block: remote.Hash(), root: remote.Root}, nil
+	}
+	if remote, err = headerByNumber(client, number); err != nil {
+		return nil, fmt.Errorf("failed to retrieve fork block: %v", err)
+	}
+	fork := &Fork{client: client, disk: db, block: remote.Hash(), root: remote.Root}
+	if genesis == nil {
+		return nil, errors.New("forking requires a genesis specification")
+	}
+	if err := fork.commit(db, genesis); err != nil {
+		return nil, err
+	}
+	log.Info("Forked remote state", "number", remote.Number, "hash", remote.Hash(), "root", remote.Root)
+	return fork, nil
+}
+
+// NewAt creates a fork of the remote chain's state at the given block, without
+// committing any genesis block locally. The retrieved state is persisted into
+// the given database and can be accessed directly through the block's state
+// root.
+//
+// Since all trie nodes and contract code are addressed by their hash, any state
+// read through a trusted state root is verified, even if the remote is not.
+func NewAt(db ethdb.KeyValueStore, client *rpc.Client, block common.Hash, root common.Hash) *Fork {
+	return &Fork{client: client, disk: db, block: block, root: root}
+}
+
+// commit writes a genesis block into the database, with its state being the
+// remote state with the genesis allocation applied on top.
+func (f *Fork) commit(db ethdb.Database, genesis *core.Genesis) error {
+	// Ensure the remote state root is available locally, the rest will be pulled
+	// in while the allocations are applied
+	if err := f.fetchAccount(common.Address{}); err != nil {
+		return err
+	}
+	sdb := f.Wrap(state.NewDatabase(db))
+	statedb, err := state.New(f.root, sdb, nil)
+	if err != nil {
+		return err
+	}
+	for addr, account := range genesis.Alloc {
+		statedb.SetBalance(addr, account.Balance)
+		if account.Code != nil {
+			statedb.SetCode(addr, account.Code)
+		}
+		if account.Nonce != 0 {
+			statedb.SetNonce(addr, account.Nonce)
+		}
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	root, err := statedb.Commit(0, false)
+	if err != nil {
+		return err
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		return err
+	}
+	// Assemble the genesis block on top of the forked state and persist it
+	spec := *genesis
+	spec.ParentHash = f.block
+
+	block := spec.ToBlock()
+	header := block.Header()
+	header.Root = root
+	block = block.WithSeal(header)
+
+	config := spec.Config
+	if config == nil {
+		config = params.AllEthashProtocolChanges
+	}
+	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), block.Difficulty())
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	rawdb.WriteHeadBlockHash(db, block.Hash())
+	rawdb.WriteHeadFastBlockHash(db, block.Hash())
+	rawdb.WriteHeadHeaderHash(db, block.Hash())
+	rawdb.WriteChainConfig(db, block.Hash(), config)
+	return nil
+}
+
+// Close terminates the connection to the remote chain.
+func (f *Fork) Close() {
+	f.client.Close()
+}
+
+// Wrap returns a state database which resolves any trie nodes and contract code
+// missing from the given database by retrieving them from the remote chain.
+func (f *Fork) Wrap(db state.Database) state.Database {
+	return &database{Database: db, fork: f}
+}
+
+// proofResult is the subset of an eth_getProof response needed to reconstruct
+// the trie paths leading to an account and its storage slots.
+type proofResult struct {
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []struct {
+		Proof []hexutil.Bytes `json:"proof"`
+	} `json:"storageProof"`
+}
+
+// fetchAccount retrieves the trie nodes leading to an account in the remote
+// state and persists them locally.
+func (f *Fork) fetchAccount(addr common.Address) error {
+	return f.fetch(addr, nil)
+}
+
+// fetchStorage retrieves the trie nodes leading to an account and one of its
+// storage slots in the remote state and persists them locally.
+func (f *Fork) fetchStorage(addr common.Address, slot common.Hash) error {
+	return f.fetch(addr, []string{slot.Hex()})
+}
+
+// fetch retrieves the Merkle proofs of an account and some of its storage slots
+// from the remote chain and writes all the contained trie nodes into the local
+// database.
+func (f *Fork) fetch(addr common.Address, slots []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	if slots == nil {
+		slots = []string{}
+	}
+	var result proofResult
+	if err := f.client.CallContext(ctx, &result, "eth_getProof", addr, slots, rpc.BlockNumberOrHashWithHash(f.block, false)); err != nil {
+		return fmt.Errorf("failed to retrieve remote state of %x: %v", addr, err)
+	}
+	batch := f.disk.NewBatch()
+	for _, node := range result.AccountProof {
+		rawdb.WriteLegacyTrieNode(batch, crypto.Keccak256Hash(node), node)
+	}
+	for _, proof := range result.StorageProof {
+		for _, node := range proof.Proof {
+			rawdb.WriteLegacyTrieNode(batch, crypto.Keccak256Hash(node), node)
+		}
+	}
+	return batch.Write()
+}
+
+// fetchCode retrieves the code of a contract from the remote chain and persists
+// it locally, if it matches the expected code hash.
+func (f *Fork) fetchCode(addr common.Address, codeHash common.Hash) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	var code hexutil.Bytes
+	if err := f.client.CallContext(ctx, &code, "eth_getCode", addr, rpc.BlockNumberOrHashWithHash(f.block, false)); err != nil {
+		return nil, fmt.Errorf("failed to retrieve remote code of %x: %v", addr, err)
+	}
+	if hash := crypto.Keccak256Hash(code); hash != codeHash {
+		return nil, fmt.Errorf("remote code hash mismatch for %x: have %x, want %x", addr, hash, codeHash)
+	}
+	rawdb.WriteCode(f.disk, codeHash, code)
+	return code, nil
+}
+
+// headerByHash retrieves a block header from the remote chain by hash.
+func headerByHash(client *rpc.Client, hash common.Hash) (*types.Header, error) {
+	return header(client, "eth_getBlockByHash", hash)
+}
+
+// headerByNumber retrieves a block header from the remote chain by number, or
+// the latest one if no number is given.
+func headerByNumber(client *rpc.Client, number *uint64) (*types.Header, error) {
+	if number == nil {
+		return header(client, "eth_getBlockByNumber", "latest")
+	}
+	return header(client, "eth_getBlockByNumber", hexutil.EncodeBig(new(big.Int).SetUint64(*number)))
+}
+
+// header retrieves a block header from the remote chain.
+func header(client *rpc.Client, method string, arg interface{}) (*types.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	var head *types.Header
+	if err := client.CallContext(ctx, &head, method, arg, false); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, errors.New("block not found")
+	}
+	return head, nil
+}
+
+// isMissingNode reports whether an error is caused by a missing trie node.
+func isMissingNode(err error) bool {
+	var missing *trie.MissingNodeError
+	return errors.As(err, &missing)
+}
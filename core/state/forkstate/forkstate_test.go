@@ -0,0 +1,180 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkstate_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/state/forkstate"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+var (
+	remoteAddr     = common.Address{0x01, 0x01}
+	remoteContract = common.Address{0x02, 0x02}
+	remoteCode     = []byte{0x60, 0x01, 0x60, 0x00, 0x55} // PUSH1 1 PUSH1 0 SSTORE
+	localAddr      = common.Address{0x03, 0x03}
+)
+
+// startRemote starts an in-process node serving a chain with some pre-allocated
+// accounts, contract code and storage to fork from.
+func startRemote(t *testing.T) *node.Node {
+	t.Helper()
+
+	stack, err := node.New(&node.Config{
+		IPCPath: filepath.Join(t.TempDir(), "geth.ipc"),
+		P2P:     p2p.Config{NoDiscovery: true, MaxPeers: 0},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	genesis := core.DeveloperGenesisBlock(10_000_000, remoteAddr)
+	genesis.Alloc[remoteContract] = core.GenesisAccount{
+		Balance: big.NewInt(42),
+		Code:    remoteCode,
+		Storage: map[common.Hash]common.Hash{
+			{0x01}: {0x11},
+			{0x02}: {0x22},
+		},
+	}
+	if _, err := eth.New(stack, &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync}); err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	return stack
+}
+
+// Tests that the state of a remote chain is lazily pulled in when accessed, can
+// be modified locally and that the fork point is retained across restarts.
+func TestFork(t *testing.T) {
+	remote := startRemote(t)
+	defer remote.Close()
+
+	db := rawdb.NewMemoryDatabase()
+	fork, err := forkstate.New(db, remote.Attach(), nil, core.DeveloperGenesisBlock(10_000_000, localAddr))
+	if err != nil {
+		t.Fatalf("failed to fork remote state: %v", err)
+	}
+	defer fork.Close()
+
+	genesis := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, 0), 0)
+	if genesis == nil {
+		t.Fatalf("forked genesis not committed")
+	}
+	sdb := fork.Wrap(state.NewDatabase(db))
+	statedb, err := state.New(genesis.Root, sdb, nil)
+	if err != nil {
+		t.Fatalf("failed to open forked state: %v", err)
+	}
+	// Ensure both the remote state and the local allocations are accessible
+	if statedb.GetBalance(remoteAddr).Sign() == 0 {
+		t.Errorf("remote account not funded")
+	}
+	if statedb.GetBalance(localAddr).Sign() == 0 {
+		t.Errorf("local account not funded")
+	}
+	if balance := statedb.GetBalance(remoteContract); balance.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("remote contract balance mismatch: have %v, want %v", balance, 42)
+	}
+	if code := statedb.GetCode(remoteContract); !bytes.Equal(code, remoteCode) {
+		t.Errorf("remote contract code mismatch: have %x, want %x", code, remoteCode)
+	}
+	if value := statedb.GetState(remoteContract, common.Hash{0x01}); value != (common.Hash{0x11}) {
+		t.Errorf("remote storage mismatch: have %x, want %x", value, common.Hash{0x11})
+	}
+	// Modify the remote state locally and ensure it's retained
+	statedb.SetState(remoteContract, common.Hash{0x01}, common.Hash{0xff})
+	statedb.SetState(remoteContract, common.Hash{0x03}, common.Hash{0x33})
+	statedb.AddBalance(remoteAddr, big.NewInt(1))
+
+	root, err := statedb.Commit(1, false)
+	if err != nil {
+		t.Fatalf("failed to commit forked state: %v", err)
+	}
+	if statedb, err = state.New(root, sdb, nil); err != nil {
+		t.Fatalf("failed to reopen modified state: %v", err)
+	}
+	for slot, want := range map[common.Hash]common.Hash{{0x01}: {0xff}, {0x02}: {0x22}, {0x03}: {0x33}} {
+		if have := statedb.GetState(remoteContract, slot); have != want {
+			t.Errorf("slot %x: storage mismatch: have %x, want %x", slot, have, want)
+		}
+	}
+	// Reopen the fork from the database and ensure the fork point is retained
+	reopened, err := forkstate.New(db, remote.Attach(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen fork: %v", err)
+	}
+	reopened.Close()
+
+	number := uint64(1)
+	if _, err := forkstate.New(db, remote.Attach(), &number, nil); err == nil {
+		t.Fatalf("fork point mismatch not detected")
+	}
+}
+
+// Tests that the ephemeral state databases used for tracing also resolve the
+// state of the forked remote chain.
+func TestForkStateAccessor(t *testing.T) {
+	remote := startRemote(t)
+	defer remote.Close()
+
+	stack, err := node.New(&node.Config{
+		P2P: p2p.Config{NoDiscovery: true, MaxPeers: 0},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	ethservice, err := eth.New(stack, &ethconfig.Config{
+		Genesis:     core.DeveloperGenesisBlock(10_000_000, localAddr),
+		SyncMode:    downloader.FullSync,
+		StateScheme: rawdb.HashScheme,
+		DevFork:     remote.IPCEndpoint(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	genesis := ethservice.BlockChain().Genesis()
+	for _, readOnly := range []bool{false, true} {
+		statedb, release, err := ethservice.APIBackend.StateAtBlock(context.Background(), genesis, 0, nil, readOnly, false)
+		if err != nil {
+			t.Fatalf("readonly %v: failed to open forked state: %v", readOnly, err)
+		}
+		if balance := statedb.GetBalance(remoteContract); balance.Cmp(big.NewInt(42)) != 0 {
+			t.Errorf("readonly %v: remote contract balance mismatch: have %v, want %v", readOnly, balance, 42)
+		}
+		if value := statedb.GetState(remoteContract, common.Hash{0x02}); value != (common.Hash{0x22}) {
+			t.Errorf("readonly %v: remote storage mismatch: have %x, want %x", readOnly, value, common.Hash{0x22})
+		}
+		release()
+	}
+}
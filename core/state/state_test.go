@@ -93,6 +93,20 @@ func TestDump(t *testing.T) {
 	if got != want {
 		t.Errorf("DumpToCollector mismatch:\ngot: %s\nwant: %s\n", got, want)
 	}
+	// check that the streamed dump has the same content
+	var (
+		buf      bytes.Buffer
+		streamed Dump
+	)
+	if err := s.state.StreamDump(nil, &buf); err != nil {
+		t.Fatalf("failed to stream dump: %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &streamed); err != nil {
+		t.Fatalf("invalid streamed dump %s: %v", buf.Bytes(), err)
+	}
+	if have, _ := json.MarshalIndent(streamed, "", "    "); string(have) != want {
+		t.Errorf("StreamDump mismatch:\ngot: %s\nwant: %s\n", have, want)
+	}
 }
 
 func TestIterativeDump(t *testing.T) {
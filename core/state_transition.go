@@ -328,10 +328,16 @@ func (st *StateTransition) preCheck() error {
 
 	if st.evm.ChainConfig().IsCancun(st.evm.Context.BlockNumber, st.evm.Context.Time) {
 		if st.blobGasUsed() > 0 {
-			// Check that the user is paying at least the current blob fee
-			blobFee := eip4844.CalcBlobFee(*st.evm.Context.ExcessBlobGas)
-			if st.msg.BlobGasFeeCap.Cmp(blobFee) < 0 {
-				return fmt.Errorf("%w: address %v have %v want %v", ErrBlobFeeCapTooLow, st.msg.From.Hex(), st.msg.BlobGasFeeCap, blobFee)
+			// Skip the check if the blob fee cap is zero and NoBaseFee is set, as
+			// done for the execution basefee. This allows simulating blob txs via
+			// eth_call without specifying a blob fee cap.
+			skipCheck := st.evm.Config.NoBaseFee && msg.BlobGasFeeCap.BitLen() == 0
+			if !skipCheck {
+				// Check that the user is paying at least the current blob fee
+				blobFee := eip4844.CalcBlobFee(*st.evm.Context.ExcessBlobGas)
+				if st.msg.BlobGasFeeCap.Cmp(blobFee) < 0 {
+					return fmt.Errorf("%w: address %v have %v want %v", ErrBlobFeeCapTooLow, st.msg.From.Hex(), st.msg.BlobGasFeeCap, blobFee)
+				}
 			}
 		}
 	}
@@ -0,0 +1,270 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blobpool
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/billy"
+)
+
+// ArchivedBlob is a single blob retrieved from the archive, along with its KZG
+// commitment and proof and the location where it was included in the chain.
+type ArchivedBlob struct {
+	Block      uint64             // Block in which the owner transaction was included
+	TxHash     common.Hash        // Owner transaction's hash
+	Index      int                // Position of the blob within the owner transaction
+	Blob       kzg4844.Blob       // Blob contents
+	Commitment kzg4844.Commitment // KZG commitment of the blob
+	Proof      kzg4844.Proof      // KZG proof of the blob against the commitment
+}
+
+// archiveSidecar is the database entry of the archive, wrapping the full blob
+// sidecar of an included transaction together with its inclusion block number
+// for retention eviction.
+type archiveSidecar struct {
+	TxHash  common.Hash          // Owner transaction's hash
+	Block   uint64               // Block in which the blob transaction was included
+	Sidecar *types.BlobTxSidecar // Blobs, commitments and proofs of the transaction
+}
+
+// archive is a light, indexed database to store the blobs of included blob
+// transactions for a configured number of blocks after inclusion. Contrary to
+// the limbo, the purpose is not reorg handling, rather to serve blob data to
+// users after the pool itself lost interest in it.
+//
+// Note, only blobs that went through the local pool can be archived. Blobs of
+// transactions that were directly mined without announcing them to the network
+// are not available.
+type archive struct {
+	store     billy.Database // Persistent data store for archived blobs
+	retention uint64         // Number of blocks to retain blobs for after inclusion
+
+	txs    map[common.Hash]uint64            // Mappings from tx hashes to datastore ids
+	blobs  map[common.Hash]uint64            // Mappings from versioned hashes to datastore ids
+	owned  map[uint64][]common.Hash          // Versioned hashes contained in each datastore entry
+	groups map[uint64]map[uint64]common.Hash // Set of txs included in past blocks
+}
+
+// newArchive opens and indexes a set of archived blob sidecars.
+func newArchive(datadir string, retention uint64) (*archive, error) {
+	a := &archive{
+		retention: retention,
+		txs:       make(map[common.Hash]uint64),
+		blobs:     make(map[common.Hash]uint64),
+		owned:     make(map[uint64][]common.Hash),
+		groups:    make(map[uint64]map[uint64]common.Hash),
+	}
+	// Index all archived blobs on disk and delete anything inprocessable
+	var fails []uint64
+	index := func(id uint64, size uint32, data []byte) {
+		if a.parseSidecar(id, data) != nil {
+			fails = append(fails, id)
+		}
+	}
+	store, err := billy.Open(billy.Options{Path: datadir}, newSlotter(), index)
+	if err != nil {
+		return nil, err
+	}
+	a.store = store
+
+	if len(fails) > 0 {
+		log.Warn("Dropping invalidated archived blobs", "ids", fails)
+		for _, id := range fails {
+			if err := a.store.Delete(id); err != nil {
+				a.Close()
+				return nil, err
+			}
+		}
+	}
+	return a, nil
+}
+
+// Close closes down the underlying persistent store.
+func (a *archive) Close() error {
+	return a.store.Close()
+}
+
+// parseSidecar is a callback method on archive creation that gets called for
+// each archived sidecar on disk to create the in-memory metadata index.
+func (a *archive) parseSidecar(id uint64, data []byte) error {
+	item := new(archiveSidecar)
+	if err := rlp.DecodeBytes(data, item); err != nil {
+		// This path is impossible unless the disk data representation changes
+		// across restarts. For that ever unprobable case, recover gracefully
+		// by ignoring this data entry.
+		log.Error("Failed to decode blob archive entry", "id", id, "err", err)
+		return err
+	}
+	if _, ok := a.txs[item.TxHash]; ok {
+		// This path is impossible, unless due to a programming error a sidecar
+		// gets inserted into the archive twice. Recover gracefully by ignoring
+		// this data entry.
+		log.Error("Dropping duplicate blob archive entry", "owner", item.TxHash, "id", id)
+		return errors.New("duplicate sidecar")
+	}
+	a.index(id, item)
+	return nil
+}
+
+// index inserts a stored sidecar into the in-memory indices.
+func (a *archive) index(id uint64, item *archiveSidecar) {
+	vhashes := item.Sidecar.BlobHashes()
+
+	a.txs[item.TxHash] = id
+	a.owned[id] = vhashes
+	for _, vhash := range vhashes {
+		a.blobs[vhash] = id
+	}
+	if _, ok := a.groups[item.Block]; !ok {
+		a.groups[item.Block] = make(map[uint64]common.Hash)
+	}
+	a.groups[item.Block][id] = item.TxHash
+}
+
+// drop deletes a stored sidecar from both the store and the in-memory indices.
+func (a *archive) drop(block uint64, id uint64) error {
+	delete(a.txs, a.groups[block][id])
+	for _, vhash := range a.owned[id] {
+		// Two transactions may carry the same blob, only delete the mapping if
+		// it belongs to this entry
+		if a.blobs[vhash] == id {
+			delete(a.blobs, vhash)
+		}
+	}
+	delete(a.owned, id)
+	delete(a.groups[block], id)
+	if len(a.groups[block]) == 0 {
+		delete(a.groups, block)
+	}
+	return a.store.Delete(id)
+}
+
+// prune evicts all blobs that have been included more than the retention
+// period before the given head block.
+func (a *archive) prune(head uint64) {
+	if head < a.retention {
+		return
+	}
+	limit := head - a.retention
+	for block, ids := range a.groups {
+		if block > limit {
+			continue
+		}
+		for id := range ids {
+			if err := a.drop(block, id); err != nil {
+				log.Error("Failed to drop archived blobs", "block", block, "id", id, "err", err)
+			}
+		}
+	}
+}
+
+// push stores the sidecar of an included blob transaction into the archive. If
+// the transaction is already archived (e.g. reincluded after a reorg), its block
+// number is updated instead.
+func (a *archive) push(tx *types.Transaction, block uint64) error {
+	if _, ok := a.txs[tx.Hash()]; ok {
+		return a.update(tx.Hash(), block)
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return errors.New("missing blob sidecar")
+	}
+	return a.set(&archiveSidecar{TxHash: tx.Hash(), Block: block, Sidecar: sidecar})
+}
+
+// update changes the block number under which an archived sidecar is tracked.
+// This method should be used when a reorg changes a transaction's inclusion
+// block, otherwise the blobs would be reported and pruned by the stale block.
+func (a *archive) update(txhash common.Hash, block uint64) error {
+	id, ok := a.txs[txhash]
+	if !ok {
+		return nil
+	}
+	if _, ok := a.groups[block][id]; ok {
+		return nil
+	}
+	item, err := a.get(id)
+	if err != nil {
+		return err
+	}
+	if err := a.drop(item.Block, id); err != nil {
+		return err
+	}
+	item.Block = block
+	return a.set(item)
+}
+
+// set stores an archive entry into the database, also updating the in-memory
+// indices.
+func (a *archive) set(item *archiveSidecar) error {
+	data, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		panic(err) // cannot happen runtime, dev error
+	}
+	id, err := a.store.Put(data)
+	if err != nil {
+		return err
+	}
+	a.index(id, item)
+	return nil
+}
+
+// get retrieves and decodes an archived sidecar from the store.
+func (a *archive) get(id uint64) (*archiveSidecar, error) {
+	data, err := a.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	item := new(archiveSidecar)
+	if err = rlp.DecodeBytes(data, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// blob retrieves a single archived blob by its versioned hash, or nil if the
+// blob is not tracked by the archive.
+func (a *archive) blob(vhash common.Hash) *ArchivedBlob {
+	id, ok := a.blobs[vhash]
+	if !ok {
+		return nil
+	}
+	item, err := a.get(id)
+	if err != nil {
+		log.Error("Failed to retrieve archived blobs", "blob", vhash, "id", id, "err", err)
+		return nil
+	}
+	for i, hash := range item.Sidecar.BlobHashes() {
+		if hash == vhash {
+			return &ArchivedBlob{
+				Block:      item.Block,
+				TxHash:     item.TxHash,
+				Index:      i,
+				Blob:       item.Sidecar.Blobs[i],
+				Commitment: item.Sidecar.Commitments[i],
+				Proof:      item.Sidecar.Proofs[i],
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blobpool
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that archived blobs can be retrieved by versioned hash, survive restarts,
+// track reorged inclusion blocks and get pruned after the retention period.
+func TestArchive(t *testing.T) {
+	datadir := t.TempDir()
+
+	arch, err := newArchive(datadir, 10)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	key, _ := crypto.GenerateKey()
+	tx := makeTx(0, 1, 1, 1, key)
+
+	if err := arch.push(tx, 5); err != nil {
+		t.Fatalf("failed to archive blobs: %v", err)
+	}
+	blob := arch.blob(emptyBlobVHash)
+	if blob == nil {
+		t.Fatalf("archived blob missing")
+	}
+	if blob.Block != 5 || blob.TxHash != tx.Hash() || blob.Index != 0 {
+		t.Fatalf("archived blob location mismatch: have %d/%x/%d, want %d/%x/%d", blob.Block, blob.TxHash, blob.Index, 5, tx.Hash(), 0)
+	}
+	if blob.Commitment != emptyBlobCommit || blob.Proof != emptyBlobProof {
+		t.Fatalf("archived blob commitment or proof mismatch")
+	}
+	// Reopen the archive and ensure the index is rebuilt
+	arch.Close()
+	if arch, err = newArchive(datadir, 10); err != nil {
+		t.Fatalf("failed to reopen archive: %v", err)
+	}
+	defer arch.Close()
+
+	if blob := arch.blob(emptyBlobVHash); blob == nil || blob.Block != 5 {
+		t.Fatalf("archived blob lost on restart: %+v", blob)
+	}
+	// Move the transaction into a different block and ensure it's reported
+	if err := arch.update(tx.Hash(), 7); err != nil {
+		t.Fatalf("failed to update archived blobs: %v", err)
+	}
+	if blob := arch.blob(emptyBlobVHash); blob == nil || blob.Block != 7 {
+		t.Fatalf("archived blob block not updated: %+v", blob)
+	}
+	// Prune up until just before the retention expires, then past it
+	arch.prune(16)
+	if arch.blob(emptyBlobVHash) == nil {
+		t.Fatalf("archived blob pruned before retention expired")
+	}
+	arch.prune(17)
+	if arch.blob(emptyBlobVHash) != nil {
+		t.Fatalf("archived blob not pruned after retention expired")
+	}
+	if len(arch.txs) != 0 || len(arch.blobs) != 0 || len(arch.owned) != 0 || len(arch.groups) != 0 {
+		t.Fatalf("archive indices not cleaned up: txs %d, blobs %d, owned %d, groups %d", len(arch.txs), len(arch.blobs), len(arch.owned), len(arch.groups))
+	}
+}
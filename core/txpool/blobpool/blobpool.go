@@ -81,6 +81,10 @@ const (
 	// limboedTransactionStore is the subfolder containing the currently included
 	// but not yet finalized transaction blobs.
 	limboedTransactionStore = "limbo"
+
+	// archivedTransactionStore is the subfolder containing the blobs of already
+	// included transactions, retained for serving them over RPC.
+	archivedTransactionStore = "archive"
 )
 
 // blobTxMeta is the minimal subset of types.BlobTx necessary to validate and
@@ -97,6 +101,7 @@ type blobTxMeta struct {
 	execTipCap *uint256.Int // Needed to prioritize inclusion order across accounts and validate replacement price bump
 	execFeeCap *uint256.Int // Needed to validate replacement price bump
 	blobFeeCap *uint256.Int // Needed to validate replacement price bump
+	execGas    uint64       // Needed to estimate the block space demand of the pool
 
 	basefeeJumps float64 // Absolute number of 1559 fee adjustments needed to reach the tx's fee cap
 	blobfeeJumps float64 // Absolute number of 4844 fee adjustments needed to reach the tx's blob fee cap
@@ -118,6 +123,7 @@ func newBlobTxMeta(id uint64, size uint32, tx *types.Transaction) *blobTxMeta {
 		execTipCap: uint256.MustFromBig(tx.GasTipCap()),
 		execFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
 		blobFeeCap: uint256.MustFromBig(tx.BlobGasFeeCap()),
+		execGas:    tx.Gas(),
 	}
 	meta.basefeeJumps = dynamicFeeJumps(meta.execFeeCap)
 	meta.blobfeeJumps = dynamicFeeJumps(meta.blobFeeCap)
@@ -294,6 +300,7 @@ type BlobPool struct {
 	store  billy.Database // Persistent data store for the tx metadata and blobs
 	stored uint64         // Useful data size of all transactions on disk
 	limbo  *limbo         // Persistent data store for the non-finalized blobs
+	arch   *archive       // Persistent data store for the included blobs (nil if disabled)
 
 	signer types.Signer // Transaction signer to use for sender recovery
 	chain  BlockChain   // Chain object to access the state through
@@ -310,6 +317,10 @@ type BlobPool struct {
 	eventFeed  event.Feed              // Event feed to send out new tx events on pool inclusion
 	eventScope event.SubscriptionScope // Event scope to track and mass unsubscribe on termination
 
+	dropFeed  event.Feed          // Event feed to send out dropped tx events on pool eviction
+	drops     []*txpool.DroppedTx // Transactions dropped since the last announcement
+	dropsLock sync.Mutex          // Lock protecting the dropped transaction list
+
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
 }
 
@@ -344,6 +355,7 @@ func (p *BlobPool) Init(gasTip *big.Int, head *types.Header, reserve txpool.Addr
 	var (
 		queuedir string
 		limbodir string
+		archdir  string
 	)
 	if p.config.Datadir != "" {
 		queuedir = filepath.Join(p.config.Datadir, pendingTransactionStore)
@@ -354,6 +366,12 @@ func (p *BlobPool) Init(gasTip *big.Int, head *types.Header, reserve txpool.Addr
 		if err := os.MkdirAll(limbodir, 0700); err != nil {
 			return err
 		}
+		if p.config.ArchiveRetention > 0 {
+			archdir = filepath.Join(p.config.Datadir, archivedTransactionStore)
+			if err := os.MkdirAll(archdir, 0700); err != nil {
+				return err
+			}
+		}
 	}
 	state, err := p.chain.StateAt(head.Root)
 	if err != nil {
@@ -404,6 +422,14 @@ func (p *BlobPool) Init(gasTip *big.Int, head *types.Header, reserve txpool.Addr
 		p.Close()
 		return err
 	}
+	// If enabled, attach the blob archive to retain blobs beyond finality
+	if p.config.ArchiveRetention > 0 {
+		p.arch, err = newArchive(archdir, p.config.ArchiveRetention)
+		if err != nil {
+			p.Close()
+			return err
+		}
+	}
 	// Set the configured gas tip, triggering a filtering of anything just loaded
 	basefeeGauge.Update(int64(basefee.Uint64()))
 	blobfeeGauge.Update(int64(blobfee.Uint64()))
@@ -427,6 +453,11 @@ func (p *BlobPool) Close() error {
 	if err := p.limbo.Close(); err != nil {
 		errs = append(errs, err)
 	}
+	if p.arch != nil {
+		if err := p.arch.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if err := p.store.Close(); err != nil {
 		errs = append(errs, err)
 	}
@@ -519,6 +550,11 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			if filled && inclusions != nil {
 				p.offload(addr, txs[i].nonce, txs[i].id, inclusions)
 			}
+			if gapped {
+				p.dropTx(txs[i].hash, txpool.DropReorg, nil)
+			} else {
+				p.dropStaleTx(txs[i].hash, inclusions)
+			}
 		}
 		delete(p.index, addr)
 		delete(p.spent, addr)
@@ -558,6 +594,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			if inclusions != nil {
 				p.offload(addr, txs[0].nonce, txs[0].id, inclusions)
 			}
+			p.dropStaleTx(txs[0].hash, inclusions)
 			txs = txs[1:]
 		}
 		log.Trace("Dropping overlapped blob transactions", "from", addr, "overlapped", nonces, "ids", ids, "left", len(txs))
@@ -609,6 +646,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[j].costCap)
 			p.stored -= uint64(txs[j].size)
 			delete(p.lookup, txs[j].hash)
+			p.dropTx(txs[j].hash, txpool.DropReorg, nil)
 		}
 		txs = txs[:i]
 
@@ -645,6 +683,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.dropTx(last.hash, txpool.DropUnpayable, nil)
 		}
 		if len(txs) == 0 {
 			delete(p.index, addr)
@@ -683,6 +722,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], last.costCap)
 			p.stored -= uint64(last.size)
 			delete(p.lookup, last.hash)
+			p.dropTx(last.hash, txpool.DropOverflow, nil)
 		}
 		p.index[addr] = txs
 
@@ -714,7 +754,7 @@ func (p *BlobPool) offload(addr common.Address, nonce uint64, id uint64, inclusi
 		return
 	}
 	var tx types.Transaction
-	if err = rlp.DecodeBytes(data, tx); err != nil {
+	if err = rlp.DecodeBytes(data, &tx); err != nil {
 		log.Error("Blobs corrupted for included transaction", "from", addr, "nonce", nonce, "id", id, "err", err)
 		return
 	}
@@ -723,6 +763,11 @@ func (p *BlobPool) offload(addr common.Address, nonce uint64, id uint64, inclusi
 		log.Warn("Blob transaction swapped out by signer", "from", addr, "nonce", nonce, "id", id)
 		return
 	}
+	if p.arch != nil {
+		if err := p.arch.push(&tx, block); err != nil {
+			log.Warn("Failed to archive included blob tx", "err", err)
+		}
+	}
 	if err := p.limbo.push(&tx, block); err != nil {
 		log.Warn("Failed to offload blob tx into limbo", "err", err)
 		return
@@ -732,6 +777,8 @@ func (p *BlobPool) offload(addr common.Address, nonce uint64, id uint64, inclusi
 // Reset implements txpool.SubPool, allowing the blob pool's internal state to be
 // kept in sync with the main transacion pool's internal state.
 func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
+	defer p.flushDrops()
+
 	waitStart := time.Now()
 	p.lock.Lock()
 	resetwaitHist.Update(time.Since(waitStart).Nanoseconds())
@@ -766,6 +813,10 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 	if p.chain.Config().IsCancun(p.head.Number, p.head.Time) {
 		p.limbo.finalize(p.chain.CurrentFinalBlock())
 	}
+	// Flush out any archived blobs that are older than the retention period
+	if p.arch != nil {
+		p.arch.prune(newHead.Number.Uint64())
+	}
 	// Reset the price heap for the new set of basefee/blobfee pairs
 	var (
 		basefee = uint256.MustFromBig(eip1559.CalcBaseFee(p.chain.Config(), newHead))
@@ -901,6 +952,11 @@ func (p *BlobPool) reorg(oldHead, newHead *types.Header) (map[common.Address][]*
 		for _, tx := range types.TxDifference(included[addr], discarded[addr]) {
 			if p.Filter(tx) {
 				p.limbo.update(tx.Hash(), inclusions[tx.Hash()])
+				if p.arch != nil {
+					if err := p.arch.update(tx.Hash(), inclusions[tx.Hash()]); err != nil {
+						log.Error("Failed to update archived blobs", "tx", tx.Hash(), "err", err)
+					}
+				}
 			}
 		}
 	}
@@ -921,6 +977,7 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) {
 	tx, err := p.limbo.pull(txhash)
 	if err != nil {
 		log.Error("Blobs unavailable, dropping reorged tx", "err", err)
+		p.dropTx(txhash, txpool.DropReorg, nil)
 		return
 	}
 	// TODO: seems like an easy optimization here would be getting the serialized tx
@@ -956,9 +1013,42 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) {
 	p.stored += uint64(meta.size)
 }
 
+// Clear implements txpool.SubPool, removing all tracked transactions from the
+// pool and the persistent store. The limbo and archive are left untouched.
+func (p *BlobPool) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for addr, txs := range p.index {
+		for _, tx := range txs {
+			if err := p.store.Delete(tx.id); err != nil {
+				log.Error("Failed to delete blob transaction", "from", addr, "id", tx.id, "err", err)
+			}
+		}
+		p.reserve(addr, false)
+	}
+	p.lookup = make(map[common.Hash]uint64)
+	p.index = make(map[common.Address][]*blobTxMeta)
+	p.spent = make(map[common.Address]*uint256.Int)
+	p.stored = 0
+
+	var (
+		basefee = uint256.MustFromBig(eip1559.CalcBaseFee(p.chain.Config(), p.head))
+		blobfee = uint256.MustFromBig(big.NewInt(params.BlobTxMinBlobGasprice))
+	)
+	if p.head.ExcessBlobGas != nil {
+		blobfee = uint256.MustFromBig(eip4844.CalcBlobFee(*p.head.ExcessBlobGas))
+	}
+	p.evict = newPriceHeap(basefee, blobfee, &p.index)
+
+	p.updateStorageMetrics()
+}
+
 // SetGasTip implements txpool.SubPool, allowing the blob pool's gas requirements
 // to be kept in sync with the main transacion pool's gas requirements.
 func (p *BlobPool) SetGasTip(tip *big.Int) {
+	defer p.flushDrops()
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -979,6 +1069,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 					p.stored -= uint64(tx.size)
 					delete(p.lookup, tx.hash)
+					p.dropTx(tx.hash, txpool.DropUnderpriced, nil)
 					txs[i] = nil
 
 					// Drop everything afterwards, no gaps allowed
@@ -989,6 +1080,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 						p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
 						p.stored -= uint64(tx.size)
 						delete(p.lookup, tx.hash)
+						p.dropTx(tx.hash, txpool.DropUnderpriced, nil)
 						txs[i+1+j] = nil
 					}
 					// Clear out the dropped transactions from the index
@@ -1021,7 +1113,8 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
-func (p *BlobPool) validateTx(tx *types.Transaction) error {
+// The blob proofs are only skipped if the caller already verified them.
+func (p *BlobPool) validateTx(tx *types.Transaction, verified bool) error {
 	// Ensure the transaction adheres to basic pool filters (type, size, tip) and
 	// consensus rules
 	baseOpts := &txpool.ValidationOptions{
@@ -1029,6 +1122,8 @@ func (p *BlobPool) validateTx(tx *types.Transaction) error {
 		Accept:  1 << types.BlobTxType,
 		MaxSize: txMaxSize,
 		MinTip:  p.gasTip.ToBig(),
+
+		BlobProofsVerified: verified,
 	}
 	if err := txpool.ValidateTransaction(tx, p.head, p.signer, baseOpts); err != nil {
 		return err
@@ -1145,19 +1240,40 @@ func (p *BlobPool) Get(hash common.Hash) *types.Transaction {
 	return item
 }
 
+// ArchivedBlob returns a previously included blob along with its commitment and
+// proof if it is retained in the archive, or nil otherwise.
+func (p *BlobPool) ArchivedBlob(vhash common.Hash) *ArchivedBlob {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.arch == nil {
+		return nil
+	}
+	return p.arch.blob(vhash)
+}
+
 // Add inserts a set of blob transactions into the pool if they pass validation (both
 // consensus validity and pool restictions).
 func (p *BlobPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
-	errs := make([]error, len(txs))
+	defer p.flushDrops()
+
+	// Verify the blob proofs in a batch before taking the pool lock, allowing the
+	// expensive cryptography to run concurrently for different callers
+	errs := txpool.VerifyBlobProofs(txs)
 	for i, tx := range txs {
-		errs[i] = p.add(tx)
+		if errs[i] != nil {
+			log.Trace("Transaction blob verification failed", "hash", tx.Hash(), "err", errs[i])
+			continue
+		}
+		errs[i] = p.add(tx, true)
 	}
 	return errs
 }
 
 // Add inserts a new blob transaction into the pool if it passes validation (both
-// consensus validity and pool restictions).
-func (p *BlobPool) add(tx *types.Transaction) (err error) {
+// consensus validity and pool restictions). The verified flag signals that the
+// blob proofs were already checked by the caller.
+func (p *BlobPool) add(tx *types.Transaction, verified bool) (err error) {
 	// The blob pool blocks on adding a transaction. This is because blob txs are
 	// only even pulled form the network, so this method will act as the overload
 	// protection for fetches.
@@ -1171,7 +1287,7 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 	}(time.Now())
 
 	// Ensure the transaction is valid from all perspectives
-	if err := p.validateTx(tx); err != nil {
+	if err := p.validateTx(tx, verified); err != nil {
 		log.Trace("Transaction validation failed", "hash", tx.Hash(), "err", err)
 		return err
 	}
@@ -1232,6 +1348,8 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		delete(p.lookup, prev.hash)
 		p.lookup[meta.hash] = meta.id
 		p.stored += uint64(meta.size) - uint64(prev.size)
+
+		p.dropTx(prev.hash, txpool.DropReplaced, &meta.hash)
 	} else {
 		// Transaction extends previously scheduled ones
 		p.index[from] = append(p.index[from], meta)
@@ -1329,6 +1447,7 @@ func (p *BlobPool) drop() {
 	}
 	p.stored -= uint64(drop.size)
 	delete(p.lookup, drop.hash)
+	p.dropTx(drop.hash, txpool.DropOverflow, nil)
 
 	// Remove the transaction from the pool's evicion heap:
 	//   - If the entire account was dropped, pop off the address
@@ -1378,6 +1497,7 @@ func (p *BlobPool) Pending(enforceTips bool) map[common.Address][]*txpool.LazyTr
 				Time:      time.Now(), // TODO(karalabe): Maybe save these and use that?
 				GasFeeCap: tx.execFeeCap.ToBig(),
 				GasTipCap: tx.execTipCap.ToBig(),
+				Gas:       tx.execGas,
 			})
 		}
 		if len(lazies) > 0 {
@@ -1468,6 +1588,43 @@ func (p *BlobPool) SubscribeTransactions(ch chan<- core.NewTxsEvent) event.Subsc
 	return p.eventScope.Track(p.eventFeed.Subscribe(ch))
 }
 
+// SubscribeDropTxs registers a subscription of DropTxsEvent and starts sending
+// event to the given channel.
+func (p *BlobPool) SubscribeDropTxs(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return p.eventScope.Track(p.dropFeed.Subscribe(ch))
+}
+
+// dropTx records a transaction as dropped from the pool, to be announced once
+// the pool lock is released.
+func (p *BlobPool) dropTx(hash common.Hash, reason txpool.DropReason, replacement *common.Hash) {
+	p.dropsLock.Lock()
+	defer p.dropsLock.Unlock()
+
+	p.drops = append(p.drops, &txpool.DroppedTx{Hash: hash, Reason: reason, Replacement: replacement})
+}
+
+// dropStaleTx records a transaction with a nonce below the account's nonce as
+// dropped, unless it was included in the chain.
+func (p *BlobPool) dropStaleTx(hash common.Hash, inclusions map[common.Hash]uint64) {
+	if _, ok := inclusions[hash]; ok {
+		return
+	}
+	p.dropTx(hash, txpool.DropNonceTooLow, nil)
+}
+
+// flushDrops announces all the transactions dropped since the last call. This
+// method must not be called with the pool lock held.
+func (p *BlobPool) flushDrops() {
+	p.dropsLock.Lock()
+	drops := p.drops
+	p.drops = nil
+	p.dropsLock.Unlock()
+
+	if len(drops) > 0 {
+		p.dropFeed.Send(txpool.DropTxsEvent{Txs: drops})
+	}
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *BlobPool) Nonce(addr common.Address) uint64 {
@@ -217,6 +217,18 @@ func makeUnsignedTx(nonce uint64, gasTipCap uint64, gasFeeCap uint64, blobFeeCap
 	}
 }
 
+// makeInvalidProofTx is a utility method to construct a blob transaction whose
+// KZG proof does not match the blob, without signing it.
+func makeInvalidProofTx(nonce uint64, gasTipCap uint64, gasFeeCap uint64, blobFeeCap uint64) *types.BlobTx {
+	var blob kzg4844.Blob
+	blob[31] = 1
+	proof, _ := kzg4844.BlobToCommitment(blob)
+
+	tx := makeUnsignedTx(nonce, gasTipCap, gasFeeCap, blobFeeCap)
+	tx.Sidecar.Proofs = []kzg4844.Proof{kzg4844.Proof(proof)}
+	return tx
+}
+
 // verifyPoolInternals iterates over all the transactions in the pool and checks
 // that sort orders, calculated fields, cumulated fields are correct.
 func verifyPoolInternals(t *testing.T, pool *BlobPool) {
@@ -1189,6 +1201,24 @@ func TestAdd(t *testing.T) {
 				},
 			},
 		},
+		// Transactions with blob proofs not matching their blobs should be rejected
+		{
+			seeds: map[string]seed{
+				"alice": {balance: 1000000},
+			},
+			adds: []addtx{
+				{ // New account, no previous txs: reject nonce 0 with invalid proof
+					from: "alice",
+					tx:   makeInvalidProofTx(0, 1, 1, 1),
+					err:  txpool.ErrInvalidBlobProof,
+				},
+				{ // New account, no previous txs: accept nonce 0 with valid proof
+					from: "alice",
+					tx:   makeUnsignedTx(0, 1, 1, 1),
+					err:  nil,
+				},
+			},
+		},
 	}
 	for i, tt := range tests {
 		// Create a temporary folder for the persistent backend
@@ -1239,7 +1269,7 @@ func TestAdd(t *testing.T) {
 		// Add each transaction one by one, verifying the pool internals in between
 		for j, add := range tt.adds {
 			signed, _ := types.SignNewTx(keys[add.from], types.LatestSigner(testChainConfig), add.tx)
-			if err := pool.add(signed); !errors.Is(err, add.err) {
+			if err := pool.Add([]*types.Transaction{signed}, false, false)[0]; !errors.Is(err, add.err) {
 				t.Errorf("test %d, tx %d: adding transaction error mismatch: have %v, want %v", i, j, err, add.err)
 			}
 			verifyPoolInternals(t, pool)
@@ -1249,3 +1279,77 @@ func TestAdd(t *testing.T) {
 		pool.Close()
 	}
 }
+
+// Tests that transactions added without a prior batch verification have their
+// blob proofs checked by the pool itself.
+func TestAddUnverifiedProofs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	statedb.AddBalance(addr, big.NewInt(1000000000))
+	statedb.Commit(0, true)
+
+	chain := &testBlockChain{
+		config:  testChainConfig,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: t.TempDir()}, chain)
+	if err := pool.Init(big.NewInt(1), chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	invalid := types.MustSignNewTx(key, types.LatestSigner(testChainConfig), makeInvalidProofTx(0, 1, 1, 1))
+	if err := pool.add(invalid, false); !errors.Is(err, txpool.ErrInvalidBlobProof) {
+		t.Fatalf("unverified invalid proof error mismatch: have %v, want %v", err, txpool.ErrInvalidBlobProof)
+	}
+	if err := pool.add(makeTx(0, 1, 1, 1, key), false); err != nil {
+		t.Fatalf("failed to add transaction with valid proof: %v", err)
+	}
+	verifyPoolInternals(t, pool)
+}
+
+// Tests that the blobs of included transactions are moved from the pool into the
+// limbo and the archive.
+func TestOffload(t *testing.T) {
+	storage := t.TempDir()
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	statedb.AddBalance(addr, big.NewInt(1000000000))
+	statedb.Commit(0, true)
+
+	chain := &testBlockChain{
+		config:  testChainConfig,
+		basefee: uint256.NewInt(1050),
+		blobfee: uint256.NewInt(105),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: storage, ArchiveRetention: 10}, chain)
+	if err := pool.Init(big.NewInt(1), chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	tx := makeTx(0, 1, 1, 1, key)
+	if err := pool.add(tx, false); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	pool.offload(addr, 0, pool.lookup[tx.Hash()], map[common.Hash]uint64{tx.Hash(): 5})
+
+	if blob := pool.ArchivedBlob(emptyBlobVHash); blob == nil || blob.TxHash != tx.Hash() || blob.Block != 5 {
+		t.Fatalf("included blob not archived: %+v", blob)
+	}
+	limboed, err := pool.limbo.pull(tx.Hash())
+	if err != nil {
+		t.Fatalf("included transaction not offloaded into limbo: %v", err)
+	}
+	if limboed.Hash() != tx.Hash() {
+		t.Fatalf("limboed transaction mismatch: have %x, want %x", limboed.Hash(), tx.Hash())
+	}
+}
@@ -25,6 +25,8 @@ type Config struct {
 	Datadir   string // Data directory containing the currently executable blobs
 	Datacap   uint64 // Soft-cap of database storage (hard cap is larger due to overhead)
 	PriceBump uint64 // Minimum price bump percentage to replace an already existing nonce
+
+	ArchiveRetention uint64 // Number of blocks to archive included blobs for (0 = disabled)
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
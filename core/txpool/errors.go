@@ -54,4 +54,13 @@ var (
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// transaction. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
+
+	// ErrPolicyRejected is returned if a transaction is refused by the operator
+	// configured admission policy of the pool.
+	ErrPolicyRejected = errors.New("rejected by admission policy")
+
+	// ErrInvalidBlobProof is returned if the KZG proof of a blob does not match
+	// its data and commitment. Contrary to most other errors, this one can only
+	// happen if the originator of the transaction is faulty or malicious.
+	ErrInvalidBlobProof = errors.New("invalid blob proof")
 )
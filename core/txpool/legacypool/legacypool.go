@@ -129,6 +129,9 @@ type Config struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	RemoteJournal      string // Snapshot of remote transactions to survive node restarts (empty = disabled)
+	RemoteJournalSlots uint64 // Maximum number of transaction slots to persist in the remote snapshot
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -145,6 +148,8 @@ var DefaultConfig = Config{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	RemoteJournalSlots: 4096,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -164,6 +169,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.RemoteJournal != "" && conf.RemoteJournalSlots < 1 {
+		log.Warn("Sanitizing invalid txpool remote journal slots", "provided", conf.RemoteJournalSlots, "updated", DefaultConfig.RemoteJournalSlots)
+		conf.RemoteJournalSlots = DefaultConfig.RemoteJournalSlots
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultConfig.PriceLimit)
 		conf.PriceLimit = DefaultConfig.PriceLimit
@@ -208,6 +217,7 @@ type LegacyPool struct {
 	chain       BlockChain
 	gasTip      atomic.Pointer[big.Int]
 	txFeed      event.Feed
+	dropFeed    event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -218,6 +228,7 @@ type LegacyPool struct {
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *journal    // Journal of local transaction to back up to disk
+	remotes *snapshot   // Snapshot of remote transactions to back up to disk
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -235,6 +246,10 @@ type LegacyPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	drops     []*txpool.DroppedTx      // Transactions dropped since the last announcement
+	dropsLock sync.Mutex               // Lock protecting the dropped transaction list
+	included  map[common.Hash]struct{} // Transactions included by the chain during the current reset
 }
 
 type txpoolResetRequest struct {
@@ -274,6 +289,9 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
+	if config.RemoteJournal != "" {
+		pool.remotes = newTxSnapshot(config.RemoteJournal)
+	}
 	return pool
 }
 
@@ -319,6 +337,19 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header, reserve txpool
 	return nil
 }
 
+// LoadRemotes reinjects the transactions of the remote snapshot, if enabled, via
+// the given add function. It is not done during Init, so that the owner of the
+// pool can first install its admission checks and route the reloaded transactions
+// through them, just like newly arriving ones.
+func (pool *LegacyPool) LoadRemotes(add func([]*types.Transaction) []error) {
+	if pool.remotes == nil {
+		return
+	}
+	if err := pool.remotes.load(add); err != nil {
+		log.Warn("Failed to load remote transaction snapshot", "err", err)
+	}
+}
+
 // loop is the transaction pool's main event loop, waiting for and reacting to
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
@@ -370,11 +401,13 @@ func (pool *LegacyPool) loop() {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true, true)
+						pool.dropTx(tx.Hash(), txpool.DropExpired, nil)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
 			pool.mu.Unlock()
+			pool.flushDrops()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -385,6 +418,7 @@ func (pool *LegacyPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			pool.saveRemotes()
 		}
 	}
 }
@@ -401,10 +435,47 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	pool.saveRemotes()
+
 	log.Info("Transaction pool stopped")
 	return nil
 }
 
+// Clear implements txpool.SubPool, removing all tracked transactions from the
+// pool and rotating the journal.
+func (pool *LegacyPool) Clear() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	// Release the reservations of all tracked accounts so that other subpools
+	// may pick them up afterwards
+	for addr := range pool.pending {
+		if _, ok := pool.queue[addr]; !ok {
+			pool.reserve(addr, false)
+		}
+	}
+	for addr := range pool.queue {
+		pool.reserve(addr, false)
+	}
+	pool.all = newLookup()
+	pool.priced = newPricedList(pool.all)
+	pool.pending = make(map[common.Address]*list)
+	pool.queue = make(map[common.Address]*list)
+	pool.beats = make(map[common.Address]time.Time)
+	pool.pendingNonces = newNoncer(pool.currentState)
+
+	pendingGauge.Update(0)
+	queuedGauge.Update(0)
+	localGauge.Update(0)
+	slotsGauge.Update(0)
+
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate transaction journal", "err", err)
+		}
+	}
+}
+
 // Reset implements txpool.SubPool, allowing the legacy pool's internal state to be
 // kept in sync with the main transacion pool's internal state.
 func (pool *LegacyPool) Reset(oldHead, newHead *types.Header) {
@@ -418,9 +489,48 @@ func (pool *LegacyPool) SubscribeTransactions(ch chan<- core.NewTxsEvent) event.
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDropTxs registers a subscription of DropTxsEvent and starts sending
+// event to the given channel.
+func (pool *LegacyPool) SubscribeDropTxs(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
+// dropTx records a transaction as dropped from the pool, to be announced once
+// the pool lock is released.
+func (pool *LegacyPool) dropTx(hash common.Hash, reason txpool.DropReason, replacement *common.Hash) {
+	pool.dropsLock.Lock()
+	defer pool.dropsLock.Unlock()
+
+	pool.drops = append(pool.drops, &txpool.DroppedTx{Hash: hash, Reason: reason, Replacement: replacement})
+}
+
+// dropStaleTx records a transaction with a nonce below the account's nonce as
+// dropped, unless it was included in the chain.
+func (pool *LegacyPool) dropStaleTx(hash common.Hash) {
+	if _, ok := pool.included[hash]; ok {
+		return
+	}
+	pool.dropTx(hash, txpool.DropNonceTooLow, nil)
+}
+
+// flushDrops announces all the transactions dropped since the last call. This
+// method must not be called with the pool lock held.
+func (pool *LegacyPool) flushDrops() {
+	pool.dropsLock.Lock()
+	drops := pool.drops
+	pool.drops = nil
+	pool.dropsLock.Unlock()
+
+	if len(drops) > 0 {
+		pool.dropFeed.Send(txpool.DropTxsEvent{Txs: drops})
+	}
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *LegacyPool) SetGasTip(tip *big.Int) {
+	defer pool.flushDrops()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -433,6 +543,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash(), false, true)
+			pool.dropTx(tx.Hash(), txpool.DropUnderpriced, nil)
 		}
 		pool.priced.Removed(len(drop))
 	}
@@ -539,6 +650,7 @@ func (pool *LegacyPool) Pending(enforceTips bool) map[common.Address][]*txpool.L
 					Time:      txs[i].Time(),
 					GasFeeCap: txs[i].GasFeeCap(),
 					GasTipCap: txs[i].GasTipCap(),
+					Gas:       txs[i].Gas(),
 				}
 			}
 			pending[addr] = lazies
@@ -571,6 +683,54 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves the remote transactions to persist across restarts, capped at
+// the configured number of slots. Executable transactions are preferred over
+// queued ones and within each group, accounts paying higher tips come first. The
+// transactions of an account are kept nonce-contiguous, so any beyond the first
+// one not fitting are skipped.
+func (pool *LegacyPool) remote() types.Transactions {
+	var (
+		txs   types.Transactions
+		slots uint64
+	)
+	for _, set := range []map[common.Address]*list{pool.pending, pool.queue} {
+		accounts := make([]types.Transactions, 0, len(set))
+		for addr, list := range set {
+			if pool.locals.contains(addr) || list.Empty() {
+				continue
+			}
+			accounts = append(accounts, list.Flatten())
+		}
+		sort.Slice(accounts, func(i, j int) bool {
+			return accounts[i][0].GasTipCapCmp(accounts[j][0]) > 0
+		})
+		for _, list := range accounts {
+			for _, tx := range list {
+				if slots+uint64(numSlots(tx)) > pool.config.RemoteJournalSlots {
+					break
+				}
+				txs = append(txs, tx)
+				slots += uint64(numSlots(tx))
+			}
+		}
+	}
+	return txs
+}
+
+// saveRemotes regenerates the remote transaction snapshot if enabled.
+func (pool *LegacyPool) saveRemotes() {
+	if pool.remotes == nil {
+		return
+	}
+	pool.mu.RLock()
+	txs := pool.remote()
+	pool.mu.RUnlock()
+
+	if err := pool.remotes.save(txs); err != nil {
+		log.Warn("Failed to save remote transaction snapshot", "err", err)
+	}
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
@@ -739,6 +899,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 
 			sender, _ := types.Sender(pool.signer, tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
+			pool.dropTx(tx.Hash(), txpool.DropUnderpriced, nil)
 
 			pool.changesSinceReorg += dropped
 		}
@@ -757,6 +918,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.dropTx(old.Hash(), txpool.DropReplaced, &hash)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
@@ -832,6 +994,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.dropTx(old.Hash(), txpool.DropReplaced, &hash)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -881,6 +1044,9 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+
+		better := list.txs.Get(tx.Nonce()).Hash()
+		pool.dropTx(hash, txpool.DropReplaced, &better)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -888,6 +1054,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.dropTx(old.Hash(), txpool.DropReplaced, &hash)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -979,6 +1146,7 @@ func (pool *LegacyPool) Add(txs []*types.Transaction, local, sync bool) []error
 	pool.mu.Lock()
 	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
 	pool.mu.Unlock()
+	pool.flushDrops()
 
 	var nilSlot = 0
 	for _, err := range newErrs {
@@ -1288,7 +1456,9 @@ func (pool *LegacyPool) runReorg(done chan struct{}, reset *txpoolResetRequest,
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+	pool.included = nil        // Inclusions only matter for the reset's own demotions
 	pool.mu.Unlock()
+	pool.flushDrops()
 
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
@@ -1384,8 +1554,15 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 					}
 				}
 				reinject = lost
+				pool.trackInclusions(included)
 			}
 		}
+	} else if oldHead != nil && newHead != nil {
+		// Chain progressed by a single block, track the included transactions so
+		// they are not reported as dropped
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			pool.trackInclusions(block.Transactions())
+		}
 	}
 	// Initialize the internal state to the current head
 	if newHead == nil {
@@ -1406,6 +1583,15 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	pool.addTxsLocked(reinject, false)
 }
 
+// trackInclusions marks a batch of transactions as included in the chain, so that
+// their removal during the current reset is not announced as a drop.
+func (pool *LegacyPool) trackInclusions(txs types.Transactions) {
+	pool.included = make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		pool.included[tx.Hash()] = struct{}{}
+	}
+}
+
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
 // invalidated transactions (low nonce, low balance) are deleted.
@@ -1425,6 +1611,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.dropStaleTx(hash)
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
@@ -1432,6 +1619,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.dropTx(hash, txpool.DropUnpayable, nil)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1454,6 +1642,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
+				pool.dropTx(hash, txpool.DropOverflow, nil)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1519,6 +1708,7 @@ func (pool *LegacyPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.dropTx(hash, txpool.DropOverflow, nil)
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1546,6 +1736,7 @@ func (pool *LegacyPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.dropTx(hash, txpool.DropOverflow, nil)
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1593,6 +1784,7 @@ func (pool *LegacyPool) truncateQueue() {
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
 				pool.removeTx(tx.Hash(), true, true)
+				pool.dropTx(tx.Hash(), txpool.DropOverflow, nil)
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
@@ -1602,6 +1794,7 @@ func (pool *LegacyPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true, true)
+			pool.dropTx(txs[i].Hash(), txpool.DropOverflow, nil)
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1626,6 +1819,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range olds {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.dropStaleTx(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
@@ -1634,6 +1828,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
+			pool.dropTx(hash, txpool.DropUnpayable, nil)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -2189,6 +2190,57 @@ func TestReplacement(t *testing.T) {
 	}
 }
 
+// Tests that transactions removed from the pool are announced together with
+// the reason of their removal.
+func TestDropEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	drops := make(chan txpool.DropTxsEvent, 32)
+	sub := pool.SubscribeDropTxs(drops)
+	defer sub.Unsubscribe()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Replace a pending transaction and ensure the replacement is reported
+	original := pricedTransaction(0, 100000, big.NewInt(1), key)
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), key)
+
+	if err := pool.addRemoteSync(original); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(replacement); err != nil {
+		t.Fatalf("failed to add replacement transaction: %v", err)
+	}
+	select {
+	case ev := <-drops:
+		if len(ev.Txs) != 1 {
+			t.Fatalf("dropped transaction count mismatch: have %d, want 1", len(ev.Txs))
+		}
+		if drop := ev.Txs[0]; drop.Hash != original.Hash() || drop.Reason != txpool.DropReplaced || drop.Replacement == nil || *drop.Replacement != replacement.Hash() {
+			t.Fatalf("replacement drop mismatch: have %+v", drop)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("replacement drop not fired")
+	}
+	// Raise the minimum tip and ensure the remote transaction is reported underpriced
+	pool.SetGasTip(big.NewInt(3))
+
+	select {
+	case ev := <-drops:
+		if len(ev.Txs) != 1 {
+			t.Fatalf("dropped transaction count mismatch: have %d, want 1", len(ev.Txs))
+		}
+		if drop := ev.Txs[0]; drop.Hash != replacement.Hash() || drop.Reason != txpool.DropUnderpriced {
+			t.Fatalf("underpriced drop mismatch: have %+v", drop)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("underpriced drop not fired")
+	}
+}
+
 // Tests that the pool rejects replacement dynamic fee transactions that don't
 // meet the minimum price bump required.
 func TestReplacementDynamicFee(t *testing.T) {
@@ -2415,6 +2467,70 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that remote transactions are snapshotted on shutdown and reinjected on
+// startup, revalidating them against the current head and respecting the cap.
+func TestRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.RemoteJournal = filepath.Join(t.TempDir(), "remotes.rlp")
+	config.RemoteJournalSlots = 4
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+
+	// Create a cheap and an expensive account to check the snapshot ordering
+	cheap, _ := crypto.GenerateKey()
+	pricy, _ := crypto.GenerateKey()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(cheap.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(pricy.PublicKey), big.NewInt(1000000000))
+
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.addRemoteSync(pricedTransaction(i, 100000, big.NewInt(1), cheap)); err != nil {
+			t.Fatalf("failed to add cheap transaction %d: %v", i, err)
+		}
+		if err := pool.addRemoteSync(pricedTransaction(i, 100000, big.NewInt(2), pricy)); err != nil {
+			t.Fatalf("failed to add pricy transaction %d: %v", i, err)
+		}
+	}
+	if err := pool.addRemoteSync(pricedTransaction(5, 100000, big.NewInt(2), pricy)); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	// Terminate the old pool, include the first pricy transaction in the new head
+	// and ensure the capped, still valid remote transactions survive
+	pool.Close()
+	statedb.SetNonce(crypto.PubkeyToAddress(pricy.PublicKey), 1)
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	pool = New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	pool.LoadRemotes(pool.addRemotes)
+	<-pool.requestReset(nil, nil)
+
+	pending, queued := pool.Stats()
+	if pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if list := pool.pending[crypto.PubkeyToAddress(pricy.PublicKey)]; list == nil || list.Len() != 2 {
+		t.Fatalf("pricy account not fully restored")
+	}
+	if list := pool.pending[crypto.PubkeyToAddress(cheap.PublicKey)]; list == nil || list.Len() != 1 {
+		t.Fatalf("cheap account cap mismatch")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
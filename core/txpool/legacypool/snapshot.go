@@ -0,0 +1,128 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// snapshot is a periodically regenerated dump of the remote transactions in the
+// pool. Contrary to the local journal, it is never appended to, rather always
+// rewritten in full, so it can be replaced atomically on disk.
+type snapshot struct {
+	path string // Filesystem path to store the transactions at
+}
+
+// newTxSnapshot creates a new remote transaction snapshot at the given path.
+func newTxSnapshot(path string) *snapshot {
+	return &snapshot{
+		path: path,
+	}
+}
+
+// load parses a transaction snapshot from disk, injecting its contents into the
+// specified pool. The transactions are revalidated by the pool against the
+// current chain head, so stale ones are simply dropped.
+func (snap *snapshot) load(add func([]*types.Transaction) []error) error {
+	input, err := os.Open(snap.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		stream  = rlp.NewStream(bufio.NewReader(input), 0)
+		batch   types.Transactions
+		failure error
+
+		total, dropped int
+	)
+	loadBatch := func(txs types.Transactions) {
+		for _, err := range add(txs) {
+			if err != nil {
+				log.Debug("Failed to add snapshotted transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		tx := new(types.Transaction)
+		if err = stream.Decode(tx); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		total++
+
+		if batch = append(batch, tx); batch.Len() > 1024 {
+			loadBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	log.Info("Loaded remote transaction snapshot", "transactions", total, "dropped", dropped)
+
+	return failure
+}
+
+// save writes the given transactions into a temporary file, flushes it to disk
+// and swaps it in place of the previous snapshot. A crash at any point leaves
+// either the old or the new snapshot intact, never a partial one.
+func (snap *snapshot) save(txs types.Transactions) error {
+	output, err := os.OpenFile(snap.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	buffer := bufio.NewWriter(output)
+	for _, tx := range txs {
+		if err = rlp.Encode(buffer, tx); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = buffer.Flush()
+	}
+	if err == nil {
+		err = output.Sync()
+	}
+	if cerr := output.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(snap.path + ".new")
+		return err
+	}
+	if err = os.Rename(snap.path+".new", snap.path); err != nil {
+		return err
+	}
+	log.Debug("Regenerated remote transaction snapshot", "transactions", len(txs))
+	return nil
+}
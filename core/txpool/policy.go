@@ -0,0 +1,211 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// policyQuotaWindow is the time window over which per-peer quotas are counted.
+// Once the window elapses, all peer counters are reset.
+const policyQuotaWindow = time.Minute
+
+// MethodFilter is a rule matching contract calls by their 4 byte method selector,
+// optionally restricted to a single contract.
+type MethodFilter struct {
+	Contract *common.Address `json:"contract,omitempty"` // Contract to match, nil for any
+	Selector hexutil.Bytes   `json:"selector"`           // Method selector to match
+}
+
+// PolicyConfig is the on-disk (JSON) format of a transaction admission policy.
+// Empty allow lists mean everything is allowed, whereas deny lists are always
+// checked before allow lists.
+type PolicyConfig struct {
+	DenySenders     []common.Address `json:"denySenders,omitempty"`
+	AllowSenders    []common.Address `json:"allowSenders,omitempty"`
+	DenyRecipients  []common.Address `json:"denyRecipients,omitempty"`
+	AllowRecipients []common.Address `json:"allowRecipients,omitempty"`
+	DenyMethods     []MethodFilter   `json:"denyMethods,omitempty"`
+
+	// MinTips is a set of per-sender minimum gas tips required for remote
+	// transactions, overriding the pool wide minimum if higher.
+	MinTips map[common.Address]*math.HexOrDecimal256 `json:"minTips,omitempty"`
+
+	// PeerQuota is the maximum number of remote transactions admitted from a
+	// single peer per minute. Zero means unlimited.
+	PeerQuota uint64 `json:"peerQuota,omitempty"`
+}
+
+// Policy is an admission policy run by the transaction pool before inserting a
+// transaction into any subpool. It allows operators to filter transactions on
+// top of the protocol rules without having to fork the pool implementations.
+//
+// A policy is immutable apart from its quota tracking, so reloading is done by
+// creating a new policy and swapping it into the pool.
+type Policy struct {
+	denySenders     map[common.Address]struct{}
+	allowSenders    map[common.Address]struct{}
+	denyRecipients  map[common.Address]struct{}
+	allowRecipients map[common.Address]struct{}
+	denyMethods     map[[4]byte][]*common.Address
+	minTips         map[common.Address]*big.Int
+	peerQuota       uint64
+
+	quotas     map[string]uint64 // Number of transactions admitted per peer in the current window
+	quotaStart time.Time         // Start time of the current quota window
+	quotaLock  sync.Mutex        // Lock protecting the quota counters
+}
+
+// NewPolicy creates an admission policy from its configuration.
+func NewPolicy(config *PolicyConfig) (*Policy, error) {
+	policy := &Policy{
+		denySenders:     make(map[common.Address]struct{}),
+		allowSenders:    make(map[common.Address]struct{}),
+		denyRecipients:  make(map[common.Address]struct{}),
+		allowRecipients: make(map[common.Address]struct{}),
+		denyMethods:     make(map[[4]byte][]*common.Address),
+		minTips:         make(map[common.Address]*big.Int),
+		peerQuota:       config.PeerQuota,
+		quotas:          make(map[string]uint64),
+	}
+	for _, addr := range config.DenySenders {
+		policy.denySenders[addr] = struct{}{}
+	}
+	for _, addr := range config.AllowSenders {
+		policy.allowSenders[addr] = struct{}{}
+	}
+	for _, addr := range config.DenyRecipients {
+		policy.denyRecipients[addr] = struct{}{}
+	}
+	for _, addr := range config.AllowRecipients {
+		policy.allowRecipients[addr] = struct{}{}
+	}
+	for i, filter := range config.DenyMethods {
+		if len(filter.Selector) != 4 {
+			return nil, fmt.Errorf("method filter %d: invalid selector length %d", i, len(filter.Selector))
+		}
+		var selector [4]byte
+		copy(selector[:], filter.Selector)
+		policy.denyMethods[selector] = append(policy.denyMethods[selector], filter.Contract)
+	}
+	for addr, tip := range config.MinTips {
+		if tip == nil || (*big.Int)(tip).Sign() < 0 {
+			return nil, fmt.Errorf("invalid minimum tip for %v", addr)
+		}
+		policy.minTips[addr] = new(big.Int).Set((*big.Int)(tip))
+	}
+	return policy, nil
+}
+
+// LoadPolicy reads a JSON admission policy from the given file.
+func LoadPolicy(path string) (*Policy, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(PolicyConfig)
+	if err := json.Unmarshal(blob, config); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	return NewPolicy(config)
+}
+
+// needsSender returns whether the policy has any rules that depend on the sender
+// of a transaction, allowing the pool to skip signature recovery otherwise.
+func (p *Policy) needsSender() bool {
+	return len(p.denySenders) > 0 || len(p.allowSenders) > 0 || len(p.minTips) > 0
+}
+
+// Admit checks whether a transaction from the given sender is permitted into
+// the pool. Remote transactions are additionally checked against per-sender
+// minimum tips and are charged against the originating peer's quota, if known.
+// The charge must be refunded if the transaction is rejected by the subpools.
+func (p *Policy) Admit(tx *types.Transaction, from common.Address, local bool, peer string) error {
+	if _, ok := p.denySenders[from]; ok {
+		return fmt.Errorf("%w: sender %v denied", ErrPolicyRejected, from)
+	}
+	if len(p.allowSenders) > 0 {
+		if _, ok := p.allowSenders[from]; !ok {
+			return fmt.Errorf("%w: sender %v not allowed", ErrPolicyRejected, from)
+		}
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.denyRecipients[*to]; ok {
+			return fmt.Errorf("%w: recipient %v denied", ErrPolicyRejected, *to)
+		}
+		if len(p.allowRecipients) > 0 {
+			if _, ok := p.allowRecipients[*to]; !ok {
+				return fmt.Errorf("%w: recipient %v not allowed", ErrPolicyRejected, *to)
+			}
+		}
+		if data := tx.Data(); len(data) >= 4 {
+			var selector [4]byte
+			copy(selector[:], data)
+
+			for _, contract := range p.denyMethods[selector] {
+				if contract == nil || *contract == *to {
+					return fmt.Errorf("%w: method %#x on %v denied", ErrPolicyRejected, selector, *to)
+				}
+			}
+		}
+	}
+	if local {
+		return nil
+	}
+	if tip := p.minTips[from]; tip != nil && tx.GasTipCapIntCmp(tip) < 0 {
+		return fmt.Errorf("%w: sender %v tip needed %v, tip permitted %v", ErrUnderpriced, from, tip, tx.GasTipCap())
+	}
+	if p.peerQuota > 0 && peer != "" {
+		p.quotaLock.Lock()
+		defer p.quotaLock.Unlock()
+
+		if now := time.Now(); now.Sub(p.quotaStart) > policyQuotaWindow {
+			p.quotas = make(map[string]uint64)
+			p.quotaStart = now
+		}
+		if p.quotas[peer] >= p.peerQuota {
+			return fmt.Errorf("%w: peer %s exceeded quota of %d txs", ErrPolicyRejected, peer, p.peerQuota)
+		}
+		p.quotas[peer]++
+	}
+	return nil
+}
+
+// refund returns the quota charged by Admit for a remote transaction that was
+// rejected by the subpools afterwards, so only pooled transactions count.
+func (p *Policy) refund(local bool, peer string) {
+	if local || p.peerQuota == 0 || peer == "" {
+		return
+	}
+	p.quotaLock.Lock()
+	defer p.quotaLock.Unlock()
+
+	// The window might have been reset since the charge, don't underflow
+	if p.quotas[peer] > 0 {
+		p.quotas[peer]--
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func makePolicyTx(to *common.Address, tip int64, data []byte) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		To:        to,
+		Gas:       100000,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(1000),
+		Data:      data,
+	})
+}
+
+// Tests that the various admission policy rules accept and reject transactions
+// as configured.
+func TestPolicyAdmit(t *testing.T) {
+	var (
+		alice    = common.HexToAddress("0xa11ce")
+		bob      = common.HexToAddress("0xb0b")
+		token    = common.HexToAddress("0x70ce")
+		selector = []byte{0xa9, 0x05, 0x9c, 0xbb}
+	)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	blob := `{
+		"denySenders": ["0x000000000000000000000000000000000000b0b0"],
+		"denyRecipients": ["0x0000000000000000000000000000000000000b0b"],
+		"denyMethods": [{"contract": "0x00000000000000000000000000000000000070ce", "selector": "0xa9059cbb"}],
+		"minTips": {"0x00000000000000000000000000000000000a11ce": "100"},
+		"peerQuota": 2
+	}`
+	if err := os.WriteFile(path, []byte(blob), 0600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+	tests := []struct {
+		tx    *types.Transaction
+		from  common.Address
+		local bool
+		peer  string
+		err   error
+	}{
+		{makePolicyTx(&alice, 100, nil), bob, false, "", nil},                                         // plain transfer
+		{makePolicyTx(&alice, 100, nil), common.HexToAddress("0xb0b0"), false, "", ErrPolicyRejected}, // denied sender
+		{makePolicyTx(&bob, 100, nil), alice, true, "", ErrPolicyRejected},                            // denied recipient
+		{makePolicyTx(&token, 100, selector), alice, true, "", ErrPolicyRejected},                     // denied method
+		{makePolicyTx(&bob, 100, selector), alice, false, "", ErrPolicyRejected},                      // denied recipient before method
+		{makePolicyTx(&alice, 100, selector), bob, false, "", nil},                                    // method on other contract
+		{makePolicyTx(&token, 99, nil), alice, false, "", ErrUnderpriced},                             // sender tip too low
+		{makePolicyTx(&token, 99, nil), alice, true, "", nil},                                         // local exempt from tips
+		{makePolicyTx(&token, 100, nil), bob, false, "peer", nil},                                     // quota 1
+		{makePolicyTx(&token, 100, nil), bob, false, "peer", nil},                                     // quota 2
+		{makePolicyTx(&token, 100, nil), bob, false, "peer", ErrPolicyRejected},                       // quota exceeded
+		{makePolicyTx(&token, 100, nil), bob, false, "other", nil},                                    // separate quota
+	}
+	for i, tt := range tests {
+		if err := policy.Admit(tt.tx, tt.from, tt.local, tt.peer); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// rejectingSubPool is a subpool accepting all transaction types, but rejecting
+// every transaction added to it.
+type rejectingSubPool struct {
+	SubPool
+}
+
+func (p *rejectingSubPool) Filter(tx *types.Transaction) bool { return true }
+
+func (p *rejectingSubPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range errs {
+		errs[i] = ErrUnderpriced
+	}
+	return errs
+}
+
+// Tests that transactions rejected by the subpools don't consume the quota of
+// the peer they originate from.
+func TestPolicyQuotaRefund(t *testing.T) {
+	policy, err := NewPolicy(&PolicyConfig{PeerQuota: 1})
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+	pool := &TxPool{subpools: []SubPool{new(rejectingSubPool)}}
+	pool.SetPolicy(policy)
+
+	to := common.HexToAddress("0xb0b")
+	for i := 0; i < 3; i++ {
+		errs := pool.AddFromPeer("peer", []*types.Transaction{makePolicyTx(&to, 1, nil)})
+		if !errors.Is(errs[0], ErrUnderpriced) {
+			t.Fatalf("add %d: error mismatch: have %v, want %v", i, errs[0], ErrUnderpriced)
+		}
+	}
+	if err := policy.Admit(makePolicyTx(&to, 1, nil), common.Address{}, false, "peer"); err != nil {
+		t.Fatalf("quota consumed by rejected transactions: %v", err)
+	}
+}
+
+// Tests that allow lists reject anything not explicitly permitted.
+func TestPolicyAllowLists(t *testing.T) {
+	var (
+		alice = common.HexToAddress("0xa11ce")
+		bob   = common.HexToAddress("0xb0b")
+	)
+	policy, err := NewPolicy(&PolicyConfig{
+		AllowSenders:    []common.Address{alice},
+		AllowRecipients: []common.Address{bob},
+	})
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+	if err := policy.Admit(makePolicyTx(&bob, 1, nil), alice, false, ""); err != nil {
+		t.Errorf("allowed transaction rejected: %v", err)
+	}
+	if err := policy.Admit(makePolicyTx(&bob, 1, nil), bob, false, ""); !errors.Is(err, ErrPolicyRejected) {
+		t.Errorf("unlisted sender error mismatch: have %v, want %v", err, ErrPolicyRejected)
+	}
+	if err := policy.Admit(makePolicyTx(&alice, 1, nil), alice, false, ""); !errors.Is(err, ErrPolicyRejected) {
+		t.Errorf("unlisted recipient error mismatch: have %v, want %v", err, ErrPolicyRejected)
+	}
+	if err := policy.Admit(makePolicyTx(nil, 1, nil), alice, false, ""); err != nil {
+		t.Errorf("contract creation rejected: %v", err)
+	}
+}
+
+// Tests that malformed policies are refused.
+func TestPolicyInvalid(t *testing.T) {
+	if _, err := NewPolicy(&PolicyConfig{DenyMethods: []MethodFilter{{Selector: []byte{1, 2, 3}}}}); err == nil {
+		t.Errorf("short selector accepted")
+	}
+}
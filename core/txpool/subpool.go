@@ -37,6 +37,7 @@ type LazyTransaction struct {
 	Time      time.Time // Time when the transaction was first seen
 	GasFeeCap *big.Int  // Maximum fee per gas the transaction may consume
 	GasTipCap *big.Int  // Maximum miner tip per gas the transaction can pay
+	Gas       uint64    // Amount of gas required by the transaction
 }
 
 // Resolve retrieves the full transaction belonging to a lazy handle if it is still
@@ -48,6 +49,59 @@ func (ltx *LazyTransaction) Resolve() *types.Transaction {
 	return ltx.Tx
 }
 
+// DropReason is the reason why a transaction was removed from the pool without
+// being included in the chain.
+type DropReason uint8
+
+const (
+	DropReplaced    DropReason = iota // Replaced by a transaction with the same nonce and higher fees
+	DropUnderpriced                   // Below the pool's minimum tip or outbid in a full pool
+	DropOverflow                      // Evicted due to exceeding the account or pool capacity limits
+	DropNonceTooLow                   // Account nonce moved past it without the transaction being included
+	DropUnpayable                     // Sender cannot pay for it anymore or it exceeds the block gas limit
+	DropExpired                       // Non-executable for longer than the pool's lifetime allowance
+	DropReorg                         // Invalidated by a chain reorganisation
+)
+
+// String implements the stringer interface.
+func (r DropReason) String() string {
+	switch r {
+	case DropReplaced:
+		return "replaced"
+	case DropUnderpriced:
+		return "underpriced"
+	case DropOverflow:
+		return "overflow"
+	case DropNonceTooLow:
+		return "nonceTooLow"
+	case DropUnpayable:
+		return "unpayable"
+	case DropExpired:
+		return "expired"
+	case DropReorg:
+		return "reorg"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r DropReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// DroppedTx contains the details of a transaction dropped from the pool.
+type DroppedTx struct {
+	Hash        common.Hash  `json:"hash"`                  // Hash of the dropped transaction
+	Reason      DropReason   `json:"reason"`                // Reason for the transaction being dropped
+	Replacement *common.Hash `json:"replacement,omitempty"` // Hash of the replacing transaction if it was replaced
+}
+
+// DropTxsEvent is posted when a batch of transactions is dropped from the pool.
+type DropTxsEvent struct {
+	Txs []*DroppedTx
+}
+
 // AddressReserver is passed by the main transaction pool to subpools, so they
 // may request (and relinquish) exclusive access to certain addresses.
 type AddressReserver func(addr common.Address, reserve bool) error
@@ -83,6 +137,10 @@ type SubPool interface {
 	// transaction, and drops all transactions below this threshold.
 	SetGasTip(tip *big.Int)
 
+	// Clear removes all tracked transactions from the pool. It is meant to be
+	// used by simulators rewinding the chain, not in live operation.
+	Clear()
+
 	// Has returns an indicator whether subpool has a transaction cached with the
 	// given hash.
 	Has(hash common.Hash) bool
@@ -102,6 +160,9 @@ type SubPool interface {
 	// SubscribeTransactions subscribes to new transaction events.
 	SubscribeTransactions(ch chan<- core.NewTxsEvent) event.Subscription
 
+	// SubscribeDropTxs subscribes to dropped transaction events.
+	SubscribeDropTxs(ch chan<- DropTxsEvent) event.Subscription
+
 	// Nonce returns the next nonce of an account, with all transactions executable
 	// by the pool already applied on top.
 	Nonce(addr common.Address) uint64
@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
@@ -40,6 +42,10 @@ const (
 	TxStatusIncluded
 )
 
+// dropCacheSize is the number of recently dropped transactions to remember, so
+// users can query why their transaction disappeared from the pool.
+const dropCacheSize = 16384
+
 var (
 	// reservationsGaugeName is the prefix of a per-subpool address reservation
 	// metric.
@@ -70,8 +76,15 @@ type TxPool struct {
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
+	policy atomic.Pointer[Policy] // Optional admission policy to run before subpool insertion
+
+	drops *lru.Cache[common.Hash, *DroppedTx] // Recently dropped transactions with their reasons
+
 	subs event.SubscriptionScope // Subscription scope to unscubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
+	term chan struct{}           // Termination channel to detect a closed pool
+
+	sync chan chan error // Testing / simulator channel to block until internal reset is done
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -85,7 +98,10 @@ func New(gasTip *big.Int, chain BlockChain, subpools []SubPool) (*TxPool, error)
 	pool := &TxPool{
 		subpools:     subpools,
 		reservations: make(map[common.Address]SubPool),
+		drops:        lru.NewCache[common.Hash, *DroppedTx](dropCacheSize),
 		quit:         make(chan chan error),
+		term:         make(chan struct{}),
+		sync:         make(chan chan error),
 	}
 	for i, subpool := range subpools {
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
@@ -172,6 +188,9 @@ func (p *TxPool) Close() error {
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
 func (p *TxPool) loop(head *types.Header, chain BlockChain) {
+	// Close the termination marker when the pool stops
+	defer close(p.term)
+
 	// Subscribe to chain head events to trigger subpool resets
 	var (
 		newHeadCh  = make(chan core.ChainHeadEvent)
@@ -179,6 +198,17 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 	)
 	defer newHeadSub.Unsubscribe()
 
+	// Subscribe to dropped transactions to be able to report on them later
+	var (
+		dropCh   = make(chan DropTxsEvent, 16)
+		dropSubs = make([]event.Subscription, len(p.subpools))
+	)
+	for i, subpool := range p.subpools {
+		dropSubs[i] = subpool.SubscribeDropTxs(dropCh)
+	}
+	dropSub := event.JoinSubscriptions(dropSubs...)
+	defer dropSub.Unsubscribe()
+
 	// Track the previous and current head to feed to an idle reset
 	var (
 		oldHead = head
@@ -188,13 +218,16 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 	var (
 		resetBusy = make(chan struct{}, 1) // Allow 1 reset to run concurrently
 		resetDone = make(chan *types.Header)
+
+		resetForced  bool         // Whether a forced reset was requested, only used by simulators
+		resetWaiters []chan error // Channels waiting on a forced reset, only used by simulators
 	)
 	var errc chan error
 	for errc == nil {
 		// Something interesting might have happened, run a reset if there is
 		// one needed but none is running. The resetter will run on its own
 		// goroutine to allow chain head events to be consumed contiguously.
-		if newHead != oldHead {
+		if newHead != oldHead || resetForced {
 			// Try to inject a busy marker and start a reset if successful
 			select {
 			case resetBusy <- struct{}{}:
@@ -206,8 +239,12 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 					resetDone <- newHead
 				}(oldHead, newHead)
 
+				// Any forced reset request is fulfilled by the one just started
+				resetForced = false
+
 			default:
-				// Reset already running, wait until it finishes
+				// Reset already running, wait until it finishes. A forced reset
+				// request is retained and will run after the current one.
 			}
 		}
 		// Wait for the next chain head event or a previous reset finish
@@ -221,14 +258,62 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 			oldHead = head
 			<-resetBusy
 
+			// Notify anyone waiting for a forced reset, unless it's still pending
+			if !resetForced {
+				for _, syncc := range resetWaiters {
+					syncc <- nil
+				}
+				resetWaiters = nil
+			}
+
+		case event := <-dropCh:
+			// Transactions dropped from a subpool, remember them for status queries
+			for _, drop := range event.Txs {
+				p.drops.Add(drop.Hash, drop)
+			}
+
+		case syncc := <-p.sync:
+			// The pool is running inside a simulator which is about to create a
+			// new block or rewind the chain. Request a forced reset to finish any
+			// running one and to promote all recently added transactions, making
+			// the pool's content deterministic.
+			resetForced = true
+			resetWaiters = append(resetWaiters, syncc)
+
 		case errc = <-p.quit:
 			// Termination requested, break out on the next loop round
 		}
 	}
-	// Notify the closer of termination (no error possible for now)
+	// Notify any waiters and the closer of termination
+	for _, syncc := range resetWaiters {
+		syncc <- errors.New("pool already terminated")
+	}
 	errc <- nil
 }
 
+// Sync runs an explicit reset of all the subpools and blocks until it finishes,
+// ensuring that all chain head events delivered so far have been processed and
+// all added transactions have been promoted. It is meant to be used by simulators
+// rewinding or quickly advancing the chain, where the pool must be stable before
+// going on, not in live operation.
+func (p *TxPool) Sync() error {
+	syncc := make(chan error, 1)
+	select {
+	case p.sync <- syncc:
+		return <-syncc
+	case <-p.term:
+		return errors.New("pool already terminated")
+	}
+}
+
+// Clear removes all tracked transactions from all the subpools. It is meant to
+// be used by simulators rewinding the chain, not in live operation.
+func (p *TxPool) Clear() {
+	for _, subpool := range p.subpools {
+		subpool.Clear()
+	}
+}
+
 // SetGasTip updates the minimum gas tip required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (p *TxPool) SetGasTip(tip *big.Int) {
@@ -258,10 +343,48 @@ func (p *TxPool) Get(hash common.Hash) *types.Transaction {
 	return nil
 }
 
+// SetPolicy installs a new admission policy into the pool, replacing any old one.
+// Passing nil removes the policy altogether. Already pooled transactions are not
+// revalidated against the new policy.
+func (p *TxPool) SetPolicy(policy *Policy) {
+	p.policy.Store(policy)
+}
+
 // Add enqueues a batch of transactions into the pool if they are valid. Due
 // to the large transaction churn, add may postpone fully integrating the tx
 // to a later point to batch multiple ones together.
 func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	return p.add(txs, local, sync, "")
+}
+
+// AddFromPeer enqueues a batch of remote transactions into the pool, similarly
+// to Add. The originating peer is used to enforce the per-peer quotas of the
+// admission policy, if any.
+func (p *TxPool) AddFromPeer(peer string, txs []*types.Transaction) []error {
+	return p.add(txs, false, false, peer)
+}
+
+// add runs the transactions through the admission policy and enqueues the ones
+// passing it into the appropriate subpools.
+func (p *TxPool) add(txs []*types.Transaction, local bool, sync bool, peer string) []error {
+	// Run the admission policy first, rejecting anything the operator does not
+	// want to have in the pool before involving any subpools.
+	errs := make([]error, len(txs))
+	policy := p.policy.Load()
+	if policy != nil {
+		for i, tx := range txs {
+			var from common.Address
+			if policy.needsSender() {
+				sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+				if err != nil {
+					errs[i] = ErrInvalidSender
+					continue
+				}
+				from = sender
+			}
+			errs[i] = policy.Admit(tx, from, local, peer)
+		}
+	}
 	// Split the input transactions between the subpools. It shouldn't really
 	// happen that we receive merged batches, but better graceful than strange
 	// errors.
@@ -275,6 +398,10 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 		// Mark this transaction belonging to no-subpool
 		splits[i] = -1
 
+		// Skip any transaction already rejected by the admission policy
+		if errs[i] != nil {
+			continue
+		}
 		// Try to find a subpool that accepts the transaction
 		for j, subpool := range p.subpools {
 			if subpool.Filter(tx) {
@@ -290,16 +417,24 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 	for i := 0; i < len(p.subpools); i++ {
 		errsets[i] = p.subpools[i].Add(txsets[i], local, sync)
 	}
-	errs := make([]error, len(txs))
 	for i, split := range splits {
-		// If the transaction was rejected by all subpools, mark it unsupported
+		// If the transaction was rejected by the policy, keep the original error
+		if errs[i] != nil {
+			continue
+		}
+		// If the transaction was rejected by all subpools, mark it unsupported,
+		// otherwise find which subpool handled it and pull in the corresponding
+		// error
 		if split == -1 {
 			errs[i] = core.ErrTxTypeNotSupported
-			continue
+		} else {
+			errs[i] = errsets[split][0]
+			errsets[split] = errsets[split][1:]
+		}
+		// Transactions not making it into the pool don't count towards the quota
+		if errs[i] != nil && policy != nil {
+			policy.refund(local, peer)
 		}
-		// Find which subpool handled it and pull in the corresponding error
-		errs[i] = errsets[split][0]
-		errsets[split] = errsets[split][1:]
 	}
 	return errs
 }
@@ -326,6 +461,23 @@ func (p *TxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscrip
 	return p.subs.Track(event.JoinSubscriptions(subs...))
 }
 
+// SubscribeDropTxsEvent registers a subscription of DropTxsEvent and starts
+// sending events to the given channel.
+func (p *TxPool) SubscribeDropTxsEvent(ch chan<- DropTxsEvent) event.Subscription {
+	subs := make([]event.Subscription, len(p.subpools))
+	for i, subpool := range p.subpools {
+		subs[i] = subpool.SubscribeDropTxs(ch)
+	}
+	return p.subs.Track(event.JoinSubscriptions(subs...))
+}
+
+// Dropped returns the details of a recently dropped transaction, or nil if the
+// transaction is not known to have been dropped.
+func (p *TxPool) Dropped(hash common.Hash) *DroppedTx {
+	drop, _ := p.drops.Get(hash)
+	return drop
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (p *TxPool) Nonce(addr common.Address) uint64 {
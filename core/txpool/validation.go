@@ -38,6 +38,8 @@ type ValidationOptions struct {
 	Accept  uint8    // Bitmap of transaction types that should be accepted for the calling pool
 	MaxSize uint64   // Maximum size of a transaction that the caller can meaningfully handle
 	MinTip  *big.Int // Minimum gas tip needed to allow a transaction into the caller pool
+
+	BlobProofsVerified bool // Whether the blob KZG proofs were already verified in a batch
 }
 
 // ValidateTransaction is a helper method to check whether a transaction is valid
@@ -123,14 +125,14 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 		if len(hashes) > params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob {
 			return fmt.Errorf("too many blobs in transaction: have %d, permitted %d", len(hashes), params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob)
 		}
-		if err := validateBlobSidecar(hashes, sidecar); err != nil {
+		if err := validateBlobSidecar(hashes, sidecar, !opts.BlobProofsVerified); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) error {
+func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar, proofs bool) error {
 	if len(sidecar.Blobs) != len(hashes) {
 		return fmt.Errorf("invalid number of %d blobs compared to %d blob hashes", len(sidecar.Blobs), len(hashes))
 	}
@@ -157,15 +159,57 @@ func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) err
 		}
 	}
 	// Blob commitments match with the hashes in the transaction, verify the
-	// blobs themselves via KZG
+	// blobs themselves via KZG unless already done in a batch
+	if !proofs {
+		return nil
+	}
 	for i := range sidecar.Blobs {
 		if err := kzg4844.VerifyBlobProof(sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
-			return fmt.Errorf("invalid blob %d: %v", i, err)
+			return fmt.Errorf("%w: blob %d: %v", ErrInvalidBlobProof, i, err)
 		}
 	}
 	return nil
 }
 
+// VerifyBlobProofs verifies the KZG proofs of all the blob sidecars attached to
+// the given transactions in a single batch. If the batch fails, the sidecars are
+// re-verified one transaction at a time to pinpoint the offending ones.
+//
+// Transactions without sidecars or with malformed ones are skipped, leaving them
+// for ValidateTransaction to reject, which remains responsible for checking that
+// the commitments match the blob hashes.
+func VerifyBlobProofs(txs []*types.Transaction) []error {
+	var (
+		errs    = make([]error, len(txs))
+		batched []int
+
+		blobs       []kzg4844.Blob
+		commitments []kzg4844.Commitment
+		proofs      []kzg4844.Proof
+	)
+	for i, tx := range txs {
+		sidecar := tx.BlobTxSidecar()
+		if sidecar == nil || len(sidecar.Commitments) != len(sidecar.Blobs) || len(sidecar.Proofs) != len(sidecar.Blobs) {
+			continue
+		}
+		blobs = append(blobs, sidecar.Blobs...)
+		commitments = append(commitments, sidecar.Commitments...)
+		proofs = append(proofs, sidecar.Proofs...)
+
+		batched = append(batched, i)
+	}
+	if len(blobs) == 0 || kzg4844.VerifyBlobProofBatch(blobs, commitments, proofs) == nil {
+		return errs
+	}
+	for _, i := range batched {
+		sidecar := txs[i].BlobTxSidecar()
+		if err := kzg4844.VerifyBlobProofBatch(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			errs[i] = fmt.Errorf("%w: %v", ErrInvalidBlobProof, err)
+		}
+	}
+	return errs
+}
+
 // ValidationOptionsWithState define certain differences between stateful transaction
 // validation across the different pools without having to duplicate those checks.
 type ValidationOptionsWithState struct {
@@ -20,6 +20,7 @@ package kzg4844
 import (
 	"embed"
 	"errors"
+	"fmt"
 	"sync/atomic"
 )
 
@@ -108,3 +109,16 @@ func VerifyBlobProof(blob Blob, commitment Commitment, proof Proof) error {
 	}
 	return gokzgVerifyBlobProof(blob, commitment, proof)
 }
+
+// VerifyBlobProofBatch verifies that a batch of blobs correspond to the provided
+// commitments. It is considerably cheaper than verifying the blobs one by one,
+// but on failure it does not report which blob was invalid.
+func VerifyBlobProofBatch(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	if len(commitments) != len(blobs) || len(proofs) != len(blobs) {
+		return fmt.Errorf("batch length mismatch: %d blobs, %d commitments, %d proofs", len(blobs), len(commitments), len(proofs))
+	}
+	if useCKZG.Load() {
+		return ckzgVerifyBlobProofBatch(blobs, commitments, proofs)
+	}
+	return gokzgVerifyBlobProofBatch(blobs, commitments, proofs)
+}
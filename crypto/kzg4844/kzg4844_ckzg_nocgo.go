@@ -60,3 +60,9 @@ func ckzgComputeBlobProof(blob Blob, commitment Commitment) (Proof, error) {
 func ckzgVerifyBlobProof(blob Blob, commitment Commitment, proof Proof) error {
 	panic("unsupported platform")
 }
+
+// ckzgVerifyBlobProofBatch verifies that a batch of blobs correspond to the
+// provided commitments.
+func ckzgVerifyBlobProofBatch(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	panic("unsupported platform")
+}
@@ -94,6 +94,37 @@ func testKZGWithBlob(t *testing.T, ckzg bool) {
 	}
 }
 
+func TestCKZGWithBlobBatch(t *testing.T)  { testKZGWithBlobBatch(t, true) }
+func TestGoKZGWithBlobBatch(t *testing.T) { testKZGWithBlobBatch(t, false) }
+func testKZGWithBlobBatch(t *testing.T, ckzg bool) {
+	if ckzg && !ckzgAvailable {
+		t.Skip("CKZG unavailable in this test build")
+	}
+	defer func(old bool) { useCKZG.Store(old) }(useCKZG.Load())
+	useCKZG.Store(ckzg)
+
+	var (
+		blobs       = make([]Blob, 3)
+		commitments = make([]Commitment, 3)
+		proofs      = make([]Proof, 3)
+	)
+	for i := range blobs {
+		blobs[i] = randBlob()
+		commitments[i], _ = BlobToCommitment(blobs[i])
+		proofs[i], _ = ComputeBlobProof(blobs[i], commitments[i])
+	}
+	if err := VerifyBlobProofBatch(blobs, commitments, proofs); err != nil {
+		t.Fatalf("failed to verify KZG proof batch: %v", err)
+	}
+	proofs[1] = proofs[0]
+	if err := VerifyBlobProofBatch(blobs, commitments, proofs); err == nil {
+		t.Fatalf("invalid KZG proof batch verified")
+	}
+	if err := VerifyBlobProofBatch(blobs, commitments[:2], proofs); err == nil {
+		t.Fatalf("mismatching KZG proof batch verified")
+	}
+}
+
 func BenchmarkCKZGBlobToCommitment(b *testing.B)  { benchmarkBlobToCommitment(b, true) }
 func BenchmarkGoKZGBlobToCommitment(b *testing.B) { benchmarkBlobToCommitment(b, false) }
 func benchmarkBlobToCommitment(b *testing.B, ckzg bool) {
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -141,3 +142,18 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// ReloadTxPolicy re-reads the configured transaction admission policy file and
+// swaps it into the transaction pool.
+func (api *AdminAPI) ReloadTxPolicy() (bool, error) {
+	path := api.eth.config.TxPoolPolicy
+	if path == "" {
+		return false, errors.New("no transaction admission policy configured")
+	}
+	policy, err := txpool.LoadPolicy(path)
+	if err != nil {
+		return false, err
+	}
+	api.eth.txPool.SetPolicy(policy)
+	return true, nil
+}
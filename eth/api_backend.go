@@ -28,9 +28,11 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
@@ -281,6 +283,10 @@ func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Sub
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeFinalityEvent(ch chan<- core.FinalityEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeFinalityEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
 }
@@ -297,6 +303,12 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
+func (b *EthAPIBackend) TrackTx(signedTx *types.Transaction, from common.Address) {
+	if b.eth.bumper != nil {
+		b.eth.bumper.Track(signedTx, from)
+	}
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(false)
 	var txs types.Transactions
@@ -319,10 +331,23 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 	return tx, blockHash, blockNumber, index, nil
 }
 
+func (b *EthAPIBackend) GetTransactionBySenderAndNonce(ctx context.Context, sender common.Address, nonce uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransactionBySenderAndNonce(b.eth.ChainDb(), sender, nonce)
+	return tx, blockHash, blockNumber, index, nil
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.Nonce(addr), nil
 }
 
+func (b *EthAPIBackend) GetPoolDroppedTransaction(hash common.Hash) *txpool.DroppedTx {
+	return b.eth.txPool.Dropped(hash)
+}
+
+func (b *EthAPIBackend) GetArchivedBlob(vhash common.Hash) *blobpool.ArchivedBlob {
+	return b.eth.blobPool.ArchivedBlob(vhash)
+}
+
 func (b *EthAPIBackend) Stats() (runnable int, blocked int) {
 	return b.eth.txPool.Stats()
 }
@@ -343,6 +368,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeDropTxsEvent(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return b.eth.txPool.SubscribeDropTxsEvent(ch)
+}
+
 func (b *EthAPIBackend) SyncProgress() ethereum.SyncProgress {
 	return b.eth.Downloader().Progress()
 }
@@ -351,7 +380,23 @@ func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	return b.gpo.SuggestTipCap(ctx)
 }
 
-func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (firstBlock *big.Int, reward [][]*big.Int, baseFee []*big.Int, gasUsedRatio []float64, err error) {
+func (b *EthAPIBackend) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	return b.gpo.BlobBaseFee(ctx)
+}
+
+func (b *EthAPIBackend) SuggestFeeTiers(ctx context.Context) (*gasprice.FeeTiers, error) {
+	return b.gpo.SuggestFeeTiers(ctx, func() []gasprice.PendingTx {
+		var pending []gasprice.PendingTx
+		for _, batch := range b.eth.txPool.Pending(false) {
+			for _, lazy := range batch {
+				pending = append(pending, gasprice.PendingTx{GasFeeCap: lazy.GasFeeCap, GasTipCap: lazy.GasTipCap, Gas: lazy.Gas})
+			}
+		}
+		return pending
+	})
+}
+
+func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (firstBlock *big.Int, reward [][]*big.Int, baseFee []*big.Int, gasUsedRatio []float64, blobBaseFee []*big.Int, blobGasUsedRatio []float64, err error) {
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
@@ -398,6 +443,26 @@ func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+func (b *EthAPIBackend) LogIndex() *logindex.Index {
+	return b.eth.logIndex
+}
+
+func (b *EthAPIBackend) AddressIndexStatus() (uint64, uint64, bool) {
+	if b.eth.addressIndexer == nil {
+		return 0, 0, false
+	}
+	tail := rawdb.ReadAddressIndexTail(b.eth.chainDb)
+	if tail == nil {
+		return 0, 0, true
+	}
+	sections, _, _ := b.eth.addressIndexer.Sections()
+	end := sections * params.AddressIndexBlocks
+	if end < *tail {
+		end = *tail
+	}
+	return *tail, end, true
+}
+
 func (b *EthAPIBackend) Engine() consensus.Engine {
 	return b.eth.engine
 }
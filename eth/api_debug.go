@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -45,8 +46,9 @@ func NewDebugAPI(eth *Ethereum) *DebugAPI {
 	return &DebugAPI{eth: eth}
 }
 
-// DumpBlock retrieves the entire state of the database at a given block.
-func (api *DebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
+// DumpBlock retrieves the entire state of the database at a given block. The dump
+// is streamed to the client while iterating the state.
+func (api *DebugAPI) DumpBlock(blockNr rpc.BlockNumber) (rpc.StreamedResult, error) {
 	opts := &state.DumpConfig{
 		OnlyWithAddresses: true,
 		Max:               AccountRangeMaxResults, // Sanity limit over RPC
@@ -57,9 +59,9 @@ func (api *DebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
 		// the miner and operate on those
 		_, stateDb := api.eth.miner.Pending()
 		if stateDb == nil {
-			return state.Dump{}, errors.New("pending state is not available")
+			return nil, errors.New("pending state is not available")
 		}
-		return stateDb.RawDump(opts), nil
+		return streamDump(stateDb, opts), nil
 	}
 	var header *types.Header
 	switch blockNr {
@@ -72,18 +74,25 @@ func (api *DebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
 	default:
 		block := api.eth.blockchain.GetBlockByNumber(uint64(blockNr))
 		if block == nil {
-			return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
+			return nil, fmt.Errorf("block #%d not found", blockNr)
 		}
 		header = block.Header()
 	}
 	if header == nil {
-		return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
+		return nil, fmt.Errorf("block #%d not found", blockNr)
 	}
 	stateDb, err := api.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
-		return state.Dump{}, err
+		return nil, err
 	}
-	return stateDb.RawDump(opts), nil
+	return streamDump(stateDb, opts), nil
+}
+
+// streamDump returns the dump of the state as a result streamed to the client.
+func streamDump(statedb *state.StateDB, opts *state.DumpConfig) rpc.StreamedResult {
+	return rpc.StreamFunc(func(w io.Writer) error {
+		return statedb.StreamDump(opts, w)
+	})
 }
 
 // Preimage is a debug API function that returns the preimage for a sha3 hash, if known.
@@ -32,13 +32,16 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/forkstate"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/bumper"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
@@ -68,7 +71,9 @@ type Ethereum struct {
 	config *ethconfig.Config
 
 	// Handlers
-	txPool *txpool.TxPool
+	txPool   *txpool.TxPool
+	blobPool *blobpool.BlobPool // Blob subpool, kept around to serve archived blobs
+	bumper   *bumper.Bumper     // Fee bumper for stuck local transactions, nil if disabled
 
 	blockchain         *core.BlockChain
 	handler            *handler
@@ -86,6 +91,8 @@ type Ethereum struct {
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
+	logIndex          *logindex.Index    // Address and topic index of the logs in the canonical chain, nil if disabled
+	addressIndexer    *core.ChainIndexer // Address activity indexer, nil if disabled
 
 	APIBackend *EthAPIBackend
 
@@ -101,6 +108,8 @@ type Ethereum struct {
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
+
+	fork *forkstate.Fork // Remote chain whose state is forked in developer mode, nil if none
 }
 
 // New creates a new Ethereum object (including the
@@ -117,6 +126,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
 	}
+	if config.DevFork != "" {
+		if config.StateScheme != rawdb.HashScheme {
+			return nil, fmt.Errorf("state forking requires the %s state scheme", rawdb.HashScheme)
+		}
+		if config.SnapshotCache > 0 {
+			log.Warn("Disabling snapshots for forked state")
+			config.SnapshotCache = 0
+		}
+	}
 	if config.NoPruning && config.TrieDirtyCache > 0 {
 		if config.SnapshotCache > 0 {
 			config.TrieCleanCache += config.TrieDirtyCache * 3 / 5
@@ -139,8 +157,25 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			log.Error("Failed to recover state", "error", err)
 		}
 	}
+	// Fork the state of a remote chain if requested, committing the genesis on
+	// top of it into an empty database.
+	var (
+		genesis = config.Genesis
+		fork    *forkstate.Fork
+	)
+	if config.DevFork != "" {
+		client, err := rpc.Dial(config.DevFork)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to fork endpoint: %v", err)
+		}
+		if fork, err = forkstate.New(chainDb, client, config.DevForkBlock, genesis); err != nil {
+			client.Close()
+			return nil, err
+		}
+		genesis = nil // fallback to db content
+	}
 	// Transfer mining-related config to the ethash config.
-	chainConfig, err := core.LoadChainConfig(chainDb, config.Genesis)
+	chainConfig, err := core.LoadChainConfig(chainDb, genesis)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +198,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		p2pServer:         stack.Server(),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		fork:              fork,
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -195,8 +231,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
 			StateScheme:         config.StateScheme,
+			ContractIndex:       config.ContractIndex,
+			SenderIndex:         config.SenderIndex,
+			InternalCalls:       config.AddressIndex,
 		}
 	)
+	if fork != nil {
+		cacheConfig.StateWrapper = fork.Wrap
+	}
 	// Override the chain config with provided settings.
 	var overrides core.ChainOverrides
 	if config.OverrideCancun != nil {
@@ -205,26 +247,58 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideVerkle != nil {
 		overrides.OverrideVerkle = config.OverrideVerkle
 	}
-	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TransactionHistory)
+	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TransactionHistory)
 	if err != nil {
 		return nil, err
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.TokenIndex && !config.LogIndex {
+		log.Warn("Enabling the log index required by the token transfer index")
+		config.LogIndex = true
+	}
+	if config.LogIndex {
+		eth.logIndex = logindex.New(chainDb, eth.blockchain, config.LogHistory, config.TokenIndex)
+	}
+	if config.AddressIndex {
+		eth.addressIndexer = core.NewAddressIndexer(eth.blockchain, params.AddressIndexBlocks, params.AddressIndexConfirms)
+		eth.addressIndexer.Start(eth.blockchain)
+	}
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
 	}
-	blobPool := blobpool.New(config.BlobPool, eth.blockchain)
+	eth.blobPool = blobpool.New(config.BlobPool, eth.blockchain)
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = stack.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
-	eth.txPool, err = txpool.New(new(big.Int).SetUint64(config.TxPool.PriceLimit), eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	eth.txPool, err = txpool.New(new(big.Int).SetUint64(config.TxPool.PriceLimit), eth.blockchain, []txpool.SubPool{legacyPool, eth.blobPool})
 	if err != nil {
 		return nil, err
 	}
+	if config.TxPoolPolicy != "" {
+		config.TxPoolPolicy = stack.ResolvePath(config.TxPoolPolicy)
+
+		policy, err := txpool.LoadPolicy(config.TxPoolPolicy)
+		if err != nil {
+			return nil, err
+		}
+		eth.txPool.SetPolicy(policy)
+		log.Info("Loaded transaction admission policy", "path", config.TxPoolPolicy)
+	}
+	// Reinject the snapshotted remote transactions once the admission policy is
+	// in place, so they are subject to the same checks as any new transaction
+	legacyPool.LoadRemotes(func(txs []*types.Transaction) []error {
+		return eth.txPool.Add(txs, false, false)
+	})
+	if config.TxBumper.Blocks > 0 {
+		eth.bumper = bumper.New(config.TxBumper, config.TxPool.PriceBump, eth.blockchain, eth.txPool, eth.accountManager)
+	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	if eth.handler, err = newHandler(&handlerConfig{
@@ -304,6 +378,13 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the fee bumper APIs if enabled
+	if s.bumper != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "txpool",
+			Service:   bumper.NewAPI(s.bumper),
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -478,6 +559,8 @@ func (s *Ethereum) Synced() bool                       { return s.handler.accept
 func (s *Ethereum) SetSynced()                         { s.handler.enableSyncedFeatures() }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
+func (s *Ethereum) LogIndex() *logindex.Index          { return s.logIndex }
+func (s *Ethereum) AddressIndexer() *core.ChainIndexer { return s.addressIndexer }
 func (s *Ethereum) Merger() *consensus.Merger          { return s.merger }
 func (s *Ethereum) SyncMode() downloader.SyncMode {
 	mode, _ := s.handler.chainSync.modeAndLocalHead()
@@ -529,10 +612,22 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.logIndex != nil {
+		s.logIndex.Close()
+	}
+	if s.addressIndexer != nil {
+		s.addressIndexer.Close()
+	}
+	if s.bumper != nil {
+		s.bumper.Close()
+	}
 	s.txPool.Close()
 	s.miner.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
+	if s.fork != nil {
+		s.fork.Close()
+	}
 
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
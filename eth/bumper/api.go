@@ -0,0 +1,109 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bumper
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RPCStatus is the RPC representation of a managed transaction's status.
+type RPCStatus struct {
+	From     common.Address  `json:"from"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Original common.Hash     `json:"original"`
+	Hash     common.Hash     `json:"hash"`
+	State    State           `json:"state"`
+	Bumps    hexutil.Uint64  `json:"bumps"`
+	Since    hexutil.Uint64  `json:"since"`
+	Tip      *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	FeeCap   *hexutil.Big    `json:"maxFeePerGas"`
+	Included *hexutil.Uint64 `json:"includedIn,omitempty"`
+}
+
+// newRPCStatus converts a managed transaction status into its RPC form.
+func newRPCStatus(status *Status) *RPCStatus {
+	result := &RPCStatus{
+		From:     status.From,
+		Nonce:    hexutil.Uint64(status.Nonce),
+		Original: status.Original,
+		Hash:     status.Hash,
+		State:    status.State,
+		Bumps:    hexutil.Uint64(status.Bumps),
+		Since:    hexutil.Uint64(status.Since),
+		Tip:      (*hexutil.Big)(status.Tip),
+		FeeCap:   (*hexutil.Big)(status.FeeCap),
+	}
+	if status.State == StateIncluded {
+		included := hexutil.Uint64(status.Included)
+		result.Included = &included
+	}
+	return result
+}
+
+// API exposes the fee bumper's managed transactions over RPC.
+type API struct {
+	bumper *Bumper
+}
+
+// NewAPI creates a new RPC service for the fee bumper.
+func NewAPI(bumper *Bumper) *API {
+	return &API{bumper: bumper}
+}
+
+// Managed returns the status of all the transactions currently managed by the
+// fee bumper.
+func (api *API) Managed() []*RPCStatus {
+	statuses := api.bumper.Status()
+
+	results := make([]*RPCStatus, 0, len(statuses))
+	for _, status := range statuses {
+		results = append(results, newRPCStatus(status))
+	}
+	return results
+}
+
+// ManagedTransactions creates a subscription that is triggered each time a
+// managed transaction changes state, e.g. when it is bumped or included.
+func (api *API) ManagedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		statuses := make(chan *Status, 128)
+		sub := api.bumper.SubscribeStatus(statuses)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case status := <-statuses:
+				notifier.Notify(rpcSub.ID, newRPCStatus(status))
+			case <-rpcSub.Err():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
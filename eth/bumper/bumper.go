@@ -0,0 +1,451 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bumper implements a manager for locally signed transactions, which
+// re-signs and resubmits stuck ones with increasing fees.
+package bumper
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DefaultCeiling is the default maximum fee cap a bumped transaction may pay.
+var DefaultCeiling = big.NewInt(500 * params.GWei)
+
+// Config are the configuration parameters of the fee bumper.
+type Config struct {
+	Blocks  uint64   // Number of blocks a transaction may linger before being bumped (0 = disabled)
+	Percent uint64   // Fee increase percentage applied on each bump (at least the pool's price bump)
+	Ceiling *big.Int `toml:",omitempty"` // Maximum gas price or fee cap a bumped transaction may pay
+}
+
+// DefaultConfig contains the default configurations for the fee bumper.
+var DefaultConfig = Config{
+	Blocks:  0,
+	Percent: 10,
+	Ceiling: DefaultCeiling,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable. The bump percentage is raised to the pool's price
+// bump if below, otherwise the pool would reject every replacement.
+func (config *Config) sanitize(priceBump uint64) Config {
+	conf := *config
+	if conf.Percent < 1 {
+		log.Warn("Sanitizing invalid fee bumper percentage", "provided", conf.Percent, "updated", DefaultConfig.Percent)
+		conf.Percent = DefaultConfig.Percent
+	}
+	if conf.Percent < priceBump {
+		log.Warn("Sanitizing fee bumper percentage below the pool's price bump", "provided", conf.Percent, "updated", priceBump)
+		conf.Percent = priceBump
+	}
+	if conf.Ceiling == nil || conf.Ceiling.Sign() <= 0 {
+		log.Warn("Sanitizing invalid fee bumper ceiling", "provided", conf.Ceiling, "updated", DefaultConfig.Ceiling)
+		conf.Ceiling = DefaultConfig.Ceiling
+	}
+	return conf
+}
+
+// State is the lifecycle stage of a managed transaction.
+type State uint8
+
+const (
+	StatePending  State = iota // Transaction submitted, waiting for inclusion
+	StateBumped                // Transaction replaced with a higher paying one
+	StateCapped                // Transaction cannot be bumped further without exceeding the ceiling
+	StateFailed                // Transaction could not be re-signed or resubmitted, retried after the bump interval
+	StateIncluded              // One of the transaction's versions was included in the chain
+	StateReplaced              // The transaction's nonce was consumed by an unmanaged transaction
+)
+
+// String implements fmt.Stringer.
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateBumped:
+		return "bumped"
+	case StateCapped:
+		return "capped"
+	case StateFailed:
+		return "failed"
+	case StateIncluded:
+		return "included"
+	case StateReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Status is a snapshot of a managed transaction, also used as the notification
+// emitted on every state transition.
+type Status struct {
+	From     common.Address
+	Nonce    uint64
+	Original common.Hash // Hash of the transaction originally submitted by the user
+	Hash     common.Hash // Hash of the latest (or included) version of the transaction
+	State    State
+	Bumps    int      // Number of times the transaction was re-signed
+	Since    uint64   // Block number at which the latest version was submitted
+	Tip      *big.Int // Gas tip cap (or gas price) of the latest (or included) version
+	FeeCap   *big.Int // Gas fee cap (or gas price) of the latest (or included) version
+	Included uint64   // Block number of inclusion if included
+}
+
+// managed is a transaction tracked by the bumper along with all its versions.
+type managed struct {
+	tx       *types.Transaction   // Latest version of the transaction
+	from     common.Address       // Sender of the transaction
+	original common.Hash          // Hash of the originally submitted version
+	versions []*types.Transaction // All the submitted versions of the transaction
+	state    State                // Current lifecycle stage
+	bumps    int                  // Number of times the transaction was re-signed
+	since    uint64               // Block number at which the latest version was submitted
+	included uint64               // Block number of inclusion if included
+}
+
+// status creates a snapshot of the managed transaction.
+func (m *managed) status() *Status {
+	return &Status{
+		From:     m.from,
+		Nonce:    m.tx.Nonce(),
+		Original: m.original,
+		Hash:     m.tx.Hash(),
+		State:    m.state,
+		Bumps:    m.bumps,
+		Since:    m.since,
+		Tip:      m.tx.GasTipCap(),
+		FeeCap:   m.tx.GasFeeCap(),
+		Included: m.included,
+	}
+}
+
+// key uniquely identifies a managed transaction across its replacements.
+type key struct {
+	from  common.Address
+	nonce uint64
+}
+
+// BlockChain defines the minimal set of methods needed to back a fee bumper
+// with a chain.
+type BlockChain interface {
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// CurrentBlock returns the current head of the chain.
+	CurrentBlock() *types.Header
+
+	// StateAt returns a state database for a given root hash.
+	StateAt(root common.Hash) (*state.StateDB, error)
+
+	// GetTransactionLookup retrieves the position of a transaction in the chain.
+	GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry
+
+	// SubscribeChainHeadEvent subscribes to new blocks being added to the chain.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// TxPool defines the minimal set of methods needed to resubmit transactions.
+type TxPool interface {
+	// Add enqueues a batch of transactions into the pool.
+	Add(txs []*types.Transaction, local bool, sync bool) []error
+}
+
+// Bumper watches locally signed transactions and, whenever one of them does not
+// get included within the configured number of blocks, re-signs it with bumped
+// fees using the original wallet and resubmits it into the pool.
+type Bumper struct {
+	config Config
+	chain  BlockChain
+	pool   TxPool
+	am     *accounts.Manager
+
+	txs  map[key]*managed
+	lock sync.RWMutex
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+
+	headSub event.Subscription
+	headCh  chan core.ChainHeadEvent
+	wg      sync.WaitGroup
+}
+
+// New creates a fee bumper watching the given chain and resubmitting bumped
+// transactions into the given pool, which replaces transactions only if their
+// fees are increased by at least priceBump percent.
+func New(config Config, priceBump uint64, chain BlockChain, pool TxPool, am *accounts.Manager) *Bumper {
+	b := &Bumper{
+		config: (&config).sanitize(priceBump),
+		chain:  chain,
+		pool:   pool,
+		am:     am,
+		txs:    make(map[key]*managed),
+		headCh: make(chan core.ChainHeadEvent, 10),
+	}
+	b.headSub = chain.SubscribeChainHeadEvent(b.headCh)
+
+	b.wg.Add(1)
+	go b.loop()
+	return b
+}
+
+// Close terminates the fee bumper.
+func (b *Bumper) Close() {
+	b.headSub.Unsubscribe()
+	b.wg.Wait()
+	b.scope.Close()
+}
+
+// Track starts managing a transaction signed by a local wallet. If a transaction
+// with the same nonce is already tracked, it is considered to have been replaced
+// by the user and is tracked as the new original.
+func (b *Bumper) Track(tx *types.Transaction, from common.Address) {
+	if tx.Type() == types.BlobTxType {
+		return
+	}
+	b.lock.Lock()
+	m := &managed{
+		tx:       tx,
+		from:     from,
+		original: tx.Hash(),
+		versions: []*types.Transaction{tx},
+		state:    StatePending,
+		since:    b.chain.CurrentBlock().Number.Uint64(),
+	}
+	b.txs[key{from, tx.Nonce()}] = m
+	status := m.status()
+	b.lock.Unlock()
+
+	b.feed.Send(status)
+}
+
+// Status returns the snapshots of all currently managed transactions.
+func (b *Bumper) Status() []*Status {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	statuses := make([]*Status, 0, len(b.txs))
+	for _, m := range b.txs {
+		statuses = append(statuses, m.status())
+	}
+	return statuses
+}
+
+// SubscribeStatus registers a subscription for managed transaction state
+// transitions.
+func (b *Bumper) SubscribeStatus(ch chan<- *Status) event.Subscription {
+	return b.scope.Track(b.feed.Subscribe(ch))
+}
+
+// loop is the bumper's main event loop, checking the managed transactions on
+// every new chain head.
+func (b *Bumper) loop() {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case ev := <-b.headCh:
+			b.update(ev.Block.Header())
+		case <-b.headSub.Err():
+			return
+		}
+	}
+}
+
+// update checks all managed transactions against a new chain head, retiring the
+// ones whose nonces were consumed and bumping the ones lingering for too long.
+func (b *Bumper) update(head *types.Header) {
+	statedb, err := b.chain.StateAt(head.Root)
+	if err != nil {
+		log.Warn("Failed to retrieve state for fee bumping", "number", head.Number, "hash", head.Hash(), "err", err)
+		return
+	}
+	var (
+		number  = head.Number.Uint64()
+		updates []*Status
+		bumps   []*bump
+	)
+	b.lock.Lock()
+	for k, m := range b.txs {
+		// If the nonce was consumed, figure out which version made it in
+		if statedb.GetNonce(m.from) > m.tx.Nonce() {
+			m.state = StateReplaced
+			for _, tx := range m.versions {
+				if lookup := b.chain.GetTransactionLookup(tx.Hash()); lookup != nil {
+					m.tx, m.state, m.included = tx, StateIncluded, lookup.BlockIndex
+					break
+				}
+			}
+			delete(b.txs, k)
+			updates = append(updates, m.status())
+			continue
+		}
+		// Transaction still pending, bump it if it lingered for too long
+		if m.state == StateCapped {
+			continue
+		}
+		if number < m.since+b.config.Blocks {
+			continue
+		}
+		inner := b.bumpFees(m.tx)
+		if inner == nil {
+			m.state = StateCapped
+			updates = append(updates, m.status())
+			continue
+		}
+		bumps = append(bumps, &bump{key: k, old: m.tx, inner: inner})
+	}
+	b.lock.Unlock()
+
+	// Sign the bumped transactions without holding the lock, as external signers
+	// may take arbitrarily long to respond
+	for _, bump := range bumps {
+		if status := b.bump(bump, number); status != nil {
+			updates = append(updates, status)
+		}
+	}
+	for _, status := range updates {
+		b.feed.Send(status)
+	}
+}
+
+// bump is a fee bumped replacement of a managed transaction, waiting to be signed.
+type bump struct {
+	key   key                // Identifier of the managed transaction
+	old   *types.Transaction // Version of the transaction being replaced
+	inner types.TxData       // Replacement transaction with the bumped fees
+}
+
+// bumpFees creates a copy of the given transaction with its fees increased, or
+// returns nil if that would exceed the configured ceiling.
+func (b *Bumper) bumpFees(old *types.Transaction) types.TxData {
+	switch old.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		price := b.increase(old.GasPrice())
+		if price.Cmp(b.config.Ceiling) > 0 {
+			return nil
+		}
+		if old.Type() == types.LegacyTxType {
+			return &types.LegacyTx{
+				Nonce:    old.Nonce(),
+				GasPrice: price,
+				Gas:      old.Gas(),
+				To:       old.To(),
+				Value:    old.Value(),
+				Data:     old.Data(),
+			}
+		}
+		return &types.AccessListTx{
+			ChainID:    old.ChainId(),
+			Nonce:      old.Nonce(),
+			GasPrice:   price,
+			Gas:        old.Gas(),
+			To:         old.To(),
+			Value:      old.Value(),
+			Data:       old.Data(),
+			AccessList: old.AccessList(),
+		}
+	case types.DynamicFeeTxType:
+		feeCap := b.increase(old.GasFeeCap())
+		if feeCap.Cmp(b.config.Ceiling) > 0 {
+			return nil
+		}
+		return &types.DynamicFeeTx{
+			ChainID:    old.ChainId(),
+			Nonce:      old.Nonce(),
+			GasTipCap:  b.increase(old.GasTipCap()),
+			GasFeeCap:  feeCap,
+			Gas:        old.Gas(),
+			To:         old.To(),
+			Value:      old.Value(),
+			Data:       old.Data(),
+			AccessList: old.AccessList(),
+		}
+	default:
+		return nil
+	}
+}
+
+// bump re-signs a fee bumped transaction and submits it into the pool, updating
+// the managed transaction's state accordingly. Failed attempts are retried once
+// the bump interval passes again. If the managed transaction was replaced by the
+// user in the meantime, the bump is discarded and nil is returned.
+func (b *Bumper) bump(bump *bump, number uint64) *Status {
+	var (
+		account = accounts.Account{Address: bump.key.from}
+		signed  *types.Transaction
+	)
+	wallet, err := b.am.Find(account)
+	if err != nil {
+		log.Warn("Failed to find wallet for fee bumping", "from", bump.key.from, "nonce", bump.key.nonce, "err", err)
+	} else if signed, err = wallet.SignTx(account, types.NewTx(bump.inner), b.chain.Config().ChainID); err != nil {
+		log.Warn("Failed to re-sign transaction for fee bumping", "from", bump.key.from, "nonce", bump.key.nonce, "err", err)
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	m := b.txs[bump.key]
+	if m == nil || m.tx != bump.old {
+		return nil
+	}
+	if err == nil {
+		if err = b.pool.Add([]*types.Transaction{signed}, true, false)[0]; err != nil {
+			log.Warn("Failed to resubmit fee bumped transaction", "from", m.from, "nonce", m.tx.Nonce(), "err", err)
+		}
+	}
+	if err != nil {
+		m.state = StateFailed
+		m.since = number
+		return m.status()
+	}
+	log.Info("Bumped stuck transaction fees", "from", m.from, "nonce", m.tx.Nonce(), "old", m.tx.Hash(), "new", signed.Hash(), "tip", signed.GasTipCap(), "feecap", signed.GasFeeCap())
+
+	m.tx = signed
+	m.versions = append(m.versions, signed)
+	m.state = StateBumped
+	m.bumps++
+	m.since = number
+	return m.status()
+}
+
+// increase bumps a fee value by the configured percentage, ensuring that even
+// tiny values are strictly increased.
+func (b *Bumper) increase(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+b.config.Percent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, common.Big1)
+	}
+	return bumped
+}
@@ -0,0 +1,224 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bumper
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChain is a mock chain with a single mutable state and a set of included
+// transactions.
+type testChain struct {
+	statedb  *state.StateDB
+	head     *types.Header
+	included map[common.Hash]uint64
+	feed     event.Feed
+}
+
+func (c *testChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (c *testChain) CurrentBlock() *types.Header { return c.head }
+
+func (c *testChain) StateAt(common.Hash) (*state.StateDB, error) { return c.statedb, nil }
+
+func (c *testChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry {
+	if number, ok := c.included[hash]; ok {
+		return &rawdb.LegacyTxLookupEntry{BlockIndex: number}
+	}
+	return nil
+}
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// testPool is a mock transaction pool collecting all the added transactions,
+// optionally rejecting them with an error.
+type testPool struct {
+	txs []*types.Transaction
+	err error
+}
+
+func (p *testPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		errs[i] = p.err
+	}
+	if p.err == nil {
+		p.txs = append(p.txs, txs...)
+	}
+	return errs
+}
+
+// newTestAccount creates an unlocked keystore account to sign with.
+func newTestAccount(t *testing.T) (*keystore.KeyStore, accounts.Account, *accounts.Manager) {
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	return ks, account, accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: true}, ks)
+}
+
+// Tests that stuck transactions get bumped until the ceiling is reached and that
+// inclusion of an older version is correctly reported.
+func TestBumping(t *testing.T) {
+	ks, account, am := newTestAccount(t)
+	defer am.Close()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	var (
+		chain = &testChain{statedb: statedb, head: &types.Header{Number: big.NewInt(0)}, included: make(map[common.Hash]uint64)}
+		pool  = new(testPool)
+	)
+	bumper := New(Config{Blocks: 2, Percent: 50, Ceiling: big.NewInt(200)}, 10, chain, pool, am)
+	defer bumper.Close()
+
+	statuses := make(chan *Status, 16)
+	sub := bumper.SubscribeStatus(statuses)
+	defer sub.Unsubscribe()
+
+	tx, err := ks.SignTx(account, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &common.Address{},
+	}), params.TestChainConfig.ChainID)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	bumper.Track(tx, account.Address)
+	if status := <-statuses; status.State != StatePending || status.Hash != tx.Hash() {
+		t.Fatalf("tracking status mismatch: have %v %x, want %v %x", status.State, status.Hash, StatePending, tx.Hash())
+	}
+	// Ensure nothing happens before the threshold, and a bump happens after
+	bumper.update(&types.Header{Number: big.NewInt(1)})
+	if len(pool.txs) != 0 {
+		t.Fatalf("transaction bumped too early")
+	}
+	bumper.update(&types.Header{Number: big.NewInt(2)})
+	if len(pool.txs) != 1 {
+		t.Fatalf("transaction not bumped")
+	}
+	if pool.txs[0].GasTipCap().Int64() != 15 || pool.txs[0].GasFeeCap().Int64() != 150 {
+		t.Fatalf("bumped fees mismatch: have %v/%v, want 15/150", pool.txs[0].GasTipCap(), pool.txs[0].GasFeeCap())
+	}
+	if status := <-statuses; status.State != StateBumped || status.Hash != pool.txs[0].Hash() || status.Original != tx.Hash() {
+		t.Fatalf("bump status mismatch: have %+v", status)
+	}
+	// Ensure the next bump would exceed the ceiling and is refused
+	bumper.update(&types.Header{Number: big.NewInt(4)})
+	if len(pool.txs) != 1 {
+		t.Fatalf("transaction bumped above ceiling")
+	}
+	if status := <-statuses; status.State != StateCapped {
+		t.Fatalf("capped status mismatch: have %v, want %v", status.State, StateCapped)
+	}
+	// Include the original transaction and ensure it's reported
+	statedb.SetNonce(account.Address, 1)
+	chain.included[tx.Hash()] = 5
+
+	bumper.update(&types.Header{Number: big.NewInt(5)})
+	if status := <-statuses; status.State != StateIncluded || status.Hash != tx.Hash() || status.Included != 5 {
+		t.Fatalf("inclusion status mismatch: have %+v", status)
+	}
+	if len(bumper.Status()) != 0 {
+		t.Fatalf("included transaction still managed")
+	}
+}
+
+// Tests that a bump rejected by the pool is reported as failed and retried once
+// the bump interval passes again.
+func TestBumpRetry(t *testing.T) {
+	ks, account, am := newTestAccount(t)
+	defer am.Close()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	var (
+		chain = &testChain{statedb: statedb, head: &types.Header{Number: big.NewInt(0)}, included: make(map[common.Hash]uint64)}
+		pool  = &testPool{err: errors.New("rejected")}
+	)
+	bumper := New(Config{Blocks: 2, Percent: 10, Ceiling: big.NewInt(1000)}, 10, chain, pool, am)
+	defer bumper.Close()
+
+	statuses := make(chan *Status, 16)
+	sub := bumper.SubscribeStatus(statuses)
+	defer sub.Unsubscribe()
+
+	tx, err := ks.SignTx(account, types.NewTransaction(0, common.Address{}, nil, 21000, big.NewInt(100), nil), nil)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	bumper.Track(tx, account.Address)
+	<-statuses
+
+	bumper.update(&types.Header{Number: big.NewInt(2)})
+	if status := <-statuses; status.State != StateFailed || status.Since != 2 {
+		t.Fatalf("failed status mismatch: have %+v", status)
+	}
+	// Accept transactions again and ensure the retry waits for the interval
+	pool.err = nil
+
+	bumper.update(&types.Header{Number: big.NewInt(3)})
+	if len(pool.txs) != 0 {
+		t.Fatalf("failed transaction retried too early")
+	}
+	bumper.update(&types.Header{Number: big.NewInt(4)})
+	if len(pool.txs) != 1 || pool.txs[0].GasPrice().Int64() != 110 {
+		t.Fatalf("failed transaction not retried")
+	}
+	if status := <-statuses; status.State != StateBumped || status.Bumps != 1 {
+		t.Fatalf("retried status mismatch: have %+v", status)
+	}
+}
+
+// Tests that the bump percentage is raised to the pool's price bump, as lower
+// bumps would never be accepted as replacements.
+func TestSanitizePercent(t *testing.T) {
+	tests := []struct {
+		percent   uint64
+		priceBump uint64
+		want      uint64
+	}{
+		{0, 10, 10},
+		{5, 10, 10},
+		{10, 10, 10},
+		{25, 10, 25},
+		{5, 0, 5},
+	}
+	for i, tt := range tests {
+		config := Config{Percent: tt.percent, Ceiling: DefaultCeiling}
+		if have := config.sanitize(tt.priceBump).Percent; have != tt.want {
+			t.Errorf("test %d: percent mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
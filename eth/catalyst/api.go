@@ -39,15 +39,21 @@ import (
 
 // Register adds the engine API to the full node.
 func Register(stack *node.Node, backend *eth.Ethereum) error {
+	RegisterAPI(stack, NewConsensusAPI(backend))
+	return nil
+}
+
+// RegisterAPI adds the given engine API instance to the full node, allowing it
+// to be shared with other drivers of the execution client.
+func RegisterAPI(stack *node.Node, api *ConsensusAPI) {
 	log.Warn("Engine API enabled", "protocol", "eth")
 	stack.RegisterAPIs([]rpc.API{
 		{
 			Namespace:     "engine",
-			Service:       NewConsensusAPI(backend),
+			Service:       api,
 			Authenticated: true,
 		},
 	})
-	return nil
 }
 
 const (
@@ -0,0 +1,52 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderEngine drives the engine API on behalf of consensus drivers which only
+// know the headers of the execution blocks but not their payloads, such as the
+// beacon light client. Instead of receiving the blocks through newPayload, the
+// engine is told about the head headers and retrieves the blocks themselves from
+// the network through beacon sync.
+//
+// HeaderEngine is not exposed over RPC, it is only meant for embedded drivers.
+type HeaderEngine struct {
+	api *ConsensusAPI
+}
+
+// NewHeaderEngine creates a header based driver of the given engine API.
+func NewHeaderEngine(api *ConsensusAPI) *HeaderEngine {
+	return &HeaderEngine{api: api}
+}
+
+// ForkchoiceUpdatedWithHeader announces the header of the new head block to the
+// engine API and updates the forkchoice to it. If the head block is unknown, the
+// update starts a beacon sync towards the header, the same way as it would for
+// a payload previously delivered through newPayload.
+func (e *HeaderEngine) ForkchoiceUpdatedWithHeader(head *types.Header, update engine.ForkchoiceStateV1) (engine.ForkChoiceResponse, error) {
+	if hash := head.Hash(); hash != update.HeadBlockHash {
+		return engine.STATUS_INVALID, fmt.Errorf("head header hash mismatch: have %x, want %x", hash, update.HeadBlockHash)
+	}
+	e.api.remoteBlocks.put(update.HeadBlockHash, head)
+	return e.api.ForkchoiceUpdatedV1(update, nil)
+}
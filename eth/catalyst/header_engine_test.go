@@ -0,0 +1,75 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+)
+
+// Tests that forkchoice updates carrying only the header of an unknown head make
+// the node sync the missing blocks from its peers.
+func TestHeaderEngineSync(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	nodeA, _ := startEthService(t, genesis, blocks)
+	nodeB, ethserviceB := startEthService(t, genesis, blocks[:5])
+	defer nodeA.Close()
+	defer nodeB.Close()
+	for nodeA.Server().NodeInfo().Ports.Listener == 0 {
+		time.Sleep(250 * time.Millisecond)
+	}
+	nodeB.Server().AddPeer(nodeA.Server().Self())
+
+	var (
+		api  = NewHeaderEngine(NewConsensusAPI(ethserviceB))
+		head = blocks[len(blocks)-1]
+	)
+	// Headers not matching the forkchoice update are rejected
+	update := engine.ForkchoiceStateV1{HeadBlockHash: head.Hash()}
+	if _, err := api.ForkchoiceUpdatedWithHeader(blocks[8].Header(), update); err == nil {
+		t.Fatal("mismatching head header accepted")
+	}
+	// Unknown heads are synced from the network
+	resp, err := api.ForkchoiceUpdatedWithHeader(head.Header(), update)
+	if err != nil {
+		t.Fatalf("failed to update forkchoice: %v", err)
+	}
+	if resp.PayloadStatus.Status != engine.SYNCING {
+		t.Fatalf("unexpected status for unknown head: have %s, want %s", resp.PayloadStatus.Status, engine.SYNCING)
+	}
+	timeout := time.After(10 * time.Second)
+	for !ethserviceB.BlockChain().HasBlock(head.Hash(), head.NumberU64()) {
+		select {
+		case <-timeout:
+			t.Fatal("head block not synced")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	// Known heads are set as the chain head
+	resp, err = api.ForkchoiceUpdatedWithHeader(head.Header(), update)
+	if err != nil {
+		t.Fatalf("failed to update forkchoice: %v", err)
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status for known head: have %s, want %s", resp.PayloadStatus.Status, engine.VALID)
+	}
+	if current := ethserviceB.BlockChain().CurrentBlock(); current.Hash() != head.Hash() {
+		t.Fatalf("chain head not updated: have %d, want %d", current.Number, head.NumberU64())
+	}
+}
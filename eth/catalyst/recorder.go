@@ -0,0 +1,307 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultErrorCode is the JSON-RPC error code assigned by the RPC server to any
+// error not carrying its own code.
+const defaultErrorCode = -32000
+
+// RecordedCall is a single engine API call along with the response returned
+// by geth, as stored in a recording.
+type RecordedCall struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *RecordedError    `json:"error,omitempty"`
+}
+
+// RecordedError is an error returned by an engine API call.
+type RecordedError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Recorder writes every engine API call and its response into a file, one JSON
+// object per line, allowing the traffic to be replayed later.
+type Recorder struct {
+	lock sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewRecorder creates a recorder appending to the file at the given path.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Start implements node.Lifecycle.
+func (r *Recorder) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the recording.
+func (r *Recorder) Stop() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.file.Close()
+}
+
+// record writes an engine API call into the recording.
+func (r *Recorder) record(method string, result interface{}, err error, params ...interface{}) {
+	call := RecordedCall{Method: method, Params: make([]json.RawMessage, len(params))}
+	for i, param := range params {
+		blob, err := json.Marshal(param)
+		if err != nil {
+			log.Warn("Failed to record engine API call", "method", method, "err", err)
+			return
+		}
+		call.Params[i] = blob
+	}
+	if err != nil {
+		code := defaultErrorCode
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			code = rpcErr.ErrorCode()
+		}
+		call.Error = &RecordedError{Code: code, Message: err.Error()}
+	} else {
+		blob, err := json.Marshal(result)
+		if err != nil {
+			log.Warn("Failed to record engine API response", "method", method, "err", err)
+			return
+		}
+		call.Result = blob
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.enc.Encode(&call); err != nil {
+		log.Warn("Failed to write engine API recording", "err", err)
+	}
+}
+
+// record writes an engine API call into the recording and passes through its
+// response.
+func record[T any](r *Recorder, method string, result T, err error, params ...interface{}) (T, error) {
+	r.record(method, result, err, params...)
+	return result, err
+}
+
+// ReadRecording loads all the engine API calls from a recording.
+func ReadRecording(path string) ([]*RecordedCall, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		calls   []*RecordedCall
+		scanner = bufio.NewScanner(file)
+	)
+	scanner.Buffer(nil, 128*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		call := new(RecordedCall)
+		if err := json.Unmarshal(scanner.Bytes(), call); err != nil {
+			return nil, fmt.Errorf("invalid recorded call %d: %v", len(calls), err)
+		}
+		calls = append(calls, call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// RegisterRecording adds the given engine API instance to the node, recording
+// all calls and responses into the file at the given path.
+func RegisterRecording(stack *node.Node, api *ConsensusAPI, path string) error {
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		return err
+	}
+	stack.RegisterLifecycle(recorder)
+
+	log.Warn("Engine API enabled", "protocol", "eth", "recording", path)
+	stack.RegisterAPIs([]rpc.API{
+		{
+			Namespace:     "engine",
+			Service:       &recordingAPI{ConsensusAPI: api, recorder: recorder},
+			Authenticated: true,
+		},
+	})
+	return nil
+}
+
+// recordingAPI is an engine API which records all calls and their responses.
+type recordingAPI struct {
+	*ConsensusAPI
+	recorder *Recorder
+}
+
+func (api *recordingAPI) ForkchoiceUpdatedV1(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	res, err := api.ConsensusAPI.ForkchoiceUpdatedV1(update, payloadAttributes)
+	return record(api.recorder, "engine_forkchoiceUpdatedV1", res, err, update, payloadAttributes)
+}
+
+func (api *recordingAPI) ForkchoiceUpdatedV2(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	res, err := api.ConsensusAPI.ForkchoiceUpdatedV2(update, payloadAttributes)
+	return record(api.recorder, "engine_forkchoiceUpdatedV2", res, err, update, payloadAttributes)
+}
+
+func (api *recordingAPI) ExchangeTransitionConfigurationV1(config engine.TransitionConfigurationV1) (*engine.TransitionConfigurationV1, error) {
+	res, err := api.ConsensusAPI.ExchangeTransitionConfigurationV1(config)
+	return record(api.recorder, "engine_exchangeTransitionConfigurationV1", res, err, config)
+}
+
+func (api *recordingAPI) GetPayloadV1(payloadID engine.PayloadID) (*engine.ExecutableData, error) {
+	res, err := api.ConsensusAPI.GetPayloadV1(payloadID)
+	return record(api.recorder, "engine_getPayloadV1", res, err, payloadID)
+}
+
+func (api *recordingAPI) GetPayloadV2(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
+	res, err := api.ConsensusAPI.GetPayloadV2(payloadID)
+	return record(api.recorder, "engine_getPayloadV2", res, err, payloadID)
+}
+
+func (api *recordingAPI) GetPayloadV3(payloadID engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
+	res, err := api.ConsensusAPI.GetPayloadV3(payloadID)
+	return record(api.recorder, "engine_getPayloadV3", res, err, payloadID)
+}
+
+func (api *recordingAPI) NewPayloadV1(params engine.ExecutableData) (engine.PayloadStatusV1, error) {
+	res, err := api.ConsensusAPI.NewPayloadV1(params)
+	return record(api.recorder, "engine_newPayloadV1", res, err, params)
+}
+
+func (api *recordingAPI) NewPayloadV2(params engine.ExecutableData) (engine.PayloadStatusV1, error) {
+	res, err := api.ConsensusAPI.NewPayloadV2(params)
+	return record(api.recorder, "engine_newPayloadV2", res, err, params)
+}
+
+func (api *recordingAPI) NewPayloadV3(params engine.ExecutableData, versionedHashes *[]common.Hash) (engine.PayloadStatusV1, error) {
+	res, err := api.ConsensusAPI.NewPayloadV3(params, versionedHashes)
+	return record(api.recorder, "engine_newPayloadV3", res, err, params, versionedHashes)
+}
+
+func (api *recordingAPI) ExchangeCapabilities(capabilities []string) []string {
+	res, _ := record(api.recorder, "engine_exchangeCapabilities", api.ConsensusAPI.ExchangeCapabilities(capabilities), nil, capabilities)
+	return res
+}
+
+func (api *recordingAPI) GetPayloadBodiesByHashV1(hashes []common.Hash) []*engine.ExecutionPayloadBodyV1 {
+	res, _ := record(api.recorder, "engine_getPayloadBodiesByHashV1", api.ConsensusAPI.GetPayloadBodiesByHashV1(hashes), nil, hashes)
+	return res
+}
+
+func (api *recordingAPI) GetPayloadBodiesByRangeV1(start, count hexutil.Uint64) ([]*engine.ExecutionPayloadBodyV1, error) {
+	res, err := api.ConsensusAPI.GetPayloadBodiesByRangeV1(start, count)
+	return record(api.recorder, "engine_getPayloadBodiesByRangeV1", res, err, start, count)
+}
+
+// Divergence is a replayed engine API call whose response differs from the
+// recorded one.
+type Divergence struct {
+	Index  int           // Position of the call in the recording
+	Call   *RecordedCall // Recorded call, including the original response
+	Result json.RawMessage
+	Error  *RecordedError
+}
+
+// String implements fmt.Stringer.
+func (d *Divergence) String() string {
+	describe := func(result json.RawMessage, err *RecordedError) string {
+		if err != nil {
+			return fmt.Sprintf("error %d %q", err.Code, err.Message)
+		}
+		return string(result)
+	}
+	return fmt.Sprintf("call %d (%s): recorded %s, replayed %s", d.Index, d.Call.Method, describe(d.Call.Result, d.Call.Error), describe(d.Result, d.Error))
+}
+
+// Replay executes the recorded engine API calls in order against the given API,
+// returning all calls whose responses diverged from the recorded ones.
+func Replay(api *ConsensusAPI, calls []*RecordedCall) ([]*Divergence, error) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("engine", api); err != nil {
+		return nil, err
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var diverged []*Divergence
+	for i, call := range calls {
+		params := make([]interface{}, len(call.Params))
+		for j, param := range call.Params {
+			params[j] = param
+		}
+		var (
+			result json.RawMessage
+			replay *RecordedError
+		)
+		if err := client.Call(&result, call.Method, params...); err != nil {
+			var rpcErr rpc.Error
+			if !errors.As(err, &rpcErr) {
+				return diverged, fmt.Errorf("failed to replay call %d (%s): %v", i, call.Method, err)
+			}
+			replay = &RecordedError{Code: rpcErr.ErrorCode(), Message: err.Error()}
+		}
+		if !equalResponses(call, result, replay) {
+			diverged = append(diverged, &Divergence{Index: i, Call: call, Result: result, Error: replay})
+		}
+	}
+	return diverged, nil
+}
+
+// equalResponses reports whether a replayed response matches the recorded one.
+// Results are compared semantically to be independent of JSON formatting.
+func equalResponses(call *RecordedCall, result json.RawMessage, err *RecordedError) bool {
+	if call.Error != nil || err != nil {
+		return call.Error != nil && err != nil && *call.Error == *err
+	}
+	var have, want interface{}
+	if json.Unmarshal(result, &have) != nil || json.Unmarshal(call.Result, &want) != nil {
+		return false
+	}
+	return reflect.DeepEqual(have, want)
+}
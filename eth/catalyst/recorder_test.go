@@ -0,0 +1,90 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that recorded engine API traffic replays identically against a node in
+// the same initial state, and that diverging responses are detected.
+func TestRecordReplay(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	path := filepath.Join(t.TempDir(), "engine.jsonl")
+
+	// Record the import of the last few blocks through the engine API
+	n, ethservice := startEthService(t, genesis, blocks[:5])
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+	api := &recordingAPI{ConsensusAPI: newConsensusAPIWithoutHeartbeat(ethservice), recorder: recorder}
+	for _, block := range blocks[5:] {
+		if _, err := api.NewPayloadV1(*engine.BlockToExecutableData(block, nil, nil).ExecutionPayload); err != nil {
+			t.Fatalf("failed to import block %d: %v", block.NumberU64(), err)
+		}
+	}
+	head := blocks[len(blocks)-1].Hash()
+	if _, err := api.ForkchoiceUpdatedV1(engine.ForkchoiceStateV1{HeadBlockHash: head}, nil); err != nil {
+		t.Fatalf("failed to update forkchoice: %v", err)
+	}
+	// Record a call without an error return too
+	api.ExchangeCapabilities([]string{"engine_newPayloadV1"})
+
+	// Record a failing call too, to check errors are replayed
+	if _, err := api.GetPayloadV1(engine.PayloadID{0x01}); err == nil {
+		t.Fatalf("unknown payload retrieved")
+	}
+	recorder.Stop()
+	n.Close()
+
+	calls, err := ReadRecording(path)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+	if len(calls) != 8 {
+		t.Fatalf("recorded call count mismatch: have %d, want %d", len(calls), 8)
+	}
+	// Replay the recording against a fresh node, expecting identical responses
+	n, ethservice = startEthService(t, genesis, blocks[:5])
+	defer n.Close()
+
+	diverged, err := Replay(newConsensusAPIWithoutHeartbeat(ethservice), calls)
+	if err != nil {
+		t.Fatalf("failed to replay recording: %v", err)
+	}
+	if len(diverged) != 0 {
+		t.Fatalf("unexpected divergences: %v", diverged)
+	}
+	if have := ethservice.BlockChain().CurrentBlock().Hash(); have != head {
+		t.Fatalf("replayed head mismatch: have %x, want %x", have, head)
+	}
+	// Tamper with a recorded response and ensure the divergence is reported
+	calls[0].Result, _ = json.Marshal(engine.PayloadStatusV1{Status: engine.INVALID, LatestValidHash: &common.Hash{}})
+	diverged, err = Replay(newConsensusAPIWithoutHeartbeat(ethservice), calls[:1])
+	if err != nil {
+		t.Fatalf("failed to replay recording: %v", err)
+	}
+	if len(diverged) != 1 || diverged[0].Index != 0 {
+		t.Fatalf("divergence mismatch: have %v, want call 0", diverged)
+	}
+}
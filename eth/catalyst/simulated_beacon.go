@@ -17,14 +17,19 @@
 package catalyst
 
 import (
+	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
@@ -34,6 +39,10 @@ import (
 
 const devEpochLength = 32
 
+// maxMineBlocks is the maximum number of blocks a single mine request may seal,
+// since they are sealed while holding the beacon lock.
+const maxMineBlocks = 1024
+
 // withdrawalQueue implements a FIFO queue which holds withdrawals that are
 // pending inclusion.
 type withdrawalQueue struct {
@@ -67,6 +76,12 @@ func (w *withdrawalQueue) gatherPending(maxCount int) []*types.Withdrawal {
 	}
 }
 
+// snapshot is a chain head marker the simulated beacon can be reverted to.
+type snapshot struct {
+	number uint64
+	hash   common.Hash
+}
+
 type SimulatedBeacon struct {
 	shutdownCh  chan struct{}
 	eth         *eth.Ethereum
@@ -79,6 +94,12 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	nextBlockTime uint64              // Timestamp override for the next block (0 = none)
+	timeOffset    uint64              // Seconds to add to the wall clock for block timestamps
+	snapshots     map[uint64]snapshot // Chain heads saved for reverting to
+	nextSnapshot  uint64              // Identifier of the next snapshot to take
+	lock          sync.Mutex          // Lock serializing chain modifications
 }
 
 func NewSimulatedBeacon(period uint64, eth *eth.Ethereum) (*SimulatedBeacon, error) {
@@ -108,6 +129,7 @@ func NewSimulatedBeacon(period uint64, eth *eth.Ethereum) (*SimulatedBeacon, err
 		lastBlockTime:      block.Time,
 		curForkchoiceState: current,
 		withdrawals:        withdrawalQueue{make(chan *types.Withdrawal, 20)},
+		snapshots:          make(map[uint64]snapshot),
 	}, nil
 }
 
@@ -136,16 +158,34 @@ func (c *SimulatedBeacon) Stop() error {
 // sealBlock initiates payload building for a new block and creates a new block
 // with the completed payload.
 func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
-	tstamp := uint64(time.Now().Unix())
-	if tstamp <= c.lastBlockTime {
-		tstamp = c.lastBlockTime + 1
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.seal(withdrawals)
+}
+
+// seal is the lock-free version of sealBlock, assuming the caller holds the
+// chain modification lock.
+func (c *SimulatedBeacon) seal(withdrawals []*types.Withdrawal) error {
+	// Ensure all recently added transactions are promoted before building
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
 	}
+	tstamp := c.nextTimestamp()
+
 	c.feeRecipientLock.Lock()
 	feeRecipient := c.feeRecipient
 	c.feeRecipientLock.Unlock()
 
+	// Use a random prevRandao, which also avoids reusing a previously built
+	// payload when sealing on top of the same parent after a rewind
+	var random common.Hash
+	if _, err := crand.Read(random[:]); err != nil {
+		return err
+	}
 	fcResponse, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, &engine.PayloadAttributes{
 		Timestamp:             tstamp,
+		Random:                random,
 		SuggestedFeeRecipient: feeRecipient,
 		Withdrawals:           withdrawals,
 	})
@@ -159,38 +199,324 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal) error {
 	}
 	payload := envelope.ExecutionPayload
 
-	var finalizedHash common.Hash
-	if payload.Number%devEpochLength == 0 {
-		finalizedHash = payload.BlockHash
-	} else {
-		finalizedHash = c.eth.BlockChain().GetBlockByNumber((payload.Number - 1) / devEpochLength * devEpochLength).Hash()
-	}
-
 	// mark the payload as canon
 	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
 		return fmt.Errorf("failed to mark payload as canonical: %v", err)
 	}
-	c.curForkchoiceState = engine.ForkchoiceStateV1{
-		HeadBlockHash:      payload.BlockHash,
-		SafeBlockHash:      payload.BlockHash,
-		FinalizedBlockHash: finalizedHash,
-	}
+	c.curForkchoiceState = c.forkchoiceState(payload.Number, payload.BlockHash)
+
 	// mark the block containing the payload as canonical
 	if _, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
 		return fmt.Errorf("failed to mark block as canonical: %v", err)
 	}
 	c.lastBlockTime = payload.Timestamp
+
+	// Wait for the pool to process the new head before returning
+	return c.eth.TxPool().Sync()
+}
+
+// nextTimestamp returns the timestamp of the next sealed block, consuming any
+// explicitly set one. Otherwise the wall clock shifted by the time offset is
+// used, bumped past the current head if blocks are sealed in quick succession.
+func (c *SimulatedBeacon) nextTimestamp() uint64 {
+	tstamp := uint64(time.Now().Unix()) + c.timeOffset
+	if c.nextBlockTime != 0 {
+		tstamp, c.nextBlockTime = c.nextBlockTime, 0
+	}
+	if tstamp <= c.lastBlockTime {
+		tstamp = c.lastBlockTime + 1
+	}
+	return tstamp
+}
+
+// sealPending seals a new block if there are any pending transactions left in
+// the pool. Transactions might be gone by the time the chain modification lock
+// is acquired if the chain was reverted in the meantime.
+func (c *SimulatedBeacon) sealPending() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
+	}
+	withdrawals := c.withdrawals.gatherPending(10)
+	if pending, _ := c.eth.TxPool().Stats(); pending == 0 && len(withdrawals) == 0 {
+		return nil
+	}
+	return c.seal(withdrawals)
+}
+
+// forkchoiceState assembles the forkchoice state marking the given block as the
+// head and safe block, finalizing the last epoch boundary not after it.
+func (c *SimulatedBeacon) forkchoiceState(number uint64, hash common.Hash) engine.ForkchoiceStateV1 {
+	finalizedHash := hash
+	if number%devEpochLength != 0 {
+		finalizedHash = c.eth.BlockChain().GetCanonicalHash((number - 1) / devEpochLength * devEpochLength)
+	}
+	return engine.ForkchoiceStateV1{
+		HeadBlockHash:      hash,
+		SafeBlockHash:      hash,
+		FinalizedBlockHash: finalizedHash,
+	}
+}
+
+// setNextBlockTimestamp overrides the timestamp of the next sealed block. It
+// must be after the current head's timestamp.
+func (c *SimulatedBeacon) setNextBlockTimestamp(timestamp uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if timestamp <= c.lastBlockTime {
+		return fmt.Errorf("timestamp %d not after head timestamp %d", timestamp, c.lastBlockTime)
+	}
+	c.nextBlockTime = timestamp
+	return nil
+}
+
+// increaseTime moves the clock used for block timestamps forward by the given
+// number of seconds, returning the total offset from the wall clock.
+func (c *SimulatedBeacon) increaseTime(seconds uint64) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.timeOffset += seconds
+	return c.timeOffset
+}
+
+// mine seals the given number of blocks instantly, including any pending
+// transactions and withdrawals.
+func (c *SimulatedBeacon) mine(blocks uint64) error {
+	if blocks > maxMineBlocks {
+		return fmt.Errorf("too many blocks requested: %d, maximum is %d", blocks, maxMineBlocks)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i := uint64(0); i < blocks; i++ {
+		if err := c.seal(c.withdrawals.gatherPending(10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot saves the current chain head and returns an identifier which can be
+// used to revert the chain back to it.
+func (c *SimulatedBeacon) snapshot() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	head := c.eth.BlockChain().CurrentBlock()
+
+	id := c.nextSnapshot
+	c.snapshots[id] = snapshot{number: head.Number.Uint64(), hash: head.Hash()}
+	c.nextSnapshot++
+
+	return id
+}
+
+// revert rewinds the chain to a previously saved snapshot, dropping it and all
+// the snapshots taken after it. The transaction pool is emptied afterwards, as
+// the transactions of the dropped blocks would otherwise be mined again.
+func (c *SimulatedBeacon) revert(id uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	snap, ok := c.snapshots[id]
+	if !ok {
+		return fmt.Errorf("unknown snapshot %d", id)
+	}
+	if err := c.rewind(snap.number, snap.hash); err != nil {
+		return fmt.Errorf("failed to revert to snapshot %d: %v", id, err)
+	}
+	// Wait for the pool to process the rewind and drop everything reinjected
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
+	}
+	c.eth.TxPool().Clear()
+
+	for snapid := range c.snapshots {
+		if snapid >= id {
+			delete(c.snapshots, snapid)
+		}
+	}
+	return nil
+}
+
+// rewind sets the chain head back to the given ancestor block, deleting all the
+// blocks after it, and marks it as the new head to build on top of.
+func (c *SimulatedBeacon) rewind(number uint64, hash common.Hash) error {
+	chain := c.eth.BlockChain()
+	if err := chain.SetHead(number); err != nil {
+		return err
+	}
+	if head := chain.CurrentBlock(); head.Hash() != hash {
+		return fmt.Errorf("head #%d [%x..] unavailable", number, hash[:4])
+	}
+	return c.markHead(number, hash)
+}
+
+// markHead marks the current chain head as the new head, safe and finalized
+// block to build on top of.
+func (c *SimulatedBeacon) markHead(number uint64, hash common.Hash) error {
+	c.curForkchoiceState = c.forkchoiceState(number, hash)
+	if _, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+		return fmt.Errorf("failed to mark block as canonical: %v", err)
+	}
+	c.lastBlockTime = c.eth.BlockChain().CurrentBlock().Time
+	c.nextBlockTime = 0
+	return nil
+}
+
+// Commit seals a block on demand, including all the pending transactions and
+// withdrawals, and returns the hash of the new head.
+func (c *SimulatedBeacon) Commit() (common.Hash, error) {
+	if err := c.sealBlock(c.withdrawals.gatherPending(10)); err != nil {
+		return common.Hash{}, err
+	}
+	return c.eth.BlockChain().CurrentBlock().Hash(), nil
+}
+
+// Rollback drops all the pending transactions from the pool.
+func (c *SimulatedBeacon) Rollback() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
+	}
+	c.eth.TxPool().Clear()
+	return nil
+}
+
+// Fork reorgs the chain back to the given ancestor block, so that the next sealed
+// blocks form a side chain. Like any reorg, it announces the logs of the dropped
+// blocks as removed and returns their transactions to the pool. It can only be
+// called if there are no pending transactions.
+func (c *SimulatedBeacon) Fork(parentHash common.Hash) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
+	}
+	if pending, _ := c.eth.TxPool().Stats(); pending != 0 {
+		return errors.New("pending block dirty")
+	}
+	parent := c.eth.BlockChain().GetBlockByHash(parentHash)
+	if parent == nil {
+		return errors.New("parent not found")
+	}
+	if _, err := c.eth.BlockChain().SetCanonical(parent); err != nil {
+		return err
+	}
+	// Wait for the pool to take in the dropped transactions before returning
+	if err := c.eth.TxPool().Sync(); err != nil {
+		return err
+	}
+	return c.markHead(parent.NumberU64(), parentHash)
+}
+
+// AdjustTime moves the timestamps of the next sealed blocks forward by the
+// given amount.
+func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) error {
+	if adjustment < 0 {
+		return errors.New("can't adjust time backwards")
+	}
+	c.increaseTime(uint64(adjustment / time.Second))
 	return nil
 }
 
+// modifyState seals a new empty block on top of the current head, with its
+// state modified by the given callback. The header is prepared and the block
+// assembled by the consensus engine like any sealed block, but since executing
+// it would not reproduce the modified state, it is written to the chain directly
+// instead of being imported, so it is only meant for local development chains.
+func (c *SimulatedBeacon) modifyState(modify func(statedb *state.StateDB)) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var (
+		chain  = c.eth.BlockChain()
+		config = chain.Config()
+		parent = chain.CurrentBlock()
+	)
+	c.feeRecipientLock.Lock()
+	feeRecipient := c.feeRecipient
+	c.feeRecipientLock.Unlock()
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       c.nextTimestamp(),
+		Coinbase:   feeRecipient,
+	}
+	if _, err := crand.Read(header.MixDigest[:]); err != nil {
+		return err
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		var excessBlobGas uint64
+		if config.IsCancun(parent.Number, parent.Time) {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excessBlobGas
+		header.BlobGasUsed = new(uint64)
+		header.BeaconRoot = new(common.Hash)
+	}
+	if err := c.eth.Engine().Prepare(chain, header); err != nil {
+		return err
+	}
+	statedb, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return err
+	}
+	modify(statedb)
+
+	block, err := c.eth.Engine().FinalizeAndAssemble(chain, header, statedb, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.eth.Engine().VerifyHeader(chain, block.Header()); err != nil {
+		return err
+	}
+	if _, err := chain.WriteBlockAndSetHead(block, nil, nil, statedb, true); err != nil {
+		return err
+	}
+	if err := c.markHead(block.NumberU64(), block.Hash()); err != nil {
+		return err
+	}
+	// Wait for the pool to revalidate against the modified state before returning
+	return c.eth.TxPool().Sync()
+}
+
 // loopOnDemand runs the block production loop for "on-demand" configuration (period = 0)
 func (c *SimulatedBeacon) loopOnDemand() {
 	var (
 		newTxs = make(chan core.NewTxsEvent)
 		sub    = c.eth.TxPool().SubscribeNewTxsEvent(newTxs)
+		sealc  = make(chan struct{}, 1)
 	)
 	defer sub.Unsubscribe()
 
+	// Sealing waits for the pool to settle, which might fire further transaction
+	// events, so consume them on a separate goroutine to avoid blocking the pool
+	go func() {
+		for {
+			select {
+			case <-newTxs:
+				select {
+				case sealc <- struct{}{}:
+				default:
+				}
+			case <-c.shutdownCh:
+				return
+			}
+		}
+	}()
 	for {
 		select {
 		case <-c.shutdownCh:
@@ -201,9 +527,8 @@ func (c *SimulatedBeacon) loopOnDemand() {
 				log.Error("Error performing sealing-work", "err", err)
 				return
 			}
-		case <-newTxs:
-			withdrawals := c.withdrawals.gatherPending(10)
-			if err := c.sealBlock(withdrawals); err != nil {
+		case <-sealc:
+			if err := c.sealPending(); err != nil {
 				log.Error("Error performing sealing-work", "err", err)
 				return
 			}
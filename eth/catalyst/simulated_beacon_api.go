@@ -18,8 +18,11 @@ package catalyst
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -34,3 +37,61 @@ func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) e
 func (a *api) SetFeeRecipient(ctx context.Context, feeRecipient common.Address) {
 	a.simBeacon.setFeeRecipient(feeRecipient)
 }
+
+// SetNextBlockTimestamp sets the timestamp of the next block to be mined.
+func (a *api) SetNextBlockTimestamp(ctx context.Context, timestamp hexutil.Uint64) error {
+	return a.simBeacon.setNextBlockTimestamp(uint64(timestamp))
+}
+
+// IncreaseTime moves the block timestamps forward by the given number of seconds
+// and returns the total time offset from the wall clock.
+func (a *api) IncreaseTime(ctx context.Context, seconds hexutil.Uint64) hexutil.Uint64 {
+	return hexutil.Uint64(a.simBeacon.increaseTime(uint64(seconds)))
+}
+
+// Mine instantly seals the given number of blocks, one if unspecified.
+func (a *api) Mine(ctx context.Context, blocks *hexutil.Uint64) error {
+	if blocks == nil {
+		return a.simBeacon.mine(1)
+	}
+	return a.simBeacon.mine(uint64(*blocks))
+}
+
+// Snapshot saves the current chain head and returns its identifier.
+func (a *api) Snapshot(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(a.simBeacon.snapshot())
+}
+
+// Revert rewinds the chain to the given snapshot, dropping it along with all the
+// later ones, and clears the transaction pool.
+func (a *api) Revert(ctx context.Context, id hexutil.Uint64) error {
+	return a.simBeacon.revert(uint64(id))
+}
+
+// SetBalance mines a block setting the balance of the given account.
+func (a *api) SetBalance(ctx context.Context, address common.Address, balance *hexutil.Big) error {
+	return a.simBeacon.modifyState(func(statedb *state.StateDB) {
+		statedb.SetBalance(address, (*big.Int)(balance))
+	})
+}
+
+// SetNonce mines a block setting the nonce of the given account.
+func (a *api) SetNonce(ctx context.Context, address common.Address, nonce hexutil.Uint64) error {
+	return a.simBeacon.modifyState(func(statedb *state.StateDB) {
+		statedb.SetNonce(address, uint64(nonce))
+	})
+}
+
+// SetCode mines a block setting the code of the given account.
+func (a *api) SetCode(ctx context.Context, address common.Address, code hexutil.Bytes) error {
+	return a.simBeacon.modifyState(func(statedb *state.StateDB) {
+		statedb.SetCode(address, code)
+	})
+}
+
+// SetStorageAt mines a block setting a storage slot of the given account.
+func (a *api) SetStorageAt(ctx context.Context, address common.Address, slot common.Hash, value common.Hash) error {
+	return a.simBeacon.modifyState(func(statedb *state.StateDB) {
+		statedb.SetState(address, slot, value)
+	})
+}
@@ -17,12 +17,14 @@
 package catalyst
 
 import (
+	"bytes"
 	"context"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -34,7 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
+func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis, period uint64) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
 	t.Helper()
 
 	n, err := node.New(&node.Config{
@@ -54,7 +56,7 @@ func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.
 		t.Fatal("can't create eth service:", err)
 	}
 
-	simBeacon, err := NewSimulatedBeacon(1, ethservice)
+	simBeacon, err := NewSimulatedBeacon(period, ethservice)
 	if err != nil {
 		t.Fatal("can't create simulated beacon:", err)
 	}
@@ -86,7 +88,7 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 	// short period (1 second) for testing purposes
 	var gasLimit uint64 = 10_000_000
 	genesis := core.DeveloperGenesisBlock(gasLimit, testAddr)
-	node, ethService, mock := startSimulatedBeaconEthService(t, genesis)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 1)
 	_ = mock
 	defer node.Close()
 
@@ -139,3 +141,117 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the simulated beacon can be driven manually: block timestamps can
+// be overridden, blocks mined instantly, state modified and the chain reverted
+// to earlier snapshots.
+func TestSimulatedBeaconTimeTravel(t *testing.T) {
+	var (
+		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+		otherAddr  = common.Address{0xaa}
+	)
+	genesis := core.DeveloperGenesisBlock(10_000_000, testAddr)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	chain := ethService.BlockChain()
+	genesisSnap := mock.snapshot()
+
+	// Override the next block's timestamp and mine a few blocks instantly
+	timestamp := uint64(time.Now().Unix()) + 1000
+	if err := mock.setNextBlockTimestamp(timestamp); err != nil {
+		t.Fatalf("failed to set next block timestamp: %v", err)
+	}
+	if err := mock.mine(1); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Number.Uint64() != 1 || head.Time != timestamp {
+		t.Fatalf("head mismatch: have #%d at %d, want #1 at %d", head.Number, head.Time, timestamp)
+	}
+	if err := mock.setNextBlockTimestamp(timestamp); err == nil {
+		t.Fatalf("timestamp not after head accepted")
+	}
+	mock.increaseTime(3600)
+	if err := mock.mine(2); err != nil {
+		t.Fatalf("failed to mine blocks: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Number.Uint64() != 3 || head.Time < uint64(time.Now().Unix())+3600 {
+		t.Fatalf("head mismatch: have #%d at %d, want #3 after %d", head.Number, head.Time, uint64(time.Now().Unix())+3600)
+	}
+	if err := mock.mine(maxMineBlocks + 1); err == nil {
+		t.Fatalf("too many blocks mined")
+	}
+	// Modify some state and ensure it's reflected in the head, which also
+	// follows the adjusted clock
+	mock.increaseTime(3600)
+	api := &api{mock}
+	if err := api.SetBalance(context.Background(), otherAddr, (*hexutil.Big)(big.NewInt(1234))); err != nil {
+		t.Fatalf("failed to set balance: %v", err)
+	}
+	if err := api.SetCode(context.Background(), otherAddr, []byte{0x60, 0x00}); err != nil {
+		t.Fatalf("failed to set code: %v", err)
+	}
+	if err := api.SetStorageAt(context.Background(), otherAddr, common.Hash{0x01}, common.Hash{0x02}); err != nil {
+		t.Fatalf("failed to set storage: %v", err)
+	}
+	if err := api.SetNonce(context.Background(), otherAddr, 5); err != nil {
+		t.Fatalf("failed to set nonce: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Number.Uint64() != 7 || head.Time < uint64(time.Now().Unix())+7200 {
+		t.Fatalf("head mismatch: have #%d at %d, want #7 after %d", head.Number, head.Time, uint64(time.Now().Unix())+7200)
+	}
+	if nonce := ethService.TxPool().Nonce(otherAddr); nonce != 5 {
+		t.Errorf("pool nonce mismatch: have %d, want %d", nonce, 5)
+	}
+	statedb, _ := chain.State()
+	if balance := statedb.GetBalance(otherAddr); balance.Cmp(big.NewInt(1234)) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", balance, 1234)
+	}
+	if code := statedb.GetCode(otherAddr); !bytes.Equal(code, []byte{0x60, 0x00}) {
+		t.Errorf("code mismatch: have %x, want %x", code, []byte{0x60, 0x00})
+	}
+	if value := statedb.GetState(otherAddr, common.Hash{0x01}); value != (common.Hash{0x02}) {
+		t.Errorf("storage mismatch: have %x, want %x", value, common.Hash{0x02})
+	}
+	// Snapshot the chain, mine a transaction and revert back
+	snap := mock.snapshot()
+	head := chain.CurrentBlock()
+
+	signer := types.LatestSigner(chain.Config())
+	tx, _ := types.SignTx(types.NewTransaction(0, otherAddr, big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
+	if err := ethService.APIBackend.SendTx(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if err := mock.mine(1); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if chain.CurrentBlock().Number.Uint64() <= head.Number.Uint64() {
+		t.Fatalf("chain not advanced")
+	}
+	if err := mock.revert(snap); err != nil {
+		t.Fatalf("failed to revert: %v", err)
+	}
+	if have := chain.CurrentBlock(); have.Hash() != head.Hash() {
+		t.Fatalf("reverted head mismatch: have #%d [%x], want #%d [%x]", have.Number, have.Hash(), head.Number, head.Hash())
+	}
+	if pending, queued := ethService.TxPool().Stats(); pending+queued != 0 {
+		t.Fatalf("transaction pool not cleared: %d pending, %d queued", pending, queued)
+	}
+	if err := mock.revert(snap); err == nil {
+		t.Fatalf("reverted to consumed snapshot")
+	}
+	// Revert all the way to genesis
+	if err := mock.revert(genesisSnap); err != nil {
+		t.Fatalf("failed to revert to genesis: %v", err)
+	}
+	if number := chain.CurrentBlock().Number.Uint64(); number != 0 {
+		t.Fatalf("head not reverted to genesis: #%d", number)
+	}
+	if err := mock.mine(1); err != nil {
+		t.Fatalf("failed to mine on reverted chain: %v", err)
+	}
+	if number := chain.CurrentBlock().Number.Uint64(); number != 1 {
+		t.Fatalf("head mismatch after revert: have #%d, want #1", number)
+	}
+}
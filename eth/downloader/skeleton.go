@@ -606,6 +606,27 @@ func (s *skeleton) saveSyncStatus(db ethdb.KeyValueWriter) {
 	rawdb.WriteSkeletonSyncStatus(db, status)
 }
 
+// WriteCheckpointSyncStatus initializes the skeleton sync progress of a database
+// started at a trusted checkpoint, the checkpoint header forming the only known
+// subchain. Any header announced afterwards will be linked to the checkpoint and
+// the headers preceding it backfilled.
+func WriteCheckpointSyncStatus(db ethdb.KeyValueWriter, header *types.Header) {
+	number := header.Number.Uint64()
+	status, err := json.Marshal(&skeletonProgress{
+		Subchains: []*subchain{{
+			Head: number,
+			Tail: number,
+			Next: header.ParentHash,
+		}},
+		Finalized: &number,
+	})
+	if err != nil {
+		panic(err) // This can only fail during implementation
+	}
+	rawdb.WriteSkeletonHeader(db, header)
+	rawdb.WriteSkeletonSyncStatus(db, status)
+}
+
 // processNewHead does the internal shuffling for a new head marker and either
 // accepts and integrates it into the skeleton or requests a reorg. Upon reorg,
 // the syncer will tear itself down and restart with a fresh head. It is simpler
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/eth/bumper"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -64,6 +65,7 @@ var Defaults = Config{
 	TxLookupLimit:      2350000,
 	TransactionHistory: 2350000,
 	StateHistory:       params.FullImmutabilityThreshold,
+	LogHistory:         2350000,
 	StateScheme:        rawdb.HashScheme,
 	LightPeers:         100,
 	DatabaseCache:      512,
@@ -75,6 +77,7 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	TxBumper:           bumper.DefaultConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -105,6 +108,12 @@ type Config struct {
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	LogIndex           bool   `toml:",omitempty"` // Whether to index the logs by address and topic, next to the bloom bits.
+	LogHistory         uint64 `toml:",omitempty"` // The maximum number of blocks from head whose logs are indexed.
+	TokenIndex         bool   `toml:",omitempty"` // Whether to index token transfers along with the logs.
+	AddressIndex       bool   `toml:",omitempty"` // Whether to index the transactions by address, within the transaction history.
+	ContractIndex      bool   `toml:",omitempty"` // Whether to index the creator of every contract created by imported blocks.
+	SenderIndex        bool   `toml:",omitempty"` // Whether to index the transactions by sender and nonce, within the transaction history.
 	StateScheme        string `toml:",omitempty"` // State scheme used to store ethereum state and merkle trie nodes on top
 
 	// RequiredBlocks is a set of block number -> hash mappings which must be in the
@@ -139,8 +148,10 @@ type Config struct {
 	Miner miner.Config
 
 	// Transaction pool options
-	TxPool   legacypool.Config
-	BlobPool blobpool.Config
+	TxPool       legacypool.Config
+	BlobPool     blobpool.Config
+	TxPoolPolicy string `toml:",omitempty"` // Path to the transaction admission policy file
+	TxBumper     bumper.Config
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -161,11 +172,28 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCLogQueryLimit is the maximum number of logs returned by a single log
+	// query, or by a page of a paginated one. It applies to eth_getLogs,
+	// eth_getFilterLogs and GraphQL log queries alike.
+	RPCLogQueryLimit int
+
+	// RPCLogRangeLimit is the maximum number of blocks a single log query may
+	// span, paginated queries scan at most this many blocks per page. Like the
+	// result limit, it applies to all historical log queries.
+	RPCLogRangeLimit uint64
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
 	// OverrideVerkle (TODO: remove after the fork)
 	OverrideVerkle *uint64 `toml:",omitempty"`
+
+	// DevFork is the RPC endpoint of a remote chain whose state the developer
+	// chain is lazily forked from.
+	DevFork string `toml:",omitempty"`
+
+	// DevForkBlock is the remote block to fork the state at (nil = latest).
+	DevForkBlock *uint64 `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/eth/bumper"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/miner"
@@ -27,6 +28,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxLookupLimit           uint64                 `toml:",omitempty"`
 		TransactionHistory      uint64                 `toml:",omitempty"`
 		StateHistory            uint64                 `toml:",omitempty"`
+		LogIndex                bool                   `toml:",omitempty"`
+		LogHistory              uint64                 `toml:",omitempty"`
+		TokenIndex              bool                   `toml:",omitempty"`
+		AddressIndex            bool                   `toml:",omitempty"`
+		ContractIndex           bool                   `toml:",omitempty"`
+		SenderIndex             bool                   `toml:",omitempty"`
 		StateScheme             string                 `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               int                    `toml:",omitempty"`
@@ -48,14 +55,20 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		TxPoolPolicy            string `toml:",omitempty"`
+		TxBumper                bumper.Config
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
 		RPCTxFeeCap             float64
+		RPCLogQueryLimit        int
+		RPCLogRangeLimit        uint64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
+		DevFork                 string  `toml:",omitempty"`
+		DevForkBlock            *uint64 `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -68,6 +81,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
 	enc.StateHistory = c.StateHistory
+	enc.LogIndex = c.LogIndex
+	enc.LogHistory = c.LogHistory
+	enc.TokenIndex = c.TokenIndex
+	enc.AddressIndex = c.AddressIndex
+	enc.ContractIndex = c.ContractIndex
+	enc.SenderIndex = c.SenderIndex
 	enc.StateScheme = c.StateScheme
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
@@ -89,14 +108,20 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxPoolPolicy = c.TxPoolPolicy
+	enc.TxBumper = c.TxBumper
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCLogQueryLimit = c.RPCLogQueryLimit
+	enc.RPCLogRangeLimit = c.RPCLogRangeLimit
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
+	enc.DevFork = c.DevFork
+	enc.DevForkBlock = c.DevForkBlock
 	return &enc, nil
 }
 
@@ -113,6 +138,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxLookupLimit           *uint64                `toml:",omitempty"`
 		TransactionHistory      *uint64                `toml:",omitempty"`
 		StateHistory            *uint64                `toml:",omitempty"`
+		LogIndex                *bool                  `toml:",omitempty"`
+		LogHistory              *uint64                `toml:",omitempty"`
+		TokenIndex              *bool                  `toml:",omitempty"`
+		AddressIndex            *bool                  `toml:",omitempty"`
+		ContractIndex           *bool                  `toml:",omitempty"`
+		SenderIndex             *bool                  `toml:",omitempty"`
 		StateScheme             *string                `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               *int                   `toml:",omitempty"`
@@ -134,14 +165,20 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		TxPoolPolicy            *string `toml:",omitempty"`
+		TxBumper                *bumper.Config
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
 		RPCTxFeeCap             *float64
+		RPCLogQueryLimit        *int
+		RPCLogRangeLimit        *uint64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
+		DevFork                 *string `toml:",omitempty"`
+		DevForkBlock            *uint64 `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -177,6 +214,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.LogIndex != nil {
+		c.LogIndex = *dec.LogIndex
+	}
+	if dec.LogHistory != nil {
+		c.LogHistory = *dec.LogHistory
+	}
+	if dec.TokenIndex != nil {
+		c.TokenIndex = *dec.TokenIndex
+	}
+	if dec.AddressIndex != nil {
+		c.AddressIndex = *dec.AddressIndex
+	}
+	if dec.ContractIndex != nil {
+		c.ContractIndex = *dec.ContractIndex
+	}
+	if dec.SenderIndex != nil {
+		c.SenderIndex = *dec.SenderIndex
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -240,6 +295,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxPoolPolicy != nil {
+		c.TxPoolPolicy = *dec.TxPoolPolicy
+	}
+	if dec.TxBumper != nil {
+		c.TxBumper = *dec.TxBumper
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -258,11 +319,23 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCLogQueryLimit != nil {
+		c.RPCLogQueryLimit = *dec.RPCLogQueryLimit
+	}
+	if dec.RPCLogRangeLimit != nil {
+		c.RPCLogRangeLimit = *dec.RPCLogRangeLimit
+	}
 	if dec.OverrideCancun != nil {
 		c.OverrideCancun = dec.OverrideCancun
 	}
 	if dec.OverrideVerkle != nil {
 		c.OverrideVerkle = dec.OverrideVerkle
 	}
+	if dec.DevFork != nil {
+		c.DevFork = *dec.DevFork
+	}
+	if dec.DevForkBlock != nil {
+		c.DevForkBlock = dec.DevForkBlock
+	}
 	return nil
 }
@@ -18,9 +18,11 @@ package filters
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sync"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -183,6 +186,40 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 	return rpcSub, nil
 }
 
+// DroppedTransactions creates a subscription that is triggered each time a
+// transaction is dropped from the transaction pool without being included in
+// the chain, reporting the reason for its removal.
+func (api *FilterAPI) DroppedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan []*txpool.DroppedTx, 128)
+		dropTxSub := api.events.SubscribeDroppedTxs(drops)
+
+		for {
+			select {
+			case drops := <-drops:
+				for _, drop := range drops {
+					notifier.Notify(rpcSub.ID, drop)
+				}
+			case <-rpcSub.Err():
+				dropTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				dropTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *FilterAPI) NewBlockFilter() rpc.ID {
@@ -217,11 +254,16 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+// If a from block is given, the headers since that block are backfilled first and
+// reorgs of delivered headers are reported explicitly.
+func (api *FilterAPI) NewHeads(ctx context.Context, args *NewHeadsArgs) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if args != nil && backfills(args.FromBlock) {
+		return api.followHeads(ctx, notifier, args.FromBlock)
+	}
 
 	rpcSub := notifier.CreateSubscription()
 
@@ -247,11 +289,18 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+// If the criteria has a from block, the logs since that block are backfilled first and
+// reorgs of delivered blocks are reported explicitly instead of as removed logs.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if crit.FromBlock != nil {
+		if from := rpc.BlockNumber(crit.FromBlock.Int64()); backfills(&from) {
+			return api.followLogs(ctx, notifier, crit)
+		}
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
@@ -331,8 +380,23 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 	return logsSub.ID, nil
 }
 
+// LogsPageArgs are the optional pagination arguments of eth_getLogs.
+type LogsPageArgs struct {
+	Limit  hexutil.Uint64 `json:"limit"`  // Maximum number of logs to return
+	Cursor *LogCursor     `json:"cursor"` // Cursor returned by the previous page
+}
+
+// LogsPage is a page of logs returned by a paginated eth_getLogs query.
+type LogsPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *LogCursor   `json:"cursor"` // Start of the next page, nil if done
+}
+
 // GetLogs returns logs matching the given argument that are stored within the state.
-func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+//
+// If pagination arguments are given, at most the requested number of logs is
+// returned along with a cursor to retrieve the next page with.
+func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria, page *LogsPageArgs) (interface{}, error) {
 	var filter *Filter
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
@@ -350,6 +414,18 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
 	}
+	// Run the filter for a single page if requested
+	if page != nil {
+		limit := page.Limit
+		if limit > math.MaxInt {
+			limit = math.MaxInt
+		}
+		logs, cursor, err := filter.LogsPage(ctx, page.Cursor, int(limit))
+		if err != nil {
+			return nil, err
+		}
+		return &LogsPage{Logs: returnLogs(logs), Cursor: cursor}, nil
+	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
 	if err != nil {
@@ -375,6 +451,8 @@ func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 
 // GetFilterLogs returns the logs for the filter with the given id.
 // If the filter could not be found an empty array of logs is returned.
+//
+// The query is subject to the same result and block range limits as eth_getLogs.
 func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Log, error) {
 	api.filtersMu.Lock()
 	f, found := api.filters[id]
@@ -581,6 +659,28 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalText encodes the cursor as an opaque hex string.
+func (c LogCursor) MarshalText() ([]byte, error) {
+	enc := make([]byte, 12)
+	binary.BigEndian.PutUint64(enc, c.Number)
+	binary.BigEndian.PutUint32(enc[8:], uint32(c.Index))
+	return hexutil.Bytes(enc).MarshalText()
+}
+
+// UnmarshalText decodes a cursor encoded by MarshalText.
+func (c *LogCursor) UnmarshalText(input []byte) error {
+	var dec hexutil.Bytes
+	if err := dec.UnmarshalText(input); err != nil {
+		return err
+	}
+	if len(dec) != 12 {
+		return errors.New("invalid log cursor")
+	}
+	c.Number = binary.BigEndian.Uint64(dec)
+	c.Index = uint(binary.BigEndian.Uint32(dec[8:]))
+	return nil
+}
+
 func decodeAddress(s string) (common.Address, error) {
 	b, err := hexutil.Decode(s)
 	if err == nil && len(b) != common.AddressLength {
@@ -19,14 +19,34 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// LogCursor is the position of a log within the chain, marking where a paginated
+// log query continues.
+type LogCursor struct {
+	Number uint64 // Number of the block containing the log
+	Index  uint   // Index of the log within the block
+}
+
+// limitError is returned if a log query exceeds the limits enforced by the
+// filter system.
+type limitError struct{ msg string }
+
+func (e *limitError) Error() string  { return e.msg }
+func (e *limitError) ErrorCode() int { return -32005 }
+
+// logIndexBatchBlocks is the number of blocks looked up from the log index at once.
+const logIndexBatchBlocks = 16384
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	sys *FilterSystem
@@ -122,12 +142,51 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		return f.pendingLogs(), nil
 	}
 
+	// range query need to resolve the special begin/end block number
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, err
+	}
+	if limit := f.sys.cfg.LogRangeLimit; limit > 0 && f.end >= f.begin && uint64(f.end-f.begin) >= limit {
+		return nil, &limitError{fmt.Sprintf("block range too large, maximum is %d blocks", limit)}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logChan, errChan := f.rangeLogsAsync(ctx)
+	var logs []*types.Log
+	for {
+		select {
+		case log := <-logChan:
+			logs = append(logs, log)
+			if limit := f.sys.cfg.LogQueryLimit; limit > 0 && len(logs) > limit {
+				cancel()
+				drainLogs(logChan, errChan)
+				return nil, &limitError{fmt.Sprintf("query returned more than %d results", limit)}
+			}
+		case err := <-errChan:
+			if err != nil {
+				// if an error occurs during extraction, we do return the extracted data
+				return logs, err
+			}
+			// Append the pending ones
+			if endPending {
+				pendingLogs := f.pendingLogs()
+				logs = append(logs, pendingLogs...)
+			}
+			return logs, nil
+		}
+	}
+}
+
+// resolveRange converts the special block numbers bounding the range of the
+// filter into actual block numbers.
+func (f *Filter) resolveRange(ctx context.Context) error {
 	resolveSpecial := func(number int64) (int64, error) {
 		var hdr *types.Header
 		switch number {
 		case rpc.LatestBlockNumber.Int64(), rpc.PendingBlockNumber.Int64():
-			// we should return head here since we've already captured
-			// that we need to get the pending logs in the pending boolean above
+			// we should return head here since the pending logs are
+			// retrieved separately by the caller
 			hdr, _ = f.sys.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 			if hdr == nil {
 				return 0, errors.New("latest header not found")
@@ -147,33 +206,99 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		}
 		return hdr.Number.Int64(), nil
 	}
-
 	var err error
-	// range query need to resolve the special begin/end block number
 	if f.begin, err = resolveSpecial(f.begin); err != nil {
-		return nil, err
+		return err
 	}
 	if f.end, err = resolveSpecial(f.end); err != nil {
-		return nil, err
+		return err
+	}
+	return nil
+}
+
+// LogsPage searches the blockchain for at most limit matching log entries,
+// starting at the given cursor or at the beginning of the filter if the cursor
+// is nil. The returned cursor points to where the next page starts, or is nil
+// if all logs matching the filter have been retrieved.
+//
+// If the filter system has a log range limit, at most that many blocks are
+// searched per page, returning a cursor to the next unsearched block.
+func (f *Filter) LogsPage(ctx context.Context, cursor *LogCursor, limit int) ([]*types.Log, *LogCursor, error) {
+	if max := f.sys.cfg.LogQueryLimit; max > 0 && (limit <= 0 || limit > max) {
+		limit = max
 	}
+	if limit <= 0 {
+		return nil, nil, errors.New("page limit must be positive")
+	}
+	// Paginate the logs of a single block in memory
+	if f.block != nil {
+		logs, err := f.Logs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for len(logs) > 0 && cursor != nil && logs[0].Index < cursor.Index {
+			logs = logs[1:]
+		}
+		if len(logs) > limit {
+			return logs[:limit], &LogCursor{Number: logs[limit].BlockNumber, Index: logs[limit].Index}, nil
+		}
+		return logs, nil, nil
+	}
+	if f.begin == rpc.PendingBlockNumber.Int64() || f.end == rpc.PendingBlockNumber.Int64() {
+		return nil, nil, errors.New("pending logs cannot be paginated")
+	}
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		if cursor.Number > math.MaxInt64 || int64(cursor.Number) < f.begin || int64(cursor.Number) > f.end {
+			return nil, nil, errors.New("cursor outside of the block range")
+		}
+		f.begin = int64(cursor.Number)
+	}
+	end := f.end
+	if max := f.sys.cfg.LogRangeLimit; max > 0 && f.end >= f.begin && uint64(f.end-f.begin) >= max {
+		f.end = f.begin + int64(max) - 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	logChan, errChan := f.rangeLogsAsync(ctx)
 	var logs []*types.Log
 	for {
 		select {
 		case log := <-logChan:
+			if cursor != nil && log.BlockNumber == cursor.Number && log.Index < cursor.Index {
+				continue
+			}
+			if len(logs) == limit {
+				// Page full, stop the search at the first log not returned
+				cancel()
+				drainLogs(logChan, errChan)
+				return logs, &LogCursor{Number: log.BlockNumber, Index: log.Index}, nil
+			}
 			logs = append(logs, log)
+
 		case err := <-errChan:
 			if err != nil {
-				// if an error occurs during extraction, we do return the extracted data
-				return logs, err
+				return nil, nil, err
 			}
-			// Append the pending ones
-			if endPending {
-				pendingLogs := f.pendingLogs()
-				logs = append(logs, pendingLogs...)
+			if f.end < end {
+				return logs, &LogCursor{Number: uint64(f.end) + 1}, nil
 			}
-			return logs, nil
+			return logs, nil, nil
+		}
+	}
+}
+
+// drainLogs discards the results of an aborted log search, waiting for it to
+// terminate.
+func drainLogs(logChan chan *types.Log, errChan chan error) {
+	for {
+		select {
+		case <-logChan:
+		case <-errChan:
+			return
 		}
 	}
 }
@@ -192,27 +317,57 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
+		// Gather the logs segment by segment, preferring the log index, then the
+		// bloom bits and finishing with non indexed blocks
 		var (
 			end            = uint64(f.end)
 			size, sections = f.sys.backend.BloomStatus()
-			err            error
+			index          = f.sys.backend.LogIndex()
 		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
-			if indexed > end {
-				indexed = end + 1
+		if !logindex.Indexable(f.addresses, f.topics) {
+			index = nil
+		}
+		for f.begin <= int64(end) {
+			var (
+				begin               = f.begin
+				last                = end
+				tail, head, covered = uint64(0), uint64(0), false
+				err                 error
+			)
+			if index != nil {
+				tail, head, covered = index.Range()
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+			if covered && uint64(begin) >= tail && uint64(begin) <= head {
+				if head < last {
+					last = head
+				}
+				if err = f.logIndexLogs(ctx, index, last, logChan); err == nil && f.begin == begin {
+					// The index changed under the query, don't use it anymore
+					index = nil
+					continue
+				}
+			} else {
+				// Stop the segment where the log index takes over
+				if covered && tail > uint64(begin) && tail <= last {
+					last = tail - 1
+				}
+				if indexed := sections * size; indexed > uint64(begin) {
+					if indexed <= last {
+						last = indexed - 1
+					}
+					err = f.indexedLogs(ctx, last, logChan)
+				} else {
+					err = f.unindexedLogs(ctx, last, logChan)
+				}
+			}
+			if err != nil {
 				errChan <- err
 				return
 			}
+			if f.begin == begin {
+				break // Chain data missing, nothing more to retrieve
+			}
 		}
-
-		if err := f.unindexedLogs(ctx, end, logChan); err != nil {
-			errChan <- err
-			return
-		}
-
 		errChan <- nil
 	}()
 
@@ -265,6 +420,44 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 	}
 }
 
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index, which must cover the blocks up to end.
+func (f *Filter) logIndexLogs(ctx context.Context, index *logindex.Index, end uint64, logChan chan *types.Log) error {
+	for f.begin <= int64(end) {
+		last := uint64(f.begin) + logIndexBatchBlocks - 1
+		if last > end {
+			last = end
+		}
+		matches, err := index.Matches(ctx, uint64(f.begin), last, f.addresses, f.topics)
+		if errors.Is(err, logindex.ErrOutOfRange) {
+			return nil // Index pruned or rolled back meanwhile
+		}
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(match.Number))
+			if header == nil || err != nil {
+				return err
+			}
+			found, err := f.checkPositions(ctx, header, match.Positions)
+			if err != nil {
+				return err
+			}
+			f.begin = int64(match.Number) + 1
+			for _, log := range found {
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		f.begin = int64(last) + 1
+	}
+	return nil
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
@@ -310,9 +503,35 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 		return nil, err
 	}
 	logs := filterLogs(cached.logs, nil, nil, f.addresses, f.topics)
+	return f.deriveLogs(ctx, cached, header, logs)
+}
+
+// checkPositions returns the logs at the given positions within the block of the
+// header, as reported by the log index, which match the filter criteria.
+func (f *Filter) checkPositions(ctx context.Context, header *types.Header, positions []uint32) ([]*types.Log, error) {
+	cached, err := f.sys.cachedLogElem(ctx, header.Hash(), header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*types.Log, 0, len(positions))
+	for _, position := range positions {
+		if int(position) < len(cached.logs) {
+			logs = append(logs, cached.logs[position])
+		}
+	}
+	// Recheck the logs in case the block was reorged since the lookup
+	logs = filterLogs(logs, nil, nil, f.addresses, f.topics)
+	return f.deriveLogs(ctx, cached, header, logs)
+}
+
+// deriveLogs fills in the transaction hashes of the given logs taken from the
+// cache element of the block of the header.
+func (f *Filter) deriveLogs(ctx context.Context, cached *logCacheElem, header *types.Header, logs []*types.Log) ([]*types.Log, error) {
 	if len(logs) == 0 {
 		return nil, nil
 	}
+	hash := header.Hash()
+
 	// Most backends will deliver un-derived logs, but check nevertheless.
 	if len(logs) > 0 && logs[0].TxHash != (common.Hash{}) {
 		return logs, nil
@@ -32,7 +32,9 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -45,11 +47,14 @@ import (
 type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
+	logIndex        *logindex.Index
 	txFeed          event.Feed
+	dropTxsFeed     event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+	finalityFeed    event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
 }
@@ -133,6 +138,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Sub
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeDropTxsEvent(ch chan<- txpool.DropTxsEvent) event.Subscription {
+	return b.dropTxsFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -149,10 +158,18 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeFinalityEvent(ch chan<- core.FinalityEvent) event.Subscription {
+	return b.finalityFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) LogIndex() *logindex.Index {
+	return b.logIndex
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
@@ -301,6 +318,39 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestDroppedTxSubscription tests whether dropped transaction subscriptions
+// receive the drop events posted by the transaction pool.
+func TestDroppedTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		es           = NewEventSystem(sys, false)
+
+		replacement = common.HexToHash("0x02")
+		dropped     = []*txpool.DroppedTx{
+			{Hash: common.HexToHash("0x01"), Reason: txpool.DropReplaced, Replacement: &replacement},
+			{Hash: common.HexToHash("0x03"), Reason: txpool.DropUnderpriced},
+		}
+	)
+	drops := make(chan []*txpool.DroppedTx)
+	sub := es.SubscribeDroppedTxs(drops)
+	defer sub.Unsubscribe()
+
+	time.Sleep(100 * time.Millisecond)
+	backend.dropTxsFeed.Send(txpool.DropTxsEvent{Txs: dropped})
+
+	select {
+	case have := <-drops:
+		if !reflect.DeepEqual(have, dropped) {
+			t.Errorf("dropped transactions mismatch: have %v, want %v", have, dropped)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for dropped transactions")
+	}
+}
+
 // TestPendingTxFilterFullTx tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilterFullTx(t *testing.T) {
 	t.Parallel()
@@ -445,7 +495,7 @@ func TestInvalidGetLogsRequest(t *testing.T) {
 	}
 
 	for i, test := range testCases {
-		if _, err := api.GetLogs(context.Background(), test); err == nil {
+		if _, err := api.GetLogs(context.Background(), test, nil); err == nil {
 			t.Errorf("Expected Logs for case #%d to fail", i)
 		}
 	}
@@ -17,8 +17,10 @@
 package filters
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"math"
 	"math/big"
 	"strings"
 	"testing"
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -356,6 +359,50 @@ func TestFilters(t *testing.T) {
 		}
 	}
 
+	// Ensure queries served by the log index return the same logs as block
+	// iteration, including ranges partially covered by the index
+	index := logindex.New(db, bc, 900, false)
+	defer index.Close()
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if tail, head, ok := index.Range(); ok && tail == 101 && head == 1000 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("log index not built")
+		}
+	}
+	for i, query := range []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+	}{
+		{0, int64(rpc.LatestBlockNumber), []common.Address{contract}, [][]common.Hash{{hash1, hash2, hash3, hash4}}},
+		{900, 999, []common.Address{contract}, [][]common.Hash{{hash3}}},
+		{990, int64(rpc.LatestBlockNumber), []common.Address{contract2}, [][]common.Hash{{hash3}}},
+		{1, 10, []common.Address{contract}, [][]common.Hash{{hash2}, {hash1}}},
+		{0, int64(rpc.LatestBlockNumber), nil, [][]common.Hash{{hash1, hash2, hash3, hash4}}},
+		{0, int64(rpc.LatestBlockNumber), nil, [][]common.Hash{nil, {hash1}}},
+		{0, int64(rpc.LatestBlockNumber), []common.Address{contract, contract2}, nil},
+		{0, int64(rpc.LatestBlockNumber), nil, [][]common.Hash{{common.BytesToHash([]byte("fail"))}, {hash1}}},
+	} {
+		sys.backend.(*testBackend).logIndex = nil
+		want, err := sys.NewRangeFilter(query.begin, query.end, query.addresses, query.topics).Logs(context.Background())
+		if err != nil {
+			t.Fatalf("query %d: unindexed filtering failed: %v", i, err)
+		}
+		sys.backend.(*testBackend).logIndex = index
+		have, err := sys.NewRangeFilter(query.begin, query.end, query.addresses, query.topics).Logs(context.Background())
+		if err != nil {
+			t.Fatalf("query %d: indexed filtering failed: %v", i, err)
+		}
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if !bytes.Equal(haveJSON, wantJSON) {
+			t.Fatalf("query %d, have:\n%s\nwant:\n%s", i, haveJSON, wantJSON)
+		}
+	}
+	sys.backend.(*testBackend).logIndex = nil
+
 	t.Run("timeout", func(t *testing.T) {
 		f := sys.NewRangeFilter(0, -1, nil, nil)
 		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Hour))
@@ -369,3 +416,252 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+func TestFiltersPagination(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.LatestSigner(params.TestChainConfig)
+		logger  = common.Address{0xfe}
+		logCode = common.FromHex("0x60006000a060006000a060006000a000") // emits three logs
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				addr:   {Balance: big.NewInt(params.Ether)},
+				logger: {Balance: common.Big0, Code: logCode},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, err := gspec.Commit(db, trie.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, 20, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), logger, common.Big0, 100000, gen.BaseFee(), nil), signer, key)
+		gen.AddTx(tx)
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	// Page through all the logs and ensure they match the unpaginated query
+	_, sys := newTestFilterSystem(t, db, Config{})
+	want, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), []common.Address{logger}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 60 {
+		t.Fatalf("log count mismatch: have %d, want %d", len(want), 60)
+	}
+	var (
+		have   []*types.Log
+		cursor *LogCursor
+		pages  int
+	)
+	for pages = 1; ; pages++ {
+		logs, next, err := sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), []common.Address{logger}, nil).LogsPage(context.Background(), cursor, 7)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		if len(logs) > 7 {
+			t.Fatalf("page %d: too many logs: have %d, want at most %d", pages, len(logs), 7)
+		}
+		have = append(have, logs...)
+		if next == nil {
+			break
+		}
+		// Round trip the cursor through its textual form
+		enc, _ := next.MarshalText()
+		cursor = new(LogCursor)
+		if err := cursor.UnmarshalText(enc); err != nil {
+			t.Fatalf("page %d: invalid cursor %s: %v", pages, enc, err)
+		}
+	}
+	if pages != 9 {
+		t.Errorf("page count mismatch: have %d, want %d", pages, 9)
+	}
+	haveJSON, _ := json.Marshal(have)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(haveJSON, wantJSON) {
+		t.Fatalf("paginated logs mismatch, have:\n%s\nwant:\n%s", haveJSON, wantJSON)
+	}
+	// Ensure oversized page limits are capped instead of overflowing
+	res, err := NewFilterAPI(sys, false).GetLogs(context.Background(), FilterCriteria{Addresses: []common.Address{logger}, FromBlock: big.NewInt(0)}, &LogsPageArgs{Limit: math.MaxUint64})
+	if err != nil {
+		t.Fatalf("oversized page limit rejected: %v", err)
+	}
+	if page := res.(*LogsPage); len(page.Logs) != len(want) || page.Cursor != nil {
+		t.Errorf("oversized page mismatch: have %d logs, cursor %v, want %d logs", len(page.Logs), page.Cursor, len(want))
+	}
+	// Ensure the server enforced limits are applied
+	_, sys = newTestFilterSystem(t, db, Config{LogQueryLimit: 10, LogRangeLimit: 5})
+
+	if _, err := sys.NewRangeFilter(0, 4, nil, nil).Logs(context.Background()); err == nil || err.Error() != "query returned more than 10 results" {
+		t.Errorf("result limit error mismatch: have %v", err)
+	}
+	if _, err := sys.NewRangeFilter(0, 10, nil, nil).Logs(context.Background()); err == nil || err.Error() != "block range too large, maximum is 5 blocks" {
+		t.Errorf("range limit error mismatch: have %v", err)
+	}
+	logs, next, err := sys.NewRangeFilter(0, 10, nil, nil).LogsPage(context.Background(), nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 10 || next == nil || *next != (LogCursor{Number: 4, Index: 1}) {
+		t.Errorf("capped page mismatch: have %d logs, cursor %v", len(logs), next)
+	}
+	logs, next, err = sys.NewRangeFilter(0, 10, []common.Address{{0x01}}, nil).LogsPage(context.Background(), nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 0 || next == nil || *next != (LogCursor{Number: 5}) {
+		t.Errorf("empty page mismatch: have %d logs, cursor %v", len(logs), next)
+	}
+	// Ensure the limits also apply to installed filters
+	crit := FilterCriteria{FromBlock: big.NewInt(0), ToBlock: big.NewInt(10)}
+	api := NewFilterAPI(sys, false)
+
+	id, err := api.NewFilter(crit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetFilterLogs(context.Background(), id); err == nil || err.Error() != "block range too large, maximum is 5 blocks" {
+		t.Errorf("filter range limit error mismatch: have %v", err)
+	}
+}
+
+func TestTokenTransfers(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.TestChainConfig)
+		token  = common.Address{0xfe}
+		// emits an ERC-20 Transfer event with the topics and value taken from the call data
+		tokenCode = common.FromHex("0x60603560005260403560203560003560206000a300")
+		alice     = common.Address{0x01}
+		bob       = common.Address{0x02}
+		carol     = common.Address{0x03}
+		gspec     = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				addr:  {Balance: big.NewInt(params.Ether)},
+				token: {Balance: common.Big0, Code: tokenCode},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, err := gspec.Commit(db, trie.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every block transfers from alice to bob, then from bob to carol
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, 12, func(i int, gen *core.BlockGen) {
+		for _, transfer := range [][2]common.Address{{alice, bob}, {bob, carol}} {
+			var data []byte
+			data = append(data, logindex.TransferTopic.Bytes()...)
+			data = append(data, common.BytesToHash(transfer[0].Bytes()).Bytes()...)
+			data = append(data, common.BytesToHash(transfer[1].Bytes()).Bytes()...)
+			data = append(data, common.BigToHash(big.NewInt(int64(i+1))).Bytes()...)
+
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), token, common.Big0, 100000, gen.BaseFee(), data), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(chain[:10]); err != nil {
+		t.Fatal(err)
+	}
+	// Index the chain and stop following it, to leave later blocks unindexed
+	index := logindex.New(db, bc, 0, true)
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if tail, head, ok := index.Range(); ok && tail == 0 && head == 10 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("log index not built")
+		}
+	}
+	index.Close()
+
+	backend, sys := newTestFilterSystem(t, db, Config{})
+	backend.logIndex = index
+	api := NewFilterAPI(sys, false)
+
+	// Page through the transfers of bob, which are all of them
+	var (
+		have   []*TokenTransfer
+		cursor *LogCursor
+		pages  int
+	)
+	for pages = 1; ; pages++ {
+		page, err := api.GetTokenTransfersByHolder(context.Background(), bob, &TransferQueryArgs{Limit: 3, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		have = append(have, page.Transfers...)
+		if page.Cursor == nil {
+			break
+		}
+		cursor = page.Cursor
+	}
+	if pages != 7 || len(have) != 20 {
+		t.Fatalf("page count mismatch: have %d pages of %d transfers, want 7 pages of 20", pages, len(have))
+	}
+	for i, transfer := range have {
+		from, to := alice, bob
+		if i%2 == 1 {
+			from, to = bob, carol
+		}
+		if transfer.Standard != logindex.ERC20 || transfer.Token != token || transfer.From != from || transfer.To != to ||
+			transfer.Value.ToInt().Int64() != int64(i/2+1) || uint64(transfer.BlockNumber) != uint64(i/2+1) || uint64(transfer.LogIndex) != uint64(i%2) {
+			t.Fatalf("transfer %d mismatch: %+v", i, transfer)
+		}
+		if transfer.TransactionHash != chain[i/2].Transactions()[i%2].Hash() {
+			t.Fatalf("transfer %d transaction mismatch: have %x, want %x", i, transfer.TransactionHash, chain[i/2].Transactions()[i%2].Hash())
+		}
+	}
+	// Query the transfers of the token narrowed down to a holder and block range
+	from, to := rpc.BlockNumber(3), rpc.BlockNumber(5)
+	page, err := api.GetTokenTransfersByToken(context.Background(), token, &TransferQueryArgs{FromBlock: &from, ToBlock: &to, Holder: &carol})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Transfers) != 3 || page.Cursor != nil {
+		t.Fatalf("narrowed query mismatch: have %d transfers, cursor %v, want 3 transfers", len(page.Transfers), page.Cursor)
+	}
+	for i, transfer := range page.Transfers {
+		if transfer.To != carol || uint64(transfer.BlockNumber) != uint64(i+3) {
+			t.Fatalf("narrowed transfer %d mismatch: %+v", i, transfer)
+		}
+	}
+	// Extend the chain past the index and ensure the unindexed blocks are left
+	// for later pages instead of being dropped
+	if _, err := bc.InsertChain(chain[10:]); err != nil {
+		t.Fatal(err)
+	}
+	from = rpc.BlockNumber(9)
+	page, err = api.GetTokenTransfersByHolder(context.Background(), bob, &TransferQueryArgs{FromBlock: &from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Transfers) != 4 || page.Cursor == nil || *page.Cursor != (LogCursor{Number: 11}) {
+		t.Fatalf("lagging index query mismatch: have %d transfers, cursor %v, want 4 transfers, cursor at 11", len(page.Transfers), page.Cursor)
+	}
+	page, err = api.GetTokenTransfersByHolder(context.Background(), bob, &TransferQueryArgs{FromBlock: &from, Cursor: page.Cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Transfers) != 0 || page.Cursor == nil || *page.Cursor != (LogCursor{Number: 11}) {
+		t.Fatalf("unindexed page mismatch: have %d transfers, cursor %v, want none, cursor at 11", len(page.Transfers), page.Cursor)
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxReorgDepth is the maximum number of delivered blocks a backfilling
+// subscription walks back to find the common ancestor of a reorg.
+const maxReorgDepth = 1024
+
+// maxBackfillRange is the maximum number of blocks a subscription may backfill
+// before switching to new blocks. The log range limit applies if lower.
+const maxBackfillRange = 10000
+
+// NewHeadsArgs are the optional arguments of a newHeads subscription.
+type NewHeadsArgs struct {
+	FromBlock *rpc.BlockNumber `json:"fromBlock"` // First block to deliver, only new heads if nil
+}
+
+// ReorgNotification is sent by subscriptions backfilling from a given block if
+// previously delivered blocks are no longer canonical. All data delivered for the removed blocks is
+// invalid, the blocks of the new chain are delivered after the notification.
+type ReorgNotification struct {
+	Type      string         `json:"type"`      // Always "reorg"
+	FromBlock hexutil.Uint64 `json:"fromBlock"` // First removed block
+	ToBlock   hexutil.Uint64 `json:"toBlock"`   // Last removed block
+	OldHashes []common.Hash  `json:"oldHashes"` // Hashes of the removed blocks, ascending
+	NewHashes []common.Hash  `json:"newHashes"` // Hashes of the new blocks at the same heights, if any
+}
+
+// chainFollower walks the canonical chain block by block from a starting point,
+// detecting when blocks it already delivered get reorged out.
+type chainFollower struct {
+	backend Backend
+	next    uint64        // Number of the next block to deliver
+	last    *types.Header // Last delivered block, nil if nothing was delivered yet
+}
+
+// newChainFollower creates a chain follower starting at the given block. Special
+// block numbers are resolved to the block following them, so "latest" starts at
+// the next new head. The range to backfill is capped at maxRange blocks, unless
+// it is zero.
+func newChainFollower(ctx context.Context, backend Backend, from *rpc.BlockNumber, maxRange uint64) (*chainFollower, error) {
+	head, err := backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, errors.New("latest header not found")
+	}
+	f := &chainFollower{backend: backend, next: head.Number.Uint64() + 1}
+	if from == nil || *from == rpc.LatestBlockNumber || *from == rpc.PendingBlockNumber {
+		f.last = head
+		return f, nil
+	}
+	if *from < 0 {
+		header, err := backend.HeaderByNumber(ctx, *from)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("%s header not found", *from)
+		}
+		f.next, f.last = header.Number.Uint64()+1, header
+	} else {
+		f.next = uint64(*from)
+	}
+	if maxRange > 0 && f.next+maxRange <= head.Number.Uint64() {
+		return nil, &limitError{fmt.Sprintf("block range too large, maximum is %d blocks", maxRange)}
+	}
+	return f, nil
+}
+
+// follow delivers the canonical blocks from the next undelivered one up to the
+// current head of the chain, reporting any reorg of the already delivered blocks
+// first. It returns when the head is reached or a callback fails.
+func (f *chainFollower) follow(ctx context.Context, deliver func(*types.Header) error, reorg func(*ReorgNotification) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Report and rewind any reorg of the delivered blocks
+		if f.last != nil {
+			canon, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.last.Number.Int64()))
+			if err != nil {
+				return err
+			}
+			if canon == nil || canon.Hash() != f.last.Hash() {
+				notification, err := f.rewind(ctx)
+				if err != nil {
+					return err
+				}
+				if notification != nil {
+					if err := reorg(notification); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+		// Deliver the next block if it's available
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.next))
+		if err != nil || header == nil {
+			return err
+		}
+		if f.last != nil && header.ParentHash != f.last.Hash() {
+			return nil // Reorg in progress, retry on the next head
+		}
+		if err := deliver(header); err != nil {
+			return err
+		}
+		f.next, f.last = f.next+1, header
+	}
+}
+
+// rewind walks back the delivered blocks until reaching one which is still
+// canonical, returning the reorg notification of the removed blocks. If the chain
+// switched back in the meantime and nothing was removed, it returns nil.
+func (f *chainFollower) rewind(ctx context.Context) (*ReorgNotification, error) {
+	var (
+		removed []*types.Header
+		header  = f.last
+	)
+	for header != nil && len(removed) < maxReorgDepth {
+		canon, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Int64()))
+		if err != nil {
+			return nil, err
+		}
+		if canon != nil && canon.Hash() == header.Hash() {
+			break
+		}
+		removed = append(removed, header)
+		if header.Number.Sign() == 0 {
+			header = nil
+			break
+		}
+		if header, err = f.backend.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if len(removed) == maxReorgDepth {
+		header = nil // Ancestor too deep, restart after the removed blocks
+	}
+	first := removed[len(removed)-1].Number.Uint64()
+	notification := &ReorgNotification{
+		Type:      "reorg",
+		FromBlock: hexutil.Uint64(first),
+		ToBlock:   hexutil.Uint64(f.last.Number.Uint64()),
+		OldHashes: make([]common.Hash, 0, len(removed)),
+		NewHashes: make([]common.Hash, 0, len(removed)),
+	}
+	for i := len(removed) - 1; i >= 0; i-- {
+		notification.OldHashes = append(notification.OldHashes, removed[i].Hash())
+	}
+	for number := first; number <= f.last.Number.Uint64(); number++ {
+		canon, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if canon == nil {
+			break
+		}
+		notification.NewHashes = append(notification.NewHashes, canon.Hash())
+	}
+	f.next, f.last = first, header
+	return notification, nil
+}
+
+// followChain runs a backfilling subscription, delivering the canonical blocks
+// through the given callback and notifying reorgs, until the subscription ends.
+func (api *FilterAPI) followChain(notifier *rpc.Notifier, rpcSub *rpc.Subscription, f *chainFollower, deliver func(context.Context, *types.Header) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Wake up the follower on new heads, without blocking the event system
+	var (
+		headers = make(chan *types.Header)
+		wake    = make(chan struct{}, 1)
+	)
+	headersSub := api.events.SubscribeNewHeads(headers)
+	defer headersSub.Unsubscribe()
+
+	go func() {
+		for {
+			select {
+			case <-headers:
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	// Abort any backfill in progress if the subscription ends
+	go func() {
+		select {
+		case <-rpcSub.Err():
+		case <-notifier.Closed():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	reorg := func(notification *ReorgNotification) error {
+		return notifier.Notify(rpcSub.ID, notification)
+	}
+	for {
+		if err := f.follow(ctx, func(header *types.Header) error { return deliver(ctx, header) }, reorg); err != nil {
+			return
+		}
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// backfills returns whether a subscription starting at the given block needs to
+// backfill history. Subscriptions starting at the latest or pending block only
+// deliver new blocks, as they always did.
+func backfills(from *rpc.BlockNumber) bool {
+	return from != nil && *from != rpc.LatestBlockNumber && *from != rpc.PendingBlockNumber
+}
+
+// backfillRange returns the maximum number of blocks a subscription may backfill.
+func (api *FilterAPI) backfillRange() uint64 {
+	if limit := api.sys.cfg.LogRangeLimit; limit > 0 && limit < maxBackfillRange {
+		return limit
+	}
+	return maxBackfillRange
+}
+
+// followHeads runs a newHeads subscription delivering every canonical header in
+// order, starting at the given block to backfill the history before switching
+// to new heads. If previously delivered blocks are reorged, a reorg notification
+// is sent before delivering the new canonical headers.
+func (api *FilterAPI) followHeads(ctx context.Context, notifier *rpc.Notifier, from *rpc.BlockNumber) (*rpc.Subscription, error) {
+	f, err := newChainFollower(ctx, api.sys.backend, from, api.backfillRange())
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go api.followChain(notifier, rpcSub, f, func(ctx context.Context, header *types.Header) error {
+		return notifier.Notify(rpcSub.ID, header)
+	})
+	return rpcSub, nil
+}
+
+// followLogs runs a logs subscription delivering the logs matching the given
+// criteria for every canonical block in order, starting at the from block of
+// the criteria to backfill the history before switching to new blocks. If
+// previously delivered blocks are reorged, a reorg notification is sent before
+// delivering the logs of the new canonical blocks.
+func (api *FilterAPI) followLogs(ctx context.Context, notifier *rpc.Notifier, crit FilterCriteria) (*rpc.Subscription, error) {
+	if crit.BlockHash != nil {
+		return nil, errors.New("block hash criteria not supported by subscriptions")
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Int64() != rpc.LatestBlockNumber.Int64() {
+		return nil, errors.New("subscriptions can't have a to block")
+	}
+	from := rpc.BlockNumber(crit.FromBlock.Int64())
+	f, err := newChainFollower(ctx, api.sys.backend, &from, api.backfillRange())
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go api.followChain(notifier, rpcSub, f, func(ctx context.Context, header *types.Header) error {
+		logs, err := api.sys.NewBlockFilter(header.Hash(), crit.Addresses, crit.Topics).Logs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if err := notifier.Notify(rpcSub.ID, log); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return rpcSub, nil
+}
+
+// SafeHeads creates a subscription delivering the header of the safe block each
+// time it changes, starting with the current one.
+func (api *FilterAPI) SafeHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, rpc.SafeBlockNumber, func(ev core.FinalityEvent) *types.Header { return ev.Safe })
+}
+
+// FinalizedHeads creates a subscription delivering the header of the finalized
+// block each time it changes, starting with the current one.
+func (api *FilterAPI) FinalizedHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, rpc.FinalizedBlockNumber, func(ev core.FinalityEvent) *types.Header { return ev.Finalized })
+}
+
+// finalityHeads creates a subscription delivering the header of the safe or
+// finalized block, as selected by the given block number and event accessor.
+func (api *FilterAPI) finalityHeads(ctx context.Context, number rpc.BlockNumber, pick func(core.FinalityEvent) *types.Header) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	events := make(chan core.FinalityEvent, 10)
+	eventsSub := api.sys.backend.SubscribeFinalityEvent(events)
+
+	// The current block might not be known, ignore errors
+	current, _ := api.sys.backend.HeaderByNumber(ctx, number)
+
+	go func() {
+		defer eventsSub.Unsubscribe()
+
+		var last common.Hash
+		if current != nil {
+			notifier.Notify(rpcSub.ID, current)
+			last = current.Hash()
+		}
+		for {
+			select {
+			case ev := <-events:
+				if header := pick(ev); header != nil && header.Hash() != last {
+					notifier.Notify(rpcSub.ID, header)
+					last = header.Hash()
+				}
+			case <-eventsSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
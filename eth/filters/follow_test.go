@@ -0,0 +1,183 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// writeTestHeaders writes a canonical header chain of n blocks on top of the
+// given parent, using extra to distinguish forks.
+func writeTestHeaders(db ethdb.Database, parent *types.Header, n int, extra byte) []*types.Header {
+	var headers []*types.Header
+	for i := 0; i < n; i++ {
+		header := &types.Header{Number: big.NewInt(0), Extra: []byte{extra}}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+			header.Number = new(big.Int).Add(parent.Number, common.Big1)
+		}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+		rawdb.WriteHeadBlockHash(db, header.Hash())
+		headers = append(headers, header)
+		parent = header
+	}
+	return headers
+}
+
+// Tests that the chain follower backfills the requested blocks, reports reorgs
+// of delivered blocks and continues on the new chain.
+func TestChainFollower(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db         = rawdb.NewMemoryDatabase()
+		backend, _ = newTestFilterSystem(t, db, Config{})
+		chain      = writeTestHeaders(db, nil, 6, 0)
+		from       = rpc.BlockNumber(2)
+	)
+	f, err := newChainFollower(context.Background(), backend, &from, 0)
+	if err != nil {
+		t.Fatalf("failed to create follower: %v", err)
+	}
+	var (
+		delivered []common.Hash
+		reorgs    []*ReorgNotification
+	)
+	deliver := func(header *types.Header) error {
+		delivered = append(delivered, header.Hash())
+		return nil
+	}
+	reorg := func(notification *ReorgNotification) error {
+		reorgs = append(reorgs, notification)
+		return nil
+	}
+	if err := f.follow(context.Background(), deliver, reorg); err != nil {
+		t.Fatalf("failed to follow chain: %v", err)
+	}
+	want := []common.Hash{chain[2].Hash(), chain[3].Hash(), chain[4].Hash(), chain[5].Hash()}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Fatalf("delivered blocks mismatch: have %x, want %x", delivered, want)
+	}
+	// Reorg the chain from block 4 onwards to a longer fork
+	delivered = nil
+	fork := writeTestHeaders(db, chain[3], 3, 1)
+
+	if err := f.follow(context.Background(), deliver, reorg); err != nil {
+		t.Fatalf("failed to follow chain: %v", err)
+	}
+	wantReorg := &ReorgNotification{
+		Type:      "reorg",
+		FromBlock: 4,
+		ToBlock:   5,
+		OldHashes: []common.Hash{chain[4].Hash(), chain[5].Hash()},
+		NewHashes: []common.Hash{fork[0].Hash(), fork[1].Hash()},
+	}
+	if len(reorgs) != 1 || !reflect.DeepEqual(reorgs[0], wantReorg) {
+		t.Fatalf("reorg notifications mismatch: have %v, want %v", reorgs, wantReorg)
+	}
+	want = []common.Hash{fork[0].Hash(), fork[1].Hash(), fork[2].Hash()}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Fatalf("delivered blocks mismatch: have %x, want %x", delivered, want)
+	}
+	// Rewinding after the chain switched back to the delivered blocks is a noop
+	if notification, err := f.rewind(context.Background()); notification != nil || err != nil {
+		t.Fatalf("unexpected rewind of canonical blocks: %v, %v", notification, err)
+	}
+}
+
+// Tests that backfilling more blocks than the configured range limit is rejected.
+func TestChainFollowerRangeLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db         = rawdb.NewMemoryDatabase()
+		backend, _ = newTestFilterSystem(t, db, Config{})
+		_          = writeTestHeaders(db, nil, 10, 0)
+	)
+	from := rpc.BlockNumber(1)
+	if _, err := newChainFollower(context.Background(), backend, &from, 4); err == nil {
+		t.Fatal("expected range limit error")
+	}
+	from = rpc.BlockNumber(6)
+	if _, err := newChainFollower(context.Background(), backend, &from, 4); err != nil {
+		t.Fatalf("failed to create follower within limit: %v", err)
+	}
+}
+
+// Tests that the backfill range of subscriptions is capped by default and by the
+// log range limit if that is lower.
+func TestBackfillRange(t *testing.T) {
+	t.Parallel()
+
+	_, sys := newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{})
+	if have := NewFilterAPI(sys, false).backfillRange(); have != maxBackfillRange {
+		t.Errorf("default backfill range mismatch: have %d, want %d", have, maxBackfillRange)
+	}
+	_, sys = newTestFilterSystem(t, rawdb.NewMemoryDatabase(), Config{LogRangeLimit: 4})
+	if have := NewFilterAPI(sys, false).backfillRange(); have != 4 {
+		t.Errorf("limited backfill range mismatch: have %d, want %d", have, 4)
+	}
+}
+
+// Tests that newHeads subscriptions with a from block backfill the history.
+func TestNewHeadsBackfill(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+		chain  = writeTestHeaders(db, nil, 6, 0)
+	)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false)); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	headers := make(chan *types.Header)
+	sub, err := client.EthSubscribe(context.Background(), headers, "newHeads", map[string]interface{}{"fromBlock": "0x2"})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for _, want := range chain[2:] {
+		select {
+		case header := <-headers:
+			if header.Hash() != want.Hash() {
+				t.Fatalf("header mismatch: have #%d %x, want #%d %x", header.Number, header.Hash(), want.Number, want.Hash())
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for header #%d", want.Number)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/logindex"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxTransfersPage is the maximum number of token transfers returned by a single
+// page, unless the log query limit is lower.
+const maxTransfersPage = 1000
+
+// TransferQueryArgs are the optional arguments of a token transfer query.
+type TransferQueryArgs struct {
+	FromBlock *rpc.BlockNumber `json:"fromBlock"` // Oldest block to search, the index tail if nil
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`   // Newest block to search, the latest if nil
+	Token     *common.Address  `json:"token"`     // Token to narrow holder queries down to
+	Holder    *common.Address  `json:"holder"`    // Holder to narrow token queries down to
+	Limit     hexutil.Uint64   `json:"limit"`     // Maximum number of transfers to return
+	Cursor    *LogCursor       `json:"cursor"`    // Cursor returned by the previous page
+}
+
+// TokenTransfer is a decoded token transfer event.
+type TokenTransfer struct {
+	Standard         string          `json:"standard"`
+	Token            common.Address  `json:"token"`
+	Operator         *common.Address `json:"operator,omitempty"`
+	From             common.Address  `json:"from"`
+	To               common.Address  `json:"to"`
+	TokenID          *hexutil.Big    `json:"tokenId,omitempty"`
+	Value            *hexutil.Big    `json:"value,omitempty"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        common.Hash     `json:"blockHash"`
+	TransactionHash  common.Hash     `json:"transactionHash"`
+	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+	LogIndex         hexutil.Uint    `json:"logIndex"`
+}
+
+// TransfersPage is a page of token transfers.
+type TransfersPage struct {
+	Transfers []*TokenTransfer `json:"transfers"`
+	Cursor    *LogCursor       `json:"cursor"` // Start of the next page, nil if done
+}
+
+// GetTokenTransfersByToken returns the ERC-20, ERC-721 and ERC-1155 transfers of
+// the given token contract in ascending chain order, a page at a time.
+//
+// The query is limited to the blocks covered by the token transfer index, with
+// a cursor pointing past its head if it has not caught up with the range yet.
+func (api *FilterAPI) GetTokenTransfersByToken(ctx context.Context, token common.Address, args *TransferQueryArgs) (*TransfersPage, error) {
+	if args == nil {
+		args = new(TransferQueryArgs)
+	}
+	return api.tokenTransfers(ctx, &token, args.Holder, args)
+}
+
+// GetTokenTransfersByHolder returns the ERC-20, ERC-721 and ERC-1155 transfers
+// sending tokens from or to the given holder in ascending chain order, a page at
+// a time.
+//
+// The query is limited to the blocks covered by the token transfer index, with
+// a cursor pointing past its head if it has not caught up with the range yet.
+func (api *FilterAPI) GetTokenTransfersByHolder(ctx context.Context, holder common.Address, args *TransferQueryArgs) (*TransfersPage, error) {
+	if args == nil {
+		args = new(TransferQueryArgs)
+	}
+	return api.tokenTransfers(ctx, args.Token, &holder, args)
+}
+
+// tokenTransfers looks up a page of the token transfers of the given token and
+// holder from the log index. The transfers of a batch event are never split up
+// between pages, so a page may exceed the limit to include all of them. If the
+// index is behind the end of the queried range, the returned cursor points to
+// the first block not indexed yet, so the query can be resumed once it is.
+func (api *FilterAPI) tokenTransfers(ctx context.Context, token *common.Address, holder *common.Address, args *TransferQueryArgs) (*TransfersPage, error) {
+	index := api.sys.backend.LogIndex()
+	if index == nil {
+		return nil, logindex.ErrNoTransfers
+	}
+	tail, head, ok := index.Range()
+	if !ok {
+		return nil, logindex.ErrOutOfRange
+	}
+	// Resolve the block range
+	begin, end := int64(tail), rpc.LatestBlockNumber.Int64()
+	if args.FromBlock != nil {
+		begin = args.FromBlock.Int64()
+	}
+	if args.ToBlock != nil {
+		end = args.ToBlock.Int64()
+	}
+	if begin == rpc.PendingBlockNumber.Int64() || end == rpc.PendingBlockNumber.Int64() {
+		return nil, errors.New("pending transfers cannot be queried")
+	}
+	filter := api.sys.NewRangeFilter(begin, end, nil, nil)
+	if err := filter.resolveRange(ctx); err != nil {
+		return nil, err
+	}
+	if filter.begin < 0 || filter.end < filter.begin {
+		return nil, errors.New("invalid block range")
+	}
+	from, to := uint64(filter.begin), uint64(filter.end)
+	if from < tail {
+		return nil, fmt.Errorf("blocks before %d are not indexed", tail)
+	}
+	cursor := args.Cursor
+	if cursor != nil {
+		if cursor.Number < from || cursor.Number > to {
+			return nil, errors.New("cursor outside of the block range")
+		}
+		from = cursor.Number
+	}
+	limit := uint64(args.Limit)
+	if limit == 0 || limit > maxTransfersPage {
+		limit = maxTransfersPage
+	}
+	if max := api.sys.cfg.LogQueryLimit; max > 0 && limit > uint64(max) {
+		limit = uint64(max)
+	}
+	// Search only the blocks indexed so far, the rest is left for later pages
+	last := to
+	if last > head {
+		last = head
+	}
+	if max := api.sys.cfg.LogRangeLimit; max > 0 && last >= from && last-from >= max {
+		last = from + max - 1
+	}
+	// Collect the transfers from the matching logs
+	page := &TransfersPage{Transfers: []*TokenTransfer{}}
+	for from <= last {
+		batchEnd := from + logIndexBatchBlocks - 1
+		if batchEnd > last {
+			batchEnd = last
+		}
+		matches, err := index.Transfers(ctx, from, batchEnd, token, holder)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(match.Number))
+			if header == nil || err != nil {
+				return nil, fmt.Errorf("header %d not found: %v", match.Number, err)
+			}
+			cached, err := api.sys.cachedLogElem(ctx, header.Hash(), match.Number)
+			if err != nil {
+				return nil, err
+			}
+			for _, position := range match.Positions {
+				if int(position) >= len(cached.logs) {
+					continue
+				}
+				log := cached.logs[position]
+				if cursor != nil && log.BlockNumber == cursor.Number && log.Index < cursor.Index {
+					continue
+				}
+				// Recheck the transfers in case the block was reorged since the lookup
+				var found []*TokenTransfer
+				for _, transfer := range logindex.DecodeTransfers(log) {
+					if (token != nil && transfer.Token != *token) || (holder != nil && transfer.From != *holder && transfer.To != *holder) {
+						continue
+					}
+					found = append(found, newTokenTransfer(&transfer))
+				}
+				if len(found) == 0 {
+					continue
+				}
+				if uint64(len(page.Transfers)) >= limit {
+					// Page full, stop at the first event not returned
+					page.Cursor = &LogCursor{Number: log.BlockNumber, Index: log.Index}
+					return page, nil
+				}
+				logs, err := (&Filter{sys: api.sys}).deriveLogs(ctx, cached, header, []*types.Log{log})
+				if err != nil {
+					return nil, err
+				}
+				for _, transfer := range found {
+					transfer.BlockNumber = hexutil.Uint64(logs[0].BlockNumber)
+					transfer.BlockHash = logs[0].BlockHash
+					transfer.TransactionHash = logs[0].TxHash
+					transfer.TransactionIndex = hexutil.Uint(logs[0].TxIndex)
+					transfer.LogIndex = hexutil.Uint(logs[0].Index)
+				}
+				page.Transfers = append(page.Transfers, found...)
+			}
+		}
+		from = batchEnd + 1
+	}
+	// Range limit or index head reached before the end of the queried range,
+	// point the cursor to the first block not searched yet
+	if last < to {
+		if cursor != nil && cursor.Number == from {
+			page.Cursor = cursor // nothing searched, index still behind the cursor
+		} else {
+			page.Cursor = &LogCursor{Number: from}
+		}
+	}
+	return page, nil
+}
+
+// newTokenTransfer converts a decoded transfer into its RPC representation,
+// without the position of its event within the chain.
+func newTokenTransfer(transfer *logindex.Transfer) *TokenTransfer {
+	result := &TokenTransfer{
+		Standard: transfer.Standard,
+		Token:    transfer.Token,
+		From:     transfer.From,
+		To:       transfer.To,
+	}
+	if transfer.Standard == logindex.ERC1155 {
+		operator := transfer.Operator
+		result.Operator = &operator
+	}
+	if transfer.ID != nil {
+		result.TokenID = (*hexutil.Big)(transfer.ID)
+	}
+	if transfer.Value != nil {
+		result.Value = (*hexutil.Big)(transfer.Value)
+	}
+	return result
+}
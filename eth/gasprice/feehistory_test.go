@@ -22,6 +22,8 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -61,7 +63,7 @@ func TestFeeHistory(t *testing.T) {
 		backend := newTestBackend(t, big.NewInt(16), c.pending)
 		oracle := NewOracle(backend, config)
 
-		first, reward, baseFee, ratio, err := oracle.FeeHistory(context.Background(), c.count, c.last, c.percent)
+		first, reward, baseFee, ratio, blobBaseFee, blobRatio, err := oracle.FeeHistory(context.Background(), c.count, c.last, c.percent)
 		backend.teardown()
 		expReward := c.expCount
 		if len(c.percent) == 0 {
@@ -84,8 +86,68 @@ func TestFeeHistory(t *testing.T) {
 		if len(ratio) != c.expCount {
 			t.Fatalf("Test case %d: gasUsedRatio array length mismatch, want %d, got %d", i, c.expCount, len(ratio))
 		}
+		if len(blobBaseFee) != expBaseFee {
+			t.Fatalf("Test case %d: blobBaseFee array length mismatch, want %d, got %d", i, expBaseFee, len(blobBaseFee))
+		}
+		if len(blobRatio) != c.expCount {
+			t.Fatalf("Test case %d: blobGasUsedRatio array length mismatch, want %d, got %d", i, c.expCount, len(blobRatio))
+		}
 		if err != c.expErr && !errors.Is(err, c.expErr) {
 			t.Fatalf("Test case %d: error mismatch, want %v, got %v", i, c.expErr, err)
 		}
+		// The test chain is pre-Cancun, so all blob fields must be zero
+		for j, fee := range blobBaseFee {
+			if fee.Sign() != 0 {
+				t.Fatalf("Test case %d: blobBaseFee %d mismatch, want 0, got %v", i, j, fee)
+			}
+		}
+		for j, r := range blobRatio {
+			if r != 0 {
+				t.Fatalf("Test case %d: blobGasUsedRatio %d mismatch, want 0, got %v", i, j, r)
+			}
+		}
 	}
 }
+
+func TestFeeHistoryBlobFees(t *testing.T) {
+	var cases = []struct {
+		excessBlobGas, blobGasUsed *uint64
+		expBlobBaseFee             int64
+		expNextBlobBaseFee         int64
+		expBlobRatio               float64
+	}{
+		{nil, nil, 0, 0, 0},                   // Pre-Cancun
+		{newUint64(0), newUint64(0), 1, 1, 0}, // Empty block at minimum fee
+		{newUint64(0), newUint64(params.BlobTxTargetBlobGasPerBlock), 1, 1, 0.5}, // Target usage keeps the excess
+		{newUint64(10000000), newUint64(params.MaxBlobGasPerBlock), 89, 100, 1},  // Full block raises the fee
+		{newUint64(10000000), newUint64(0), 89, 79, 0},                           // Empty block lowers the fee
+	}
+	backend := newTestBackend(t, big.NewInt(16), false)
+	defer backend.teardown()
+	oracle := NewOracle(backend, Config{})
+
+	for i, c := range cases {
+		bf := &blockFees{
+			blockNumber: 20,
+			header: &types.Header{
+				Number:        big.NewInt(20),
+				GasLimit:      params.TxGas * 2,
+				BaseFee:       big.NewInt(params.InitialBaseFee),
+				ExcessBlobGas: c.excessBlobGas,
+				BlobGasUsed:   c.blobGasUsed,
+			},
+		}
+		oracle.processBlock(bf, nil)
+		if bf.results.blobBaseFee.Int64() != c.expBlobBaseFee {
+			t.Errorf("Test case %d: blobBaseFee mismatch, want %d, got %v", i, c.expBlobBaseFee, bf.results.blobBaseFee)
+		}
+		if bf.results.nextBlobBaseFee.Int64() != c.expNextBlobBaseFee {
+			t.Errorf("Test case %d: nextBlobBaseFee mismatch, want %d, got %v", i, c.expNextBlobBaseFee, bf.results.nextBlobBaseFee)
+		}
+		if bf.results.blobGasUsedRatio != c.expBlobRatio {
+			t.Errorf("Test case %d: blobGasUsedRatio mismatch, want %v, got %v", i, c.expBlobRatio, bf.results.blobGasUsedRatio)
+		}
+	}
+}
+
+func newUint64(val uint64) *uint64 { return &val }
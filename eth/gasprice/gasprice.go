@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
@@ -70,6 +71,10 @@ type Oracle struct {
 	cacheLock   sync.RWMutex
 	fetchLock   sync.Mutex
 
+	lastTiersHead common.Hash // Head block the last fee tiers were suggested for
+	lastTiers     *FeeTiers   // Last fee tiers, reused until the head changes
+	tiersLock     sync.Mutex  // Lock serializing fee tier suggestions
+
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory uint64
 
@@ -141,6 +146,20 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 	}
 }
 
+// BlobBaseFee returns the base fee per blob gas of the block following the current
+// head, derived from the head's excess blob gas and blob gas usage. Nil is returned
+// if the head is not yet a Cancun block.
+func (oracle *Oracle) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if head.ExcessBlobGas == nil || head.BlobGasUsed == nil {
+		return nil, nil
+	}
+	return eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*head.ExcessBlobGas, *head.BlobGasUsed)), nil
+}
+
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
 // very high chance to be included in the following blocks.
 //
@@ -0,0 +1,282 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// tierPercentiles are the fee history reward percentiles used as the historical
+	// baseline for the slow, standard and fast tiers. The first one also acts as
+	// the inclusion floor of a block when estimating inclusion probabilities.
+	tierPercentiles = []float64{10, 50, 90}
+
+	// tierDepths are the pending pool depths, in units of the block gas limit,
+	// within which the slow, standard and fast tiers aim to be placed.
+	tierDepths = []float64{2, 1, 0.5}
+)
+
+// PendingTx is the minimal information about a pending pool transaction needed
+// to estimate the competition for the block space of the next blocks.
+type PendingTx struct {
+	GasFeeCap *big.Int // Maximum fee per gas the transaction may consume
+	GasTipCap *big.Int // Maximum miner tip per gas the transaction can pay
+	Gas       uint64   // Amount of gas required by the transaction
+}
+
+// FeeTier is a single fee recommendation along with the estimated probability
+// of a transaction paying it being included in the next block.
+type FeeTier struct {
+	TipCap      *big.Int // Suggested maximum priority fee per gas
+	FeeCap      *big.Int // Suggested maximum fee per gas
+	Probability float64  // Estimated probability of inclusion in the next block
+}
+
+// FeeTiers is a set of slow, standard and fast fee recommendations.
+type FeeTiers struct {
+	BaseFee  *big.Int // Base fee of the next block (nil before London)
+	Slow     FeeTier
+	Standard FeeTier
+	Fast     FeeTier
+}
+
+// SuggestFeeTiers returns slow, standard and fast fee recommendations, derived
+// from the priority fees paid in recent blocks and raised to outbid enough of
+// the pending transactions returned by the given callback to fit into the next
+// blocks.
+//
+// The inclusion probability of each tier is the ratio of recent blocks the tier
+// would have made it into, discounted by the amount of pending gas paying more
+// than the tier's tip (which would be included first).
+//
+// The tiers are only recalculated once per head block, so the pending pool is
+// retrieved at most once per block, no matter how often the tiers are requested.
+func (oracle *Oracle) SuggestFeeTiers(ctx context.Context, pending func() []PendingTx) (*FeeTiers, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	headHash := head.Hash()
+
+	// If the tiers were already suggested for the current head, return them
+	oracle.cacheLock.RLock()
+	lastHead, lastTiers := oracle.lastTiersHead, oracle.lastTiers
+	oracle.cacheLock.RUnlock()
+	if headHash == lastHead && lastTiers != nil {
+		return lastTiers.copy(), nil
+	}
+	oracle.tiersLock.Lock()
+	defer oracle.tiersLock.Unlock()
+
+	// Try checking the cache again, maybe the last suggestion was what we need
+	oracle.cacheLock.RLock()
+	lastHead, lastTiers = oracle.lastTiersHead, oracle.lastTiers
+	oracle.cacheLock.RUnlock()
+	if headHash == lastHead && lastTiers != nil {
+		return lastTiers.copy(), nil
+	}
+	var txs []PendingTx
+	if pending != nil {
+		txs = pending()
+	}
+	tiers, err := oracle.suggestFeeTiers(ctx, head, txs)
+	if err != nil {
+		return nil, err
+	}
+	oracle.cacheLock.Lock()
+	oracle.lastTiersHead, oracle.lastTiers = headHash, tiers
+	oracle.cacheLock.Unlock()
+
+	return tiers.copy(), nil
+}
+
+// suggestFeeTiers calculates the fee tiers on top of the given head block.
+func (oracle *Oracle) suggestFeeTiers(ctx context.Context, head *types.Header, pending []PendingTx) (*FeeTiers, error) {
+	config := oracle.backend.ChainConfig()
+
+	var baseFee *big.Int
+	if config.IsLondon(new(big.Int).Add(head.Number, common.Big1)) {
+		baseFee = eip1559.CalcBaseFee(config, head)
+	}
+	// Gather the historical tips of all non-empty recent blocks
+	_, rewards, _, ratios, _, _, err := oracle.FeeHistory(ctx, uint64(oracle.checkBlocks), rpc.LatestBlockNumber, tierPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	var samples [][]*big.Int
+	for i, reward := range rewards {
+		if ratios[i] > 0 && reward != nil {
+			samples = append(samples, reward)
+		}
+	}
+	tips := make([]*big.Int, len(tierPercentiles))
+	if len(samples) == 0 {
+		// No recent transactions to learn from, fall back to the default tip
+		tip, err := oracle.SuggestTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range tips {
+			tips[i] = tip
+		}
+	} else {
+		for i := range tips {
+			tips[i] = medianTip(samples, i)
+		}
+	}
+	// Order the pending transactions by their effective tip in the next block and
+	// raise the tiers to outbid enough of them to fit within their target depth
+	queue := effectiveTips(pending, baseFee)
+	for i, depth := range tierDepths {
+		if tip := queue.tipAt(uint64(depth * float64(head.GasLimit))); tip != nil && tip.Cmp(tips[i]) > 0 {
+			tips[i] = tip
+		}
+	}
+	for i := 1; i < len(tips); i++ {
+		tips[i] = math.BigMax(tips[i], tips[i-1])
+	}
+	// Assemble the tiers, capping the tips to the configured maximum
+	tiers := make([]FeeTier, len(tips))
+	for i, tip := range tips {
+		if oracle.maxPrice != nil && tip.Cmp(oracle.maxPrice) > 0 {
+			tip = new(big.Int).Set(oracle.maxPrice)
+		}
+		tiers[i] = FeeTier{
+			TipCap:      tip,
+			FeeCap:      tip,
+			Probability: inclusionProbability(samples, tip, queue.gasAbove(tip), head.GasLimit),
+		}
+		if baseFee != nil {
+			// Set the max fee to be 2 times larger than the next block's base fee,
+			// allowing the tx to not become invalidated if the base fee is rising.
+			tiers[i].FeeCap = new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+		}
+	}
+	return &FeeTiers{
+		BaseFee:  baseFee,
+		Slow:     tiers[0],
+		Standard: tiers[1],
+		Fast:     tiers[2],
+	}, nil
+}
+
+// copy returns a deep copy of the fee tiers.
+func (t *FeeTiers) copy() *FeeTiers {
+	cpy := *t
+	if t.BaseFee != nil {
+		cpy.BaseFee = new(big.Int).Set(t.BaseFee)
+	}
+	for _, tier := range []*FeeTier{&cpy.Slow, &cpy.Standard, &cpy.Fast} {
+		tier.TipCap = new(big.Int).Set(tier.TipCap)
+		tier.FeeCap = new(big.Int).Set(tier.FeeCap)
+	}
+	return &cpy
+}
+
+// medianTip returns the median of the index-th reward percentile across a set
+// of fee history samples.
+func medianTip(samples [][]*big.Int, index int) *big.Int {
+	tips := make([]*big.Int, len(samples))
+	for i, sample := range samples {
+		tips[i] = sample[index]
+	}
+	slices.SortFunc(tips, func(a, b *big.Int) int { return a.Cmp(b) })
+	return new(big.Int).Set(tips[len(tips)/2])
+}
+
+// inclusionProbability estimates the probability of a transaction paying the
+// given tip being included in the next block.
+func inclusionProbability(samples [][]*big.Int, tip *big.Int, ahead uint64, gasLimit uint64) float64 {
+	// Start with the ratio of recent blocks whose inclusion floor the tip meets
+	probability := 1.0
+	if len(samples) > 0 {
+		var hits int
+		for _, sample := range samples {
+			if tip.Cmp(sample[0]) >= 0 {
+				hits++
+			}
+		}
+		probability = float64(hits) / float64(len(samples))
+	}
+	// Discount by the block space consumed by better paying pending transactions
+	if gasLimit > 0 {
+		probability *= float64(gasLimit) / float64(gasLimit+ahead)
+	}
+	return probability
+}
+
+// pendingTip is a pending transaction's effective tip and gas requirement.
+type pendingTip struct {
+	tip *big.Int
+	gas uint64
+}
+
+// pendingQueue is a set of pending transactions sorted by effective tip in a
+// descending order.
+type pendingQueue []pendingTip
+
+// effectiveTips sorts the pending transactions by the tip they would pay in the
+// next block, dropping the ones that are not executable there.
+func effectiveTips(pending []PendingTx, baseFee *big.Int) pendingQueue {
+	queue := make(pendingQueue, 0, len(pending))
+	for _, tx := range pending {
+		tip := tx.GasTipCap
+		if baseFee != nil {
+			if tx.GasFeeCap.Cmp(baseFee) < 0 {
+				continue
+			}
+			tip = math.BigMin(tip, new(big.Int).Sub(tx.GasFeeCap, baseFee))
+		}
+		queue = append(queue, pendingTip{tip: tip, gas: tx.Gas})
+	}
+	slices.SortStableFunc(queue, func(a, b pendingTip) int { return b.tip.Cmp(a.tip) })
+	return queue
+}
+
+// tipAt returns the tip needed to outbid the pending transactions beyond the
+// given cumulative gas depth, or nil if the pool is shallower than that.
+func (q pendingQueue) tipAt(depth uint64) *big.Int {
+	var gas uint64
+	for _, tx := range q {
+		if gas += tx.gas; gas > depth {
+			return new(big.Int).Add(tx.tip, common.Big1)
+		}
+	}
+	return nil
+}
+
+// gasAbove returns the cumulative gas of pending transactions paying strictly
+// more than the given tip.
+func (q pendingQueue) gasAbove(tip *big.Int) uint64 {
+	var gas uint64
+	for _, tx := range q {
+		if tx.tip.Cmp(tip) <= 0 {
+			break
+		}
+		gas += tx.gas
+	}
+	return gas
+}
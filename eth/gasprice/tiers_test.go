@@ -0,0 +1,119 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that fee tiers are ordered, derived from the recent history and raised
+// to outbid a congested pending pool.
+func TestSuggestFeeTiers(t *testing.T) {
+	backend := newTestBackend(t, big.NewInt(16), false)
+	defer backend.teardown()
+
+	oracle := NewOracle(backend, Config{Blocks: 20, Percentile: 60, MaxHeaderHistory: 1000, MaxBlockHistory: 1000})
+
+	// Without any pending transactions, the tiers should follow the history
+	tiers, err := oracle.SuggestFeeTiers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to suggest fee tiers: %v", err)
+	}
+	checkTiers(t, tiers)
+
+	// The chain has a single transaction per block with increasing tips, so the
+	// median tip would have made it into about half of the sampled blocks
+	if tiers.Standard.Probability < 0.5 || tiers.Standard.Probability > 0.6 {
+		t.Errorf("uncongested standard tier probability mismatch: have %v, want ~0.55", tiers.Standard.Probability)
+	}
+	// Fill the pool with two blocks worth of well paying transactions and ensure
+	// the standard and fast tiers outbid them, but the slow one doesn't
+	var (
+		gasLimit = backend.chain.CurrentBlock().GasLimit
+		tip      = big.NewInt(100 * params.GWei)
+		pending  []PendingTx
+	)
+	for i := 0; i < 4; i++ {
+		pending = append(pending, PendingTx{
+			GasFeeCap: new(big.Int).Add(tip, big.NewInt(100*params.GWei)),
+			GasTipCap: tip,
+			Gas:       gasLimit / 2,
+		})
+	}
+	// The tiers are cached for the head block, so the pool is not looked at again
+	var calls int
+	cached, err := oracle.SuggestFeeTiers(context.Background(), func() []PendingTx {
+		calls++
+		return pending
+	})
+	if err != nil {
+		t.Fatalf("failed to suggest cached fee tiers: %v", err)
+	}
+	if calls != 0 || cached.Standard.TipCap.Cmp(tiers.Standard.TipCap) != 0 {
+		t.Errorf("cached tiers mismatch: pool retrieved %d times, standard tip %v, want %v", calls, cached.Standard.TipCap, tiers.Standard.TipCap)
+	}
+	// Query a fresh oracle, which does look at the congested pool
+	oracle = NewOracle(backend, Config{Blocks: 20, Percentile: 60, MaxHeaderHistory: 1000, MaxBlockHistory: 1000})
+	congested, err := oracle.SuggestFeeTiers(context.Background(), func() []PendingTx {
+		calls++
+		return pending
+	})
+	if err != nil {
+		t.Fatalf("failed to suggest congested fee tiers: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("pool retrieval count mismatch: have %d, want %d", calls, 1)
+	}
+	checkTiers(t, congested)
+
+	if congested.Standard.TipCap.Cmp(tip) <= 0 {
+		t.Errorf("standard tier not outbidding pool: have %v, want > %v", congested.Standard.TipCap, tip)
+	}
+	if congested.Slow.TipCap.Cmp(tiers.Slow.TipCap) != 0 {
+		t.Errorf("slow tier changed by pool: have %v, want %v", congested.Slow.TipCap, tiers.Slow.TipCap)
+	}
+	if congested.Slow.Probability >= tiers.Slow.Probability {
+		t.Errorf("slow tier probability not discounted: have %v, uncongested %v", congested.Slow.Probability, tiers.Slow.Probability)
+	}
+}
+
+// checkTiers verifies the internal consistency of a set of fee tiers.
+func checkTiers(t *testing.T, tiers *FeeTiers) {
+	t.Helper()
+
+	if tiers.BaseFee == nil {
+		t.Fatalf("missing base fee")
+	}
+	for i, tier := range []FeeTier{tiers.Slow, tiers.Standard, tiers.Fast} {
+		if want := new(big.Int).Add(tier.TipCap, new(big.Int).Mul(tiers.BaseFee, big.NewInt(2))); tier.FeeCap.Cmp(want) != 0 {
+			t.Errorf("tier %d: fee cap mismatch: have %v, want %v", i, tier.FeeCap, want)
+		}
+		if tier.Probability < 0 || tier.Probability > 1 {
+			t.Errorf("tier %d: probability out of bounds: %v", i, tier.Probability)
+		}
+	}
+	if tiers.Slow.TipCap.Cmp(tiers.Standard.TipCap) > 0 || tiers.Standard.TipCap.Cmp(tiers.Fast.TipCap) > 0 {
+		t.Errorf("tiers not ordered: %v, %v, %v", tiers.Slow.TipCap, tiers.Standard.TipCap, tiers.Fast.TipCap)
+	}
+	if tiers.Slow.Probability > tiers.Standard.Probability || tiers.Standard.Probability > tiers.Fast.Probability {
+		t.Errorf("probabilities not ordered: %v, %v, %v", tiers.Slow.Probability, tiers.Standard.Probability, tiers.Fast.Probability)
+	}
+}
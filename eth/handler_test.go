@@ -91,6 +91,12 @@ func (p *testTxPool) Add(txs []*types.Transaction, local bool, sync bool) []erro
 	return make([]error, len(txs))
 }
 
+// AddFromPeer appends a batch of remote transactions to the pool, ignoring the
+// originating peer.
+func (p *testTxPool) AddFromPeer(peer string, txs []*types.Transaction) []error {
+	return p.Add(txs, false, false)
+}
+
 // Pending returns all the transactions known to the pool
 func (p *testTxPool) Pending(enforceTips bool) map[common.Address][]*txpool.LazyTransaction {
 	p.lock.RLock()
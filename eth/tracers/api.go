@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"sync"
@@ -430,37 +431,37 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 
 // TraceBlockByNumber returns the structured logs created during the execution of
 // EVM and returns them as a JSON object.
-func (api *API) TraceBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) ([]*txTraceResult, error) {
+func (api *API) TraceBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) (rpc.StreamedResult, error) {
 	block, err := api.blockByNumber(ctx, number)
 	if err != nil {
 		return nil, err
 	}
-	return api.traceBlock(ctx, block, config)
+	return api.streamBlockTrace(ctx, block, config)
 }
 
 // TraceBlockByHash returns the structured logs created during the execution of
 // EVM and returns them as a JSON object.
-func (api *API) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+func (api *API) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) (rpc.StreamedResult, error) {
 	block, err := api.blockByHash(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
-	return api.traceBlock(ctx, block, config)
+	return api.streamBlockTrace(ctx, block, config)
 }
 
 // TraceBlock returns the structured logs created during the execution of EVM
 // and returns them as a JSON object.
-func (api *API) TraceBlock(ctx context.Context, blob hexutil.Bytes, config *TraceConfig) ([]*txTraceResult, error) {
+func (api *API) TraceBlock(ctx context.Context, blob hexutil.Bytes, config *TraceConfig) (rpc.StreamedResult, error) {
 	block := new(types.Block)
 	if err := rlp.DecodeBytes(blob, block); err != nil {
 		return nil, fmt.Errorf("could not decode block: %v", err)
 	}
-	return api.traceBlock(ctx, block, config)
+	return api.streamBlockTrace(ctx, block, config)
 }
 
 // TraceBlockFromFile returns the structured logs created during the execution of
 // EVM and returns them as a JSON object.
-func (api *API) TraceBlockFromFile(ctx context.Context, file string, config *TraceConfig) ([]*txTraceResult, error) {
+func (api *API) TraceBlockFromFile(ctx context.Context, file string, config *TraceConfig) (rpc.StreamedResult, error) {
 	blob, err := os.ReadFile(file)
 	if err != nil {
 		return nil, fmt.Errorf("could not read file: %v", err)
@@ -471,12 +472,12 @@ func (api *API) TraceBlockFromFile(ctx context.Context, file string, config *Tra
 // TraceBadBlock returns the structured logs created during the execution of
 // EVM against a block pulled from the pool of bad ones and returns them as a JSON
 // object.
-func (api *API) TraceBadBlock(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+func (api *API) TraceBadBlock(ctx context.Context, hash common.Hash, config *TraceConfig) (rpc.StreamedResult, error) {
 	block := rawdb.ReadBadBlock(api.backend.ChainDb(), hash)
 	if block == nil {
 		return nil, fmt.Errorf("bad block %#x not found", hash)
 	}
-	return api.traceBlock(ctx, block, config)
+	return api.streamBlockTrace(ctx, block, config)
 }
 
 // StandardTraceBlockToFile dumps the structured logs created during the
@@ -563,10 +564,48 @@ func (api *API) StandardTraceBadBlockToFile(ctx context.Context, hash common.Has
 	return api.standardTraceBlockToFile(ctx, block, config)
 }
 
-// traceBlock configures a new tracer according to the provided configuration, and
-// executes all the transactions contained within. The return value will be one item
-// per transaction, dependent on the requested tracer.
-func (api *API) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
+// blockTraceStream holds the results of the transactions of a block traced ahead
+// of the client reading them.
+type blockTraceStream struct {
+	results []chan *txTraceResult // Result of each transaction, delivered once
+	slots   chan struct{}         // Tokens bounding the transactions traced ahead
+	failed  chan struct{}         // Closed if tracing is aborted
+	err     error                 // Reason of the abort, set before failed is closed
+	cancel  context.CancelFunc    // Aborts tracing the remaining transactions
+	index   int                   // Index of the next result to return
+}
+
+// next returns the result of the next transaction, waiting for it to be traced.
+func (s *blockTraceStream) next() (interface{}, error) {
+	if s.index == len(s.results) {
+		s.cancel()
+		return nil, io.EOF
+	}
+	select {
+	case res := <-s.results[s.index]:
+		s.results[s.index] = nil
+		s.index++
+		<-s.slots
+		return res, nil
+	case <-s.failed:
+		s.cancel()
+		return nil, s.err
+	}
+}
+
+// fail aborts the stream. It's called at most once, by the thread generating the
+// states of the transactions.
+func (s *blockTraceStream) fail(err error) {
+	s.err = err
+	close(s.failed)
+}
+
+// streamBlockTrace configures a new tracer according to the provided configuration,
+// and returns the per-transaction results of the block as a result streamed to the
+// client. Transactions are traced lazily, only a few ahead of the results written,
+// so each result is released once written instead of all of them being held in
+// memory together. Tracing is aborted when the context is canceled.
+func (api *API) streamBlockTrace(ctx context.Context, block *types.Block, config *TraceConfig) (rpc.StreamedResult, error) {
 	if block.NumberU64() == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
@@ -583,121 +622,129 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if err != nil {
 		return nil, err
 	}
-	defer release()
-
-	// JS tracers have high overhead. In this case run a parallel
-	// process that generates states in one thread and traces txes
-	// in separate worker threads.
+	// JS tracers have high overhead. In this case trace several transactions ahead
+	// in separate worker threads, while generating their states in one thread.
+	threads := 1
 	if config != nil && config.Tracer != nil && *config.Tracer != "" {
 		if isJS := DefaultDirectory.IsJS(*config.Tracer); isJS {
-			return api.traceBlockParallel(ctx, block, statedb, config)
+			threads = runtime.NumCPU()
 		}
 	}
-	// Native tracers have low overhead
-	var (
-		txs       = block.Transactions()
-		blockHash = block.Hash()
-		is158     = api.backend.ChainConfig().IsEIP158(block.Number())
-		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
-		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
-		results   = make([]*txTraceResult, len(txs))
-	)
-	for i, tx := range txs {
-		// Generate the next state snapshot fast without tracing
-		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
-		txctx := &Context{
-			BlockHash:   blockHash,
-			BlockNumber: block.Number(),
-			TxIndex:     i,
-			TxHash:      tx.Hash(),
-		}
-		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
-		if err != nil {
-			return nil, err
-		}
-		results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
-		// Finalize the state so any modifications are written to the trie
-		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(is158)
+	txs := block.Transactions()
+	if threads > len(txs) {
+		threads = len(txs)
+	}
+	stream := &blockTraceStream{
+		results: make([]chan *txTraceResult, len(txs)),
+		slots:   make(chan struct{}, threads+1),
+		failed:  make(chan struct{}),
+	}
+	for i := range stream.results {
+		stream.results[i] = make(chan *txTraceResult, 1)
+	}
+	ctx, stream.cancel = context.WithCancel(ctx)
+	go func() {
+		defer release()
+		api.traceBlockAhead(ctx, block, statedb, config, threads, stream)
+	}()
+
+	// Wait for the first result, so that errors affecting all transactions (e.g.
+	// an invalid tracer) are reported as the error of the call
+	first, err := stream.next()
+	if err != nil && err != io.EOF {
+		return nil, err
 	}
-	return results, nil
+	return rpc.StreamArray(func() (interface{}, error) {
+		if first != nil {
+			res := first
+			first = nil
+			return res, nil
+		}
+		return stream.next()
+	}), nil
 }
 
-// traceBlockParallel is for tracers that have a high overhead (read JS tracers). One thread
-// runs along and executes txes without tracing enabled to generate their prestate.
-// Worker threads take the tasks and the prestate and trace them.
-func (api *API) traceBlockParallel(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
-	// Execute all the transaction contained within the block concurrently
+// traceBlockAhead executes the transactions of the block and delivers their traces
+// to the stream, waiting whenever the client falls behind. With more than one
+// thread, transactions are executed without tracing to generate the prestates of
+// the next ones, and the traces are done by separate worker threads.
+func (api *API) traceBlockAhead(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig, threads int, stream *blockTraceStream) {
 	var (
 		txs       = block.Transactions()
 		blockHash = block.Hash()
+		is158     = api.backend.ChainConfig().IsEIP158(block.Number())
 		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
-		results   = make([]*txTraceResult, len(txs))
+		parallel  = threads > 1
+		jobs      = make(chan *txTraceTask, threads)
 		pend      sync.WaitGroup
 	)
-	threads := runtime.NumCPU()
-	if threads > len(txs) {
-		threads = len(txs)
-	}
-	jobs := make(chan *txTraceTask, threads)
-	for th := 0; th < threads; th++ {
-		pend.Add(1)
-		go func() {
-			defer pend.Done()
-			// Fetch and execute the next transaction trace tasks
-			for task := range jobs {
-				msg, _ := core.TransactionToMessage(txs[task.index], signer, block.BaseFee())
-				txctx := &Context{
-					BlockHash:   blockHash,
-					BlockNumber: block.Number(),
-					TxIndex:     task.index,
-					TxHash:      txs[task.index].Hash(),
-				}
-				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
-				if err != nil {
-					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
-					continue
+	// The workers need to be done with their states before the base state is released
+	defer pend.Wait()
+	defer close(jobs)
+
+	if parallel {
+		for th := 0; th < threads; th++ {
+			pend.Add(1)
+			go func() {
+				defer pend.Done()
+				// Fetch and execute the next transaction trace tasks
+				for task := range jobs {
+					msg, _ := core.TransactionToMessage(txs[task.index], signer, block.BaseFee())
+					txctx := &Context{
+						BlockHash:   blockHash,
+						BlockNumber: block.Number(),
+						TxIndex:     task.index,
+						TxHash:      txs[task.index].Hash(),
+					}
+					res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
+					if err != nil {
+						stream.results[task.index] <- &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
+						continue
+					}
+					stream.results[task.index] <- &txTraceResult{TxHash: txs[task.index].Hash(), Result: res}
 				}
-				results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Result: res}
-			}
-		}()
+			}()
+		}
 	}
-
-	// Feed the transactions into the tracers and return
-	var failed error
-txloop:
 	for i, tx := range txs {
-		// Send the trace task over for execution
-		task := &txTraceTask{statedb: statedb.Copy(), index: i}
+		// Wait for the client to catch up with the transactions traced ahead
 		select {
+		case stream.slots <- struct{}{}:
 		case <-ctx.Done():
-			failed = ctx.Err()
-			break txloop
-		case jobs <- task:
+			stream.fail(ctx.Err())
+			return
 		}
-
-		// Generate the next state snapshot fast without tracing
 		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
-		statedb.SetTxContext(tx.Hash(), i)
-		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
-		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
-			failed = err
-			break txloop
+		if parallel {
+			// Send the trace task over for execution, and generate the next state
+			// snapshot fast without tracing
+			jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
+
+			statedb.SetTxContext(tx.Hash(), i)
+			vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
+			if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+				stream.fail(err)
+				return
+			}
+		} else {
+			txctx := &Context{
+				BlockHash:   blockHash,
+				BlockNumber: block.Number(),
+				TxIndex:     i,
+				TxHash:      tx.Hash(),
+			}
+			res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
+			if err != nil {
+				stream.fail(err)
+				return
+			}
+			stream.results[i] <- &txTraceResult{TxHash: tx.Hash(), Result: res}
 		}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
-	}
-
-	close(jobs)
-	pend.Wait()
-
-	// If execution failed in between, abort
-	if failed != nil {
-		return nil, failed
+		statedb.Finalise(is158)
 	}
-	return results, nil
 }
 
 // standardTraceBlockToFile configures a new tracer which uses standard JSON output,
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"runtime"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -451,6 +452,86 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// countingTracer is a struct logger counting the results produced.
+type countingTracer struct {
+	*logger.StructLogger
+	count *atomic.Int32
+}
+
+func (t *countingTracer) GetResult() (json.RawMessage, error) {
+	t.count.Add(1)
+	return t.StructLogger.GetResult()
+}
+
+// Tests that the transactions of a block are traced lazily, only a few ahead of
+// the results written to the client.
+func TestTraceBlockStreaming(t *testing.T) {
+	// Not parallel, as it registers tracers in the default directory
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		threads = runtime.NumCPU()
+		genTxs  = 2*threads + 8
+		signer  = types.HomesteadSigner{}
+	)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		for j := 0; j < genTxs; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(j), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	defer backend.chain.Stop()
+	var refs, rels atomic.Int32
+	backend.refHook = func() { refs.Add(1) }
+	backend.relHook = func() { rels.Add(1) }
+	api := NewAPI(backend)
+
+	var traced atomic.Int32
+	ctor := func(ctx *Context, cfg json.RawMessage) (Tracer, error) {
+		return &countingTracer{StructLogger: logger.NewStructLogger(nil), count: &traced}, nil
+	}
+	DefaultDirectory.Register("countingTracer", ctor, false)
+	DefaultDirectory.Register("countingTracerJS", ctor, true)
+
+	for name, ahead := range map[string]int{"countingTracer": 3, "countingTracerJS": threads + 2} {
+		name := name
+		traced.Store(0)
+
+		result, err := api.TraceBlockByNumber(context.Background(), 1, &TraceConfig{Tracer: &name})
+		if err != nil {
+			t.Fatalf("%s: failed to trace block: %v", name, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if n := int(traced.Load()); n > ahead {
+			t.Errorf("%s: too many transactions traced ahead: have %d, want at most %d", name, n, ahead)
+		}
+		enc, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("%s: failed to stream result: %v", name, err)
+		}
+		var results []*txTraceResult
+		if err := json.Unmarshal(enc, &results); err != nil {
+			t.Fatalf("%s: invalid result: %v", name, err)
+		}
+		if len(results) != genTxs || int(traced.Load()) != genTxs {
+			t.Fatalf("%s: result count mismatch: have %d (%d traced), want %d", name, len(results), traced.Load(), genTxs)
+		}
+		for i, res := range results {
+			if res.Error != "" || res.TxHash != backend.chain.GetBlockByNumber(1).Transactions()[i].Hash() {
+				t.Fatalf("%s: result %d mismatch: %v", name, i, res)
+			}
+		}
+	}
+	// The base states are released once the streams are done
+	time.Sleep(100 * time.Millisecond)
+	if refs.Load() != rels.Load() {
+		t.Errorf("states not released: %d referenced, %d released", refs.Load(), rels.Load())
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
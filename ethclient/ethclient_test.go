@@ -519,8 +519,16 @@ func testStatusFunctions(t *testing.T, client *rpc.Client) {
 			big.NewInt(765625000),
 			big.NewInt(671627818),
 		},
-		GasUsedRatio: []float64{0.008912678667376286},
+		GasUsedRatio:     []float64{0.008912678667376286},
+		BlobGasUsedRatio: []float64{0},
 	}
+	// Blob base fees are zero pre-Cancun, but the decoded big ints are not deep
+	// equal to freshly allocated ones
+	if len(history.BlobBaseFee) != 2 || history.BlobBaseFee[0].Sign() != 0 || history.BlobBaseFee[1].Sign() != 0 {
+		t.Fatalf("unexpected blob base fees: %v", history.BlobBaseFee)
+	}
+	want.BlobBaseFee = history.BlobBaseFee
+
 	if !reflect.DeepEqual(history, want) {
 		t.Fatalf("FeeHistory result doesn't match expected: (got: %v, want: %v)", history, want)
 	}
@@ -570,6 +578,13 @@ func testCallContract(t *testing.T, client *rpc.Client) {
 	if gas != 21000 {
 		t.Fatalf("unexpected gas price: %v", gas)
 	}
+	gas, err = ec.EstimateGasAtBlock(context.Background(), msg, big.NewInt(int64(rpc.PendingBlockNumber)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gas != 21000 {
+		t.Fatalf("unexpected pending gas estimate: %v", gas)
+	}
 	// CallContract
 	if _, err := ec.CallContract(context.Background(), msg, big.NewInt(1)); err != nil {
 		t.Fatalf("unexpected error: %v", err)
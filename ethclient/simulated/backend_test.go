@@ -0,0 +1,366 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+func simTestBackend(testAddr common.Address) *Backend {
+	return NewBackend(
+		core.GenesisAlloc{
+			testAddr: {Balance: big.NewInt(10000000000000000)},
+		},
+	)
+}
+
+// newTx creates a signed value transfer from the test account to a fixed
+// recipient with the next pending nonce.
+func newTx(sim *Backend, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	client := sim.Client()
+
+	// create a signed transaction to send
+	head, _ := client.HeaderByNumber(context.Background(), nil) // Should be child's, good enough
+	gasPrice := new(big.Int).Add(head.BaseFee, big.NewInt(params.GWei))
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	chainid, _ := client.ChainID(context.Background())
+	nonce, err := client.PendingNonceAt(context.Background(), addr)
+	if err != nil {
+		return nil, err
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainid,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: gasPrice,
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     big.NewInt(1),
+	})
+	return types.SignTx(tx, types.LatestSignerForChainID(chainid), key)
+}
+
+func TestNewBackend(t *testing.T) {
+	sim := NewBackend(core.GenesisAlloc{})
+	defer sim.Close()
+
+	client := sim.Client()
+	num, err := client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 0 {
+		t.Fatalf("expected 0 got %v", num)
+	}
+	// Create a block
+	sim.Commit()
+	num, err = client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num != 1 {
+		t.Fatalf("expected 1 got %v", num)
+	}
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chainID.Cmp(params.AllDevChainProtocolChanges.ChainID) != 0 {
+		t.Fatalf("chain id mismatch: have %v, want %v", chainID, params.AllDevChainProtocolChanges.ChainID)
+	}
+}
+
+func TestSendTransaction(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client := sim.Client()
+	ctx := context.Background()
+
+	signedTx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Errorf("could not create transaction: %v", err)
+	}
+	// send tx to simulated backend
+	err = client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		t.Errorf("could not add tx to pending block: %v", err)
+	}
+	sim.Commit()
+	block, err := client.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil {
+		t.Errorf("could not get block at height 1: %v", err)
+	}
+	if signedTx.Hash() != block.Transactions()[0].Hash() {
+		t.Errorf("did not commit sent transaction. expected hash %v got hash %v", block.Transactions()[0].Hash(), signedTx.Hash())
+	}
+	receipt, err := client.TransactionReceipt(ctx, signedTx.Hash())
+	if err != nil {
+		t.Fatalf("could not get receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.BlockNumber.Uint64() != 1 {
+		t.Errorf("receipt mismatch: status %d, block %v", receipt.Status, receipt.BlockNumber)
+	}
+}
+
+// Tests that pending transactions can be rolled back before being committed.
+func TestRollback(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client := sim.Client()
+	ctx := context.Background()
+
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatalf("could not create transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+	sim.Rollback()
+
+	if nonce, err := client.PendingNonceAt(ctx, testAddr); err != nil || nonce != 0 {
+		t.Fatalf("pending transactions not rolled back: nonce %d, %v", nonce, err)
+	}
+	sim.Commit()
+	block, err := client.BlockByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("could not get head block: %v", err)
+	}
+	if len(block.Transactions()) != 0 {
+		t.Fatalf("rolled back transaction included")
+	}
+}
+
+// Tests that the chain can be forked off of an ancestor, dropping the blocks
+// after it and building a new side chain.
+func TestFork(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client := sim.Client()
+	ctx := context.Background()
+
+	parent := sim.Commit()
+	for i := 0; i < 3; i++ {
+		sim.Commit()
+	}
+	if err := sim.Fork(parent); err != nil {
+		t.Fatalf("failed to fork: %v", err)
+	}
+	if num, _ := client.BlockNumber(ctx); num != 1 {
+		t.Fatalf("head mismatch after fork: have %d, want 1", num)
+	}
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatalf("could not create transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+	if err := sim.Fork(parent); err == nil {
+		t.Fatalf("forked with pending transactions")
+	}
+	head := sim.Commit()
+	block, err := client.BlockByHash(ctx, head)
+	if err != nil {
+		t.Fatalf("could not get head block: %v", err)
+	}
+	if block.NumberU64() != 2 || block.ParentHash() != parent || len(block.Transactions()) != 1 {
+		t.Fatalf("side chain block mismatch: number %d, parent %x, txs %d", block.NumberU64(), block.ParentHash(), len(block.Transactions()))
+	}
+}
+
+func TestAdjustTime(t *testing.T) {
+	sim := NewBackend(core.GenesisAlloc{})
+	defer sim.Close()
+
+	client := sim.Client()
+	block1, _ := client.BlockByNumber(context.Background(), nil)
+
+	// Create a block
+	if err := sim.AdjustTime(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	block2, _ := client.BlockByNumber(context.Background(), nil)
+	if block2.Time() < uint64(time.Now().Add(time.Minute).Unix())-1 {
+		t.Fatalf("block time not adjusted: have %d, prev %d", block2.Time(), block1.Time())
+	}
+	if err := sim.AdjustTime(-time.Minute); err == nil {
+		t.Fatalf("time adjusted backwards")
+	}
+}
+
+// Tests that, unlike in backends.SimulatedBackend, time can be adjusted while there
+// are pending transactions, which are included in the adjusted block.
+func TestAdjustTimeWithTransactions(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client, ctx := sim.Client(), context.Background()
+	prev, _ := client.HeaderByNumber(ctx, nil)
+
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatalf("could not create transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+	if err := sim.AdjustTime(time.Minute); err != nil {
+		t.Fatalf("failed to adjust time with pending transactions: %v", err)
+	}
+	block, err := client.BlockByHash(ctx, sim.Commit())
+	if err != nil {
+		t.Fatalf("could not get head block: %v", err)
+	}
+	if block.Time()-prev.Time < uint64(time.Minute.Seconds()) {
+		t.Fatalf("block time not adjusted: have %d, prev %d", block.Time(), prev.Time)
+	}
+	if block.Transaction(tx.Hash()) == nil {
+		t.Fatalf("pending transaction not included")
+	}
+}
+
+// Tests that the transactions of the blocks dropped by a fork are returned to
+// the pool and included again in the side chain.
+func TestForkReturnsTransactions(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client, ctx := sim.Client(), context.Background()
+	parent, _ := client.HeaderByNumber(ctx, nil)
+
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatalf("could not create transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("could not send transaction: %v", err)
+	}
+	included := sim.Commit()
+
+	if err := sim.Fork(parent.Hash()); err != nil {
+		t.Fatalf("failed to fork: %v", err)
+	}
+	if _, pending, err := client.TransactionByHash(ctx, tx.Hash()); err != nil || !pending {
+		t.Fatalf("transaction not returned to the pool: pending %v, err %v", pending, err)
+	}
+	if err := client.SendTransaction(ctx, tx); err == nil {
+		t.Fatalf("re-sent transaction already in the pool")
+	}
+	fork := sim.Commit()
+	if fork == included {
+		t.Fatalf("side chain block equals the dropped one")
+	}
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("could not get receipt: %v", err)
+	}
+	if receipt.BlockHash != fork || receipt.BlockNumber.Uint64() != 1 {
+		t.Fatalf("transaction included in wrong block: #%d %x", receipt.BlockNumber, receipt.BlockHash)
+	}
+}
+
+// Tests that the pool reports the status of single transactions, including the
+// nonce gap blocking queued transactions and the reason of dropped ones.
+func TestTxPoolStatus(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client, ctx := sim.Client(), context.Background()
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainid, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	makeTx := func(nonce uint64, tip int64) *types.Transaction {
+		return types.MustSignNewTx(testKey, types.LatestSignerForChainID(chainid), &types.DynamicFeeTx{
+			ChainID:   chainid,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: new(big.Int).Add(head.BaseFee, big.NewInt(tip)),
+			Gas:       params.TxGas,
+			To:        &common.Address{0xaa},
+			Value:     big.NewInt(1),
+		})
+	}
+	var (
+		original    = makeTx(0, params.GWei)
+		replacement = makeTx(0, 2*params.GWei)
+		queued      = makeTx(2, params.GWei)
+	)
+	for _, tx := range []*types.Transaction{original, queued} {
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("failed to send transaction: %v", err)
+		}
+	}
+	raw := sim.node.Attach()
+	defer raw.Close()
+
+	status := func(hash common.Hash) map[string]any {
+		var res map[string]any
+		if err := raw.Call(&res, "txpool_status", hash); err != nil {
+			t.Fatalf("failed to retrieve transaction status: %v", err)
+		}
+		return res
+	}
+	if res := status(original.Hash()); res["status"] != "pending" {
+		t.Fatalf("pending transaction status mismatch: have %v", res)
+	}
+	if res := status(queued.Hash()); res["status"] != "queued" || res["nonceGap"] != "0x1" {
+		t.Fatalf("queued transaction status mismatch: have %v", res)
+	}
+	if res := status(common.Hash{0x01}); res["status"] != "unknown" {
+		t.Fatalf("unknown transaction status mismatch: have %v", res)
+	}
+	// Replace the pending transaction, the drop is recorded asynchronously
+	if err := client.SendTransaction(ctx, replacement); err != nil {
+		t.Fatalf("failed to send replacement transaction: %v", err)
+	}
+	want := map[string]any{"status": "dropped", "reason": "replaced", "replacement": replacement.Hash().Hex()}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		res := status(original.Hash())
+		if reflect.DeepEqual(res, want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("replaced transaction status mismatch: have %v, want %v", res, want)
+		}
+	}
+}
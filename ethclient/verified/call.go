@@ -0,0 +1,157 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package verified
+
+import (
+	"context"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/state/forkstate"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// CallContract executes a message call locally over the verified state at the
+// given canonical block number, or the trusted head if number is nil. State is
+// pulled lazily from the provider as the execution accesses it.
+//
+// If the execution reverts, the returned data is the revert reason along with
+// a vm.ErrExecutionReverted error.
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.call(ctx, header, msg)
+}
+
+// CallContractAtHash executes a message call locally over the verified state at
+// the given block.
+func (c *Client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, hash common.Hash) ([]byte, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.call(ctx, header, msg)
+}
+
+// call executes a message call over the state of a verified header.
+func (c *Client) call(ctx context.Context, header *types.Header, msg ethereum.CallMsg) ([]byte, error) {
+	// Assemble a state which pulls the trie nodes from the provider, verifying
+	// them through their hashes leading up to the trusted state root
+	db := rawdb.NewMemoryDatabase()
+	fork := forkstate.NewAt(db, c.c, header.Hash(), header.Root)
+
+	statedb, err := state.New(header.Root, fork.Wrap(state.NewDatabase(db)), nil)
+	if err != nil {
+		return nil, err
+	}
+	blockCtx := core.NewEVMBlockContext(header, &chainContext{ctx: ctx, client: c}, &header.Coinbase)
+	evm := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, c.config, vm.Config{NoBaseFee: true})
+
+	result, err := core.ApplyMessage(evm, toMessage(msg, header), new(core.GasPool).AddGas(math.MaxUint64))
+	if err == nil {
+		err = statedb.Error()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Revert()) > 0 {
+		return result.Revert(), result.Err
+	}
+	return result.Return(), result.Err
+}
+
+// toMessage converts a call request into a message to execute, applying the
+// same defaults as eth_call.
+func toMessage(msg ethereum.CallMsg, header *types.Header) *core.Message {
+	gas := msg.Gas
+	if gas == 0 {
+		gas = header.GasLimit
+	}
+	value := new(big.Int)
+	if msg.Value != nil {
+		value.Set(msg.Value)
+	}
+	var gasPrice, gasFeeCap, gasTipCap *big.Int
+	switch {
+	case header.BaseFee == nil || msg.GasPrice != nil:
+		gasPrice = new(big.Int)
+		if msg.GasPrice != nil {
+			gasPrice.Set(msg.GasPrice)
+		}
+		gasFeeCap, gasTipCap = gasPrice, gasPrice
+
+	default:
+		gasFeeCap, gasTipCap = new(big.Int), new(big.Int)
+		if msg.GasFeeCap != nil {
+			gasFeeCap.Set(msg.GasFeeCap)
+		}
+		if msg.GasTipCap != nil {
+			gasTipCap.Set(msg.GasTipCap)
+		}
+		// Backfill the legacy gas price for EVM execution, unless zero
+		gasPrice = new(big.Int)
+		if gasFeeCap.BitLen() > 0 || gasTipCap.BitLen() > 0 {
+			gasPrice = gasPrice.Add(gasTipCap, header.BaseFee)
+			if gasPrice.Cmp(gasFeeCap) > 0 {
+				gasPrice = gasFeeCap
+			}
+		}
+	}
+	return &core.Message{
+		From:              msg.From,
+		To:                msg.To,
+		Value:             value,
+		GasLimit:          gas,
+		GasPrice:          gasPrice,
+		GasFeeCap:         gasFeeCap,
+		GasTipCap:         gasTipCap,
+		Data:              msg.Data,
+		AccessList:        msg.AccessList,
+		SkipAccountChecks: true,
+	}
+}
+
+// chainContext resolves the ancestor headers accessed by the BLOCKHASH opcode
+// through the verifying client.
+type chainContext struct {
+	ctx    context.Context
+	client *Client
+}
+
+// Engine implements core.ChainContext. The block author is always provided
+// explicitly, so no consensus engine is needed.
+func (cc *chainContext) Engine() consensus.Engine {
+	return nil
+}
+
+// GetHeader implements core.ChainContext.
+func (cc *chainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, err := cc.client.fetchHeader(cc.ctx, hash)
+	if err != nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
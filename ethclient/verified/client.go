@@ -0,0 +1,347 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package verified implements an Ethereum RPC client which verifies the
+// responses of an untrusted provider.
+//
+// Block headers are verified by following their parent hashes from a trusted
+// head, which is either a checkpoint or a head announced by a beacon light
+// client. Account and storage data is verified through the Merkle proofs of
+// eth_getProof against the state root of a verified header, and contract calls
+// are re-executed locally over proven state.
+package verified
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// maxBacktrack is the maximum number of blocks below the trusted head whose
+	// headers can be verified by following the parent hashes.
+	maxBacktrack = 8192
+
+	// headerCacheSize is the number of verified headers cached.
+	headerCacheSize = 8192
+
+	// forkchoiceTimeout is the maximum time allowed to retrieve the header of a
+	// newly announced head.
+	forkchoiceTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNoTrustedHead is returned if no trusted head has been set yet.
+	ErrNoTrustedHead = errors.New("no trusted head")
+
+	// ErrUnverifiable is returned if a block is outside the range which can be
+	// verified from the trusted head.
+	ErrUnverifiable = errors.New("block not verifiable")
+
+	// ErrNotCanonical is returned if a block is not an ancestor of the trusted head.
+	ErrNotCanonical = errors.New("block not canonical")
+
+	// ErrInvalidResponse is returned if a response of the provider failed to be
+	// verified.
+	ErrInvalidResponse = errors.New("invalid response")
+)
+
+// Client is an Ethereum RPC client verifying the responses of an untrusted
+// provider against a trusted head.
+type Client struct {
+	c      *rpc.Client
+	eth    *ethclient.Client
+	config *params.ChainConfig
+
+	lock      sync.RWMutex
+	head      *types.Header // Latest trusted head
+	finalized *types.Header // Latest trusted finalized block, if known
+
+	headers *lru.Cache[common.Hash, *types.Header] // Headers authenticated by hash
+}
+
+// NewClient creates a verifying client on top of the given RPC connection. The
+// chain configuration is needed to execute contract calls locally.
+func NewClient(c *rpc.Client, config *params.ChainConfig) *Client {
+	return &Client{
+		c:       c,
+		eth:     ethclient.NewClient(c),
+		config:  config,
+		headers: lru.NewCache[common.Hash, *types.Header](headerCacheSize),
+	}
+}
+
+// Close closes the underlying RPC connection.
+func (c *Client) Close() {
+	c.c.Close()
+}
+
+// Client gets the underlying RPC client.
+func (c *Client) Client() *rpc.Client {
+	return c.c
+}
+
+// SetCheckpoint sets the block with the given hash as the trusted head. Only
+// the checkpoint and its ancestors can be verified until a newer head is set.
+func (c *Client) SetCheckpoint(ctx context.Context, hash common.Hash) error {
+	header, err := c.fetchHeader(ctx, hash)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	c.head = header
+	c.lock.Unlock()
+
+	log.Info("Set trusted checkpoint", "number", header.Number, "hash", hash)
+	return nil
+}
+
+// ForkchoiceUpdatedWithHeader accepts a trusted head and finalized block from
+// a beacon light client, implementing the subset of the engine API used by
+// blsync. The head header is delivered by the light client itself, so only the
+// finalized header needs to be retrieved from the provider.
+func (c *Client) ForkchoiceUpdatedWithHeader(head *types.Header, update engine.ForkchoiceStateV1) (engine.ForkChoiceResponse, error) {
+	if hash := head.Hash(); hash != update.HeadBlockHash {
+		return engine.STATUS_INVALID, fmt.Errorf("head hash mismatch: have %x, want %x", hash, update.HeadBlockHash)
+	}
+	c.headers.Add(update.HeadBlockHash, head)
+
+	var finalized *types.Header
+	if update.FinalizedBlockHash != (common.Hash{}) {
+		ctx, cancel := context.WithTimeout(context.Background(), forkchoiceTimeout)
+		defer cancel()
+
+		var err error
+		if finalized, err = c.fetchHeader(ctx, update.FinalizedBlockHash); err != nil {
+			log.Debug("Failed to retrieve trusted finalized block", "hash", update.FinalizedBlockHash, "err", err)
+		}
+	}
+	c.lock.Lock()
+	c.head = head
+	if finalized != nil {
+		c.finalized = finalized
+	}
+	c.lock.Unlock()
+
+	log.Debug("Updated trusted head", "number", head.Number, "hash", update.HeadBlockHash)
+	return engine.ForkChoiceResponse{PayloadStatus: engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &update.HeadBlockHash}}, nil
+}
+
+// Head returns the latest trusted head, or nil if none has been set yet.
+func (c *Client) Head() *types.Header {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.head
+}
+
+// fetchHeader retrieves a header from the provider, authenticated by its hash.
+// Note, the header is not necessarily canonical.
+func (c *Client) fetchHeader(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if header, ok := c.headers.Get(hash); ok {
+		return header, nil
+	}
+	var header *types.Header
+	if err := c.c.CallContext(ctx, &header, "eth_getBlockByHash", hash, false); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, ethereum.NotFound
+	}
+	if have := header.Hash(); have != hash {
+		return nil, fmt.Errorf("%w: header hash mismatch: have %x, want %x", ErrInvalidResponse, have, hash)
+	}
+	c.headers.Add(hash, header)
+	return header, nil
+}
+
+// canonicalHeader retrieves the header of the given number by following the
+// parent hashes from the trusted head.
+func (c *Client) canonicalHeader(ctx context.Context, number uint64) (*types.Header, error) {
+	header := c.Head()
+	if header == nil {
+		return nil, ErrNoTrustedHead
+	}
+	head := header.Number.Uint64()
+	if number > head {
+		return nil, fmt.Errorf("%w: block %d is newer than trusted head %d", ErrUnverifiable, number, head)
+	}
+	if head-number > maxBacktrack {
+		return nil, fmt.Errorf("%w: block %d is too old, trusted head is %d", ErrUnverifiable, number, head)
+	}
+	var err error
+	for header.Number.Uint64() > number {
+		if header, err = c.fetchHeader(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// HeaderByNumber returns a verified canonical header with the given number. If
+// number is nil, the trusted head is returned.
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return c.resolve(ctx, rpc.LatestBlockNumber)
+	}
+	if !number.IsInt64() && !number.IsUint64() {
+		return nil, fmt.Errorf("invalid block number %d", number)
+	}
+	if number.Sign() < 0 {
+		return c.resolve(ctx, rpc.BlockNumber(number.Int64()))
+	}
+	return c.canonicalHeader(ctx, number.Uint64())
+}
+
+// resolve returns the verified header identified by a block number, including
+// the special block tags.
+func (c *Client) resolve(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	switch number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		if head := c.Head(); head != nil {
+			return head, nil
+		}
+		return nil, ErrNoTrustedHead
+
+	case rpc.FinalizedBlockNumber, rpc.SafeBlockNumber:
+		c.lock.RLock()
+		finalized := c.finalized
+		c.lock.RUnlock()
+
+		if finalized == nil {
+			return nil, fmt.Errorf("%w: no trusted finalized block", ErrUnverifiable)
+		}
+		return finalized, nil
+
+	case rpc.EarliestBlockNumber:
+		return c.canonicalHeader(ctx, 0)
+
+	default:
+		if number < 0 {
+			return nil, fmt.Errorf("invalid block number %d", number)
+		}
+		return c.canonicalHeader(ctx, uint64(number))
+	}
+}
+
+// HeaderByHash returns the verified header with the given hash, ensuring it is
+// an ancestor of the trusted head.
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	header, err := c.fetchHeader(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	canon, err := c.canonicalHeader(ctx, header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if canon.Hash() != hash {
+		return nil, fmt.Errorf("%w: block %d %x", ErrNotCanonical, header.Number, hash)
+	}
+	return header, nil
+}
+
+// BlockByNumber returns a verified canonical block with the given number. If
+// number is nil, the trusted head block is returned.
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.blockByHeader(ctx, header)
+}
+
+// BlockByHash returns the verified block with the given hash, ensuring it is an
+// ancestor of the trusted head.
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.blockByHeader(ctx, header)
+}
+
+// blockByHeader retrieves the body belonging to a verified header, verifying it
+// against the roots in the header.
+func (c *Client) blockByHeader(ctx context.Context, header *types.Header) (*types.Block, error) {
+	block, err := c.eth.BlockByHash(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if block.Hash() != header.Hash() {
+		return nil, fmt.Errorf("%w: block hash mismatch", ErrInvalidResponse)
+	}
+	if root := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); root != header.TxHash {
+		return nil, fmt.Errorf("%w: transaction root mismatch: have %x, want %x", ErrInvalidResponse, root, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return nil, fmt.Errorf("%w: uncle hash mismatch: have %x, want %x", ErrInvalidResponse, hash, header.UncleHash)
+	}
+	if header.WithdrawalsHash != nil {
+		if block.Withdrawals() == nil {
+			return nil, fmt.Errorf("%w: missing withdrawals", ErrInvalidResponse)
+		}
+		if root := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); root != *header.WithdrawalsHash {
+			return nil, fmt.Errorf("%w: withdrawals root mismatch: have %x, want %x", ErrInvalidResponse, root, *header.WithdrawalsHash)
+		}
+	}
+	return block, nil
+}
+
+// BlockNumber returns the number of the trusted head.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	head := c.Head()
+	if head == nil {
+		return 0, ErrNoTrustedHead
+	}
+	return head.Number.Uint64(), nil
+}
+
+// ChainID returns the chain ID of the configured chain.
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.config.ChainID), nil
+}
+
+// SendTransaction injects a signed transaction into the provider's pool.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var hash common.Hash
+	if err := c.c.CallContext(ctx, &hash, "eth_sendRawTransaction", hexutil.Bytes(data)); err != nil {
+		return err
+	}
+	if hash != tx.Hash() {
+		return fmt.Errorf("%w: transaction hash mismatch: have %x, want %x", ErrInvalidResponse, hash, tx.Hash())
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package verified
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testAddr     = common.Address{0x01, 0x01}
+	testContract = common.Address{0x02, 0x02}
+	testCode     = common.FromHex("0x60015460005260206000f3") // return storage slot 1
+)
+
+// startProvider starts an in-process node acting as the RPC provider.
+func startProvider(t *testing.T) (*node.Node, *core.Genesis) {
+	t.Helper()
+
+	stack, err := node.New(&node.Config{
+		P2P: p2p.Config{NoDiscovery: true, MaxPeers: 0},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	genesis := core.DeveloperGenesisBlock(10_000_000, testAddr)
+	genesis.Alloc[testContract] = core.GenesisAccount{
+		Balance: big.NewInt(42),
+		Nonce:   3,
+		Code:    testCode,
+		Storage: map[common.Hash]common.Hash{
+			common.BigToHash(big.NewInt(1)): {0x11},
+		},
+	}
+	if _, err := eth.New(stack, &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync}); err != nil {
+		t.Fatalf("failed to create eth service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	return stack, genesis
+}
+
+// Tests that data served by an honest provider is verified and returned.
+func TestVerifiedState(t *testing.T) {
+	stack, genesis := startProvider(t)
+	defer stack.Close()
+
+	client := NewClient(stack.Attach(), genesis.Config)
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.BalanceAt(ctx, testAddr, nil); !errors.Is(err, ErrNoTrustedHead) {
+		t.Fatalf("untrusted access error mismatch: have %v, want %v", err, ErrNoTrustedHead)
+	}
+	if err := client.SetCheckpoint(ctx, genesis.ToBlock().Hash()); err != nil {
+		t.Fatalf("failed to set checkpoint: %v", err)
+	}
+	if balance, err := client.BalanceAt(ctx, testContract, nil); err != nil || balance.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("balance mismatch: have %v (%v), want %v", balance, err, 42)
+	}
+	if balance, err := client.BalanceAt(ctx, common.Address{0xff}, nil); err != nil || balance.Sign() != 0 {
+		t.Errorf("missing account balance mismatch: have %v (%v), want 0", balance, err)
+	}
+	if nonce, err := client.NonceAt(ctx, testContract, nil); err != nil || nonce != 3 {
+		t.Errorf("nonce mismatch: have %d (%v), want %d", nonce, err, 3)
+	}
+	if code, err := client.CodeAt(ctx, testContract, nil); err != nil || !bytes.Equal(code, testCode) {
+		t.Errorf("code mismatch: have %x (%v), want %x", code, err, testCode)
+	}
+	if value, err := client.StorageAt(ctx, testContract, common.BigToHash(big.NewInt(1)), nil); err != nil || common.BytesToHash(value) != (common.Hash{0x11}) {
+		t.Errorf("storage mismatch: have %x (%v), want %x", value, err, common.Hash{0x11})
+	}
+	if value, err := client.StorageAt(ctx, testContract, common.Hash{0xff}, nil); err != nil || common.BytesToHash(value) != (common.Hash{}) {
+		t.Errorf("missing storage mismatch: have %x (%v), want zero", value, err)
+	}
+	ret, err := client.CallContract(ctx, ethereum.CallMsg{To: &testContract}, nil)
+	if err != nil || common.BytesToHash(ret) != (common.Hash{0x11}) {
+		t.Errorf("call result mismatch: have %x (%v), want %x", ret, err, common.Hash{0x11})
+	}
+	if _, err := client.HeaderByNumber(ctx, big.NewInt(1)); !errors.Is(err, ErrUnverifiable) {
+		t.Errorf("future block error mismatch: have %v, want %v", err, ErrUnverifiable)
+	}
+	if _, err := client.HeaderByNumber(ctx, new(big.Int).Lsh(common.Big1, 64)); err == nil {
+		t.Error("out of range block number accepted")
+	}
+}
+
+// lyingAPI is an eth namespace serving tampered responses for some methods and
+// forwarding the rest to an honest provider.
+type lyingAPI struct {
+	honest *rpc.Client
+	header common.Hash // Block hash to serve the header of for any hash
+}
+
+func (api *lyingAPI) GetBlockByHash(ctx context.Context, hash common.Hash, full bool) (json.RawMessage, error) {
+	if api.header != (common.Hash{}) {
+		hash = api.header
+	}
+	var res json.RawMessage
+	err := api.honest.CallContext(ctx, &res, "eth_getBlockByHash", hash, full)
+	return res, err
+}
+
+func (api *lyingAPI) GetProof(ctx context.Context, addr common.Address, keys []string, block rpc.BlockNumberOrHash) (map[string]interface{}, error) {
+	var res map[string]interface{}
+	if err := api.honest.CallContext(ctx, &res, "eth_getProof", addr, keys, block); err != nil {
+		return nil, err
+	}
+	// Drop the proof of the account, claiming it's absent
+	res["accountProof"] = []string{}
+	return res, nil
+}
+
+// Tests that tampered responses of a malicious provider are rejected.
+func TestVerifiedStateMalicious(t *testing.T) {
+	stack, genesis := startProvider(t)
+	defer stack.Close()
+
+	honest := stack.Attach()
+	defer honest.Close()
+
+	api := &lyingAPI{honest: honest}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(rpc.DialInProc(server), params.AllDevChainProtocolChanges)
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.SetCheckpoint(ctx, genesis.ToBlock().Hash()); err != nil {
+		t.Fatalf("failed to set checkpoint: %v", err)
+	}
+	if _, err := client.BalanceAt(ctx, testContract, nil); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("tampered proof error mismatch: have %v, want %v", err, ErrInvalidResponse)
+	}
+	// Serve the genesis header for any requested hash
+	api.header = genesis.ToBlock().Hash()
+	if err := client.SetCheckpoint(ctx, common.Hash{0xff}); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("tampered header error mismatch: have %v, want %v", err, ErrInvalidResponse)
+	}
+}
+
+// Tests that heads announced by a beacon light client are only trusted if the
+// delivered header matches the announced hash.
+func TestForkchoiceUpdatedWithHeader(t *testing.T) {
+	stack, genesis := startProvider(t)
+	defer stack.Close()
+
+	client := NewClient(stack.Attach(), genesis.Config)
+	defer client.Close()
+
+	head := genesis.ToBlock().Header()
+	if _, err := client.ForkchoiceUpdatedWithHeader(head, engine.ForkchoiceStateV1{HeadBlockHash: common.Hash{0xff}}); err == nil {
+		t.Fatal("mismatching head header accepted")
+	}
+	if client.Head() != nil {
+		t.Fatal("trusted head set from mismatching header")
+	}
+	res, err := client.ForkchoiceUpdatedWithHeader(head, engine.ForkchoiceStateV1{HeadBlockHash: head.Hash(), FinalizedBlockHash: head.Hash()})
+	if err != nil || res.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("head update failed: %v (%v)", res.PayloadStatus.Status, err)
+	}
+	if have := client.Head(); have == nil || have.Hash() != head.Hash() {
+		t.Fatalf("trusted head mismatch: have %v, want %x", have, head.Hash())
+	}
+	if balance, err := client.BalanceAt(context.Background(), testContract, nil); err != nil || balance.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("balance mismatch: have %v (%v), want %v", balance, err, 42)
+	}
+}
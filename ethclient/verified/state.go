@@ -0,0 +1,219 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package verified
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// proofResult is the subset of an eth_getProof response needed to verify an
+// account and its storage slots.
+type proofResult struct {
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []struct {
+		Key   string          `json:"key"`
+		Proof []hexutil.Bytes `json:"proof"`
+	} `json:"storageProof"`
+}
+
+// proveAccount retrieves an account and optionally some of its storage slots at
+// the given verified header, verifying them against the state root.
+func (c *Client) proveAccount(ctx context.Context, header *types.Header, addr common.Address, slots []common.Hash) (*types.StateAccount, []common.Hash, error) {
+	keys := make([]string, len(slots))
+	for i, slot := range slots {
+		keys[i] = slot.Hex()
+	}
+	var result proofResult
+	if err := c.c.CallContext(ctx, &result, "eth_getProof", addr, keys, rpc.BlockNumberOrHashWithHash(header.Hash(), false)); err != nil {
+		return nil, nil, err
+	}
+	// Verify the account against the state root
+	blob, err := trie.VerifyProof(header.Root, crypto.Keccak256(addr.Bytes()), proofDB(result.AccountProof))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid account proof for %x: %v", ErrInvalidResponse, addr, err)
+	}
+	account := types.NewEmptyStateAccount()
+	if blob != nil {
+		if err := rlp.DecodeBytes(blob, account); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid account %x: %v", ErrInvalidResponse, addr, err)
+		}
+	}
+	// Verify the requested storage slots against the storage root
+	if len(result.StorageProof) != len(slots) {
+		return nil, nil, fmt.Errorf("%w: storage proof count mismatch: have %d, want %d", ErrInvalidResponse, len(result.StorageProof), len(slots))
+	}
+	values := make([]common.Hash, len(slots))
+	for i, slot := range slots {
+		if account.Root == types.EmptyRootHash {
+			continue
+		}
+		blob, err := trie.VerifyProof(account.Root, crypto.Keccak256(slot.Bytes()), proofDB(result.StorageProof[i].Proof))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid storage proof for %x slot %x: %v", ErrInvalidResponse, addr, slot, err)
+		}
+		if blob != nil {
+			_, content, _, err := rlp.Split(blob)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: invalid storage value for %x slot %x: %v", ErrInvalidResponse, addr, slot, err)
+			}
+			values[i] = common.BytesToHash(content)
+		}
+	}
+	return account, values, nil
+}
+
+// proofDB collects a list of proof nodes into a database keyed by their hashes.
+func proofDB(nodes []hexutil.Bytes) *memorydb.Database {
+	db := memorydb.New()
+	for _, node := range nodes {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
+// BalanceAt returns the verified wei balance of the given account at the given
+// canonical block number, or the trusted head if number is nil.
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.balanceAt(ctx, header, account)
+}
+
+// BalanceAtHash returns the verified wei balance of the given account at the
+// given block.
+func (c *Client) BalanceAtHash(ctx context.Context, account common.Address, hash common.Hash) (*big.Int, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.balanceAt(ctx, header, account)
+}
+
+func (c *Client) balanceAt(ctx context.Context, header *types.Header, addr common.Address) (*big.Int, error) {
+	account, _, err := c.proveAccount(ctx, header, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return account.Balance, nil
+}
+
+// NonceAt returns the verified nonce of the given account at the given canonical
+// block number, or the trusted head if number is nil.
+func (c *Client) NonceAt(ctx context.Context, account common.Address, number *big.Int) (uint64, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, err
+	}
+	return c.nonceAt(ctx, header, account)
+}
+
+// NonceAtHash returns the verified nonce of the given account at the given block.
+func (c *Client) NonceAtHash(ctx context.Context, account common.Address, hash common.Hash) (uint64, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	return c.nonceAt(ctx, header, account)
+}
+
+func (c *Client) nonceAt(ctx context.Context, header *types.Header, addr common.Address) (uint64, error) {
+	account, _, err := c.proveAccount(ctx, header, addr, nil)
+	if err != nil {
+		return 0, err
+	}
+	return account.Nonce, nil
+}
+
+// StorageAt returns the verified value of a storage slot of the given account at
+// the given canonical block number, or the trusted head if number is nil.
+func (c *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, number *big.Int) ([]byte, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.storageAt(ctx, header, account, key)
+}
+
+// StorageAtHash returns the verified value of a storage slot of the given account
+// at the given block.
+func (c *Client) StorageAtHash(ctx context.Context, account common.Address, key common.Hash, hash common.Hash) ([]byte, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.storageAt(ctx, header, account, key)
+}
+
+func (c *Client) storageAt(ctx context.Context, header *types.Header, addr common.Address, key common.Hash) ([]byte, error) {
+	_, values, err := c.proveAccount(ctx, header, addr, []common.Hash{key})
+	if err != nil {
+		return nil, err
+	}
+	return values[0].Bytes(), nil
+}
+
+// CodeAt returns the verified contract code of the given account at the given
+// canonical block number, or the trusted head if number is nil.
+func (c *Client) CodeAt(ctx context.Context, account common.Address, number *big.Int) ([]byte, error) {
+	header, err := c.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return c.codeAt(ctx, header, account)
+}
+
+// CodeAtHash returns the verified contract code of the given account at the
+// given block.
+func (c *Client) CodeAtHash(ctx context.Context, account common.Address, hash common.Hash) ([]byte, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.codeAt(ctx, header, account)
+}
+
+func (c *Client) codeAt(ctx context.Context, header *types.Header, addr common.Address) ([]byte, error) {
+	account, _, err := c.proveAccount(ctx, header, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
+		return nil, nil
+	}
+	var code hexutil.Bytes
+	if err := c.c.CallContext(ctx, &code, "eth_getCode", addr, rpc.BlockNumberOrHashWithHash(header.Hash(), false)); err != nil {
+		return nil, err
+	}
+	if hash := crypto.Keccak256(code); !bytes.Equal(hash, account.CodeHash) {
+		return nil, fmt.Errorf("%w: code hash mismatch for %x: have %x, want %x", ErrInvalidResponse, addr, hash, account.CodeHash)
+	}
+	return code, nil
+}
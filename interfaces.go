@@ -208,6 +208,9 @@ type FeeHistory struct {
 	Reward       [][]*big.Int // list every txs priority fee per block
 	BaseFee      []*big.Int   // list of each block's base fee
 	GasUsedRatio []float64    // ratio of gas used out of the total available limit
+
+	BlobBaseFee      []*big.Int // list of each block's blob base fee
+	BlobGasUsedRatio []float64  // ratio of blob gas used out of the maximum allowed
 }
 
 // A PendingStateReader provides access to the pending state, which is the result of all
@@ -0,0 +1,269 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxAddressActivity is the maximum number of transactions returned by a single
+	// address activity query.
+	maxAddressActivity = 1000
+
+	// maxAddressScanBlocks is the maximum number of blocks not yet covered by the
+	// address index that a single query searches through.
+	maxAddressScanBlocks = 1024
+)
+
+// addressRoleNames are the names of the address roles, in bit order.
+var addressRoleNames = []string{"sender", "recipient", "created", "emitter", "called"}
+
+// AddressActivityArgs are the arguments of an address activity query.
+type AddressActivityArgs struct {
+	FromBlock *rpc.BlockNumber       `json:"fromBlock"` // Oldest block to search, the index tail if nil
+	ToBlock   *rpc.BlockNumber       `json:"toBlock"`   // Newest block to search, the latest if nil
+	Limit     hexutil.Uint64         `json:"limit"`     // Maximum number of transactions to return
+	Cursor    *AddressActivityCursor `json:"cursor"`    // Position to resume a previous query at
+}
+
+// AddressActivityCursor is the position of a transaction within the chain.
+type AddressActivityCursor struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+}
+
+// AddressTransaction is a transaction an address was active in.
+type AddressTransaction struct {
+	*RPCTransaction
+	Roles []string `json:"roles"`
+}
+
+// AddressActivity is a page of the transactions an address was active in. The
+// cursor is set if there might be more transactions in the queried range.
+type AddressActivity struct {
+	Transactions []*AddressTransaction  `json:"transactions"`
+	Cursor       *AddressActivityCursor `json:"cursor"`
+}
+
+// activityEntry is a transaction an address was active in.
+type activityEntry struct {
+	number uint64
+	index  uint32
+	roles  byte
+}
+
+// GetTransactionsByAddress returns the transactions the given address sent,
+// received, created, emitted logs in or was called internally by, in ascending
+// chain order. Results are paginated: if the returned cursor is set, passing it
+// in the next query resumes the search after the last returned transaction.
+//
+// The query is limited to the blocks covered by the address activity index,
+// along with the newest blocks which are not indexed yet. Internal calls are
+// only known for the blocks the node executed with the index enabled; in blocks
+// synced without execution, contracts reached through internal calls are only
+// found if they emitted a log.
+func (s *TransactionAPI) GetTransactionsByAddress(ctx context.Context, address common.Address, args *AddressActivityArgs) (*AddressActivity, error) {
+	tail, end, ok := s.b.AddressIndexStatus()
+	if !ok {
+		return nil, errors.New("address index not enabled")
+	}
+	if args == nil {
+		args = new(AddressActivityArgs)
+	}
+	head := s.b.CurrentHeader().Number.Uint64()
+
+	// Resolve the range and the position to start the search at
+	from, to := tail, head
+	if args.FromBlock != nil {
+		number, err := s.resolveBlockNumber(ctx, *args.FromBlock)
+		if err != nil {
+			return nil, err
+		}
+		from = number
+	}
+	if args.ToBlock != nil {
+		number, err := s.resolveBlockNumber(ctx, *args.ToBlock)
+		if err != nil {
+			return nil, err
+		}
+		to = number
+	}
+	if to > head {
+		to = head
+	}
+	if from < tail {
+		return nil, fmt.Errorf("blocks before %d are not indexed", tail)
+	}
+	var index uint32
+	if args.Cursor != nil {
+		if uint64(args.Cursor.BlockNumber) < from {
+			return nil, errors.New("cursor before the queried range")
+		}
+		from, index = uint64(args.Cursor.BlockNumber), uint32(args.Cursor.TransactionIndex)
+	}
+	limit := uint64(args.Limit)
+	if limit == 0 || limit > maxAddressActivity {
+		limit = maxAddressActivity
+	}
+	// Search the index first, then scan the blocks not covered by it yet
+	var (
+		entries []activityEntry
+		cursor  *AddressActivityCursor
+	)
+	if from < end {
+		last := to
+		if last >= end {
+			last = end - 1
+		}
+		err := rawdb.IterateAddressActivity(s.b.ChainDb(), address, from, index, last, func(number uint64, position uint32, roles byte) bool {
+			entries = append(entries, activityEntry{number, position, roles})
+			return uint64(len(entries)) < limit
+		})
+		if err != nil {
+			return nil, err
+		}
+		from, index = end, 0
+	}
+	if uint64(len(entries)) < limit && from <= to {
+		scanned, next, err := s.scanAddressActivity(ctx, address, from, index, to, limit-uint64(len(entries)))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, scanned...)
+		if next <= to && uint64(len(entries)) < limit {
+			cursor = &AddressActivityCursor{BlockNumber: hexutil.Uint64(next)}
+		}
+	}
+	if uint64(len(entries)) == limit {
+		last := entries[len(entries)-1]
+		cursor = &AddressActivityCursor{BlockNumber: hexutil.Uint64(last.number), TransactionIndex: hexutil.Uint(last.index + 1)}
+	}
+	// Assemble the transactions of the matches
+	result := &AddressActivity{Transactions: make([]*AddressTransaction, 0, len(entries)), Cursor: cursor}
+
+	var block *types.Block
+	for _, entry := range entries {
+		if block == nil || block.NumberU64() != entry.number {
+			var err error
+			if block, err = s.b.BlockByNumber(ctx, rpc.BlockNumber(entry.number)); err != nil {
+				return nil, err
+			}
+			if block == nil {
+				return nil, fmt.Errorf("block %d not found", entry.number)
+			}
+		}
+		tx := newRPCTransactionFromBlockIndex(block, uint64(entry.index), s.b.ChainConfig())
+		if tx == nil {
+			return nil, fmt.Errorf("transaction %d of block %d not found", entry.index, entry.number)
+		}
+		var roles []string
+		for i, name := range addressRoleNames {
+			if entry.roles&(1<<i) != 0 {
+				roles = append(roles, name)
+			}
+		}
+		result.Transactions = append(result.Transactions, &AddressTransaction{RPCTransaction: tx, Roles: roles})
+	}
+	return result, nil
+}
+
+// resolveBlockNumber resolves a block number, which might be a special tag, to
+// the number of the canonical block it refers to.
+func (s *TransactionAPI) resolveBlockNumber(ctx context.Context, number rpc.BlockNumber) (uint64, error) {
+	if number >= 0 {
+		return uint64(number), nil
+	}
+	if number == rpc.PendingBlockNumber {
+		number = rpc.LatestBlockNumber
+	}
+	header, err := s.b.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("%s block not found", number)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// scanAddressActivity searches the blocks from the given position up to block to
+// for transactions the address was active in, without the index. At most limit
+// transactions are returned and at most maxAddressScanBlocks blocks searched,
+// the number of the next block to search is returned along with the results.
+func (s *TransactionAPI) scanAddressActivity(ctx context.Context, address common.Address, from uint64, index uint32, to uint64, limit uint64) ([]activityEntry, uint64, error) {
+	var entries []activityEntry
+
+	number := from
+	for ; number <= to && number < from+maxAddressScanBlocks; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, 0, err
+		}
+		if block == nil {
+			break
+		}
+		txs := block.Transactions()
+		if len(txs) == 0 {
+			continue
+		}
+		receipts, err := s.b.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(receipts) != len(txs) {
+			return nil, 0, fmt.Errorf("receipts of block %d not found", number)
+		}
+		var (
+			signer = types.MakeSigner(s.b.ChainConfig(), block.Number(), block.Time())
+			calls  = rawdb.ReadInternalCalls(s.b.ChainDb(), block.Hash(), number)
+		)
+		start := 0
+		if number == from {
+			start = int(index)
+		}
+		for i := start; i < len(txs); i++ {
+			var called []common.Address
+			if i < len(calls) {
+				called = calls[i]
+			}
+			active, err := core.TransactionActivity(signer, txs[i], receipts[i].Logs, called)
+			if err != nil {
+				return nil, 0, err
+			}
+			if roles := active[address]; roles != 0 {
+				entries = append(entries, activityEntry{number, uint32(i), roles})
+				if uint64(len(entries)) == limit {
+					return entries, number, nil
+				}
+			}
+		}
+	}
+	return entries, number, nil
+}
@@ -36,10 +36,14 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -80,22 +84,77 @@ func (s *EthereumAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, e
 	return (*hexutil.Big)(tipcap), err
 }
 
+// BlobBaseFee returns the base fee per blob gas of the next block.
+func (s *EthereumAPI) BlobBaseFee(ctx context.Context) (*hexutil.Big, error) {
+	fee, err := s.b.BlobBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if fee == nil {
+		return nil, errors.New("blob base fee unavailable before Cancun")
+	}
+	return (*hexutil.Big)(fee), nil
+}
+
+// feeTierResult is a single fee recommendation of the eth_feeRecommendations
+// RPC method.
+type feeTierResult struct {
+	MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big `json:"maxFeePerGas"`
+	Probability          float64      `json:"probability"`
+}
+
+// feeRecommendationsResult is the result of the eth_feeRecommendations RPC method.
+type feeRecommendationsResult struct {
+	BaseFee  *hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	Slow     *feeTierResult `json:"slow"`
+	Standard *feeTierResult `json:"standard"`
+	Fast     *feeTierResult `json:"fast"`
+}
+
+// FeeRecommendations returns slow, standard and fast fee suggestions for dynamic
+// fee transactions, each with an estimated probability of being included in the
+// next block. The suggestions take both the fees paid in recent blocks and the
+// current content of the transaction pool into account.
+func (s *EthereumAPI) FeeRecommendations(ctx context.Context) (*feeRecommendationsResult, error) {
+	tiers, err := s.b.SuggestFeeTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	convert := func(tier gasprice.FeeTier) *feeTierResult {
+		return &feeTierResult{
+			MaxPriorityFeePerGas: (*hexutil.Big)(tier.TipCap),
+			MaxFeePerGas:         (*hexutil.Big)(tier.FeeCap),
+			Probability:          tier.Probability,
+		}
+	}
+	return &feeRecommendationsResult{
+		BaseFee:  (*hexutil.Big)(tiers.BaseFee),
+		Slow:     convert(tiers.Slow),
+		Standard: convert(tiers.Standard),
+		Fast:     convert(tiers.Fast),
+	}, nil
+}
+
 type feeHistoryResult struct {
-	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
-	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
-	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
-	GasUsedRatio []float64        `json:"gasUsedRatio"`
+	OldestBlock      *hexutil.Big     `json:"oldestBlock"`
+	Reward           [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee          []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio     []float64        `json:"gasUsedRatio"`
+	BlobBaseFee      []*hexutil.Big   `json:"baseFeePerBlobGas,omitempty"`
+	BlobGasUsedRatio []float64        `json:"blobGasUsedRatio,omitempty"`
 }
 
 // FeeHistory returns the fee market history.
 func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount math.HexOrDecimal64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
-	oldest, reward, baseFee, gasUsed, err := s.b.FeeHistory(ctx, uint64(blockCount), lastBlock, rewardPercentiles)
+	oldest, reward, baseFee, gasUsed, blobBaseFee, blobGasUsed, err := s.b.FeeHistory(ctx, uint64(blockCount), lastBlock, rewardPercentiles)
 	if err != nil {
 		return nil, err
 	}
 	results := &feeHistoryResult{
-		OldestBlock:  (*hexutil.Big)(oldest),
-		GasUsedRatio: gasUsed,
+		OldestBlock:      (*hexutil.Big)(oldest),
+		GasUsedRatio:     gasUsed,
+		BlobGasUsedRatio: blobGasUsed,
 	}
 	if reward != nil {
 		results.Reward = make([][]*hexutil.Big, len(reward))
@@ -112,6 +171,12 @@ func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount math.HexOrDecim
 			results.BaseFee[i] = (*hexutil.Big)(v)
 		}
 	}
+	if blobBaseFee != nil {
+		results.BlobBaseFee = make([]*hexutil.Big, len(blobBaseFee))
+		for i, v := range blobBaseFee {
+			results.BlobBaseFee[i] = (*hexutil.Big)(v)
+		}
+	}
 	return results, nil
 }
 
@@ -209,13 +274,64 @@ func (s *TxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCT
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *TxPoolAPI) Status() map[string]hexutil.Uint {
-	pending, queue := s.b.Stats()
-	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+// RPCTxStatus is the status of a single transaction as seen by the pool.
+type RPCTxStatus struct {
+	Status      string             `json:"status"`                // One of pending, queued, dropped or unknown
+	NonceGap    *hexutil.Uint64    `json:"nonceGap,omitempty"`    // First missing nonce blocking a queued transaction
+	Reason      *txpool.DropReason `json:"reason,omitempty"`      // Reason for a dropped transaction's removal
+	Replacement *common.Hash       `json:"replacement,omitempty"` // Transaction replacing a dropped one
+}
+
+// Status returns the number of pending and queued transaction in the pool. If a
+// transaction hash is specified, the status of that single transaction is
+// returned instead.
+func (s *TxPoolAPI) Status(ctx context.Context, hash *common.Hash) (interface{}, error) {
+	if hash == nil {
+		pending, queue := s.b.Stats()
+		return map[string]hexutil.Uint{
+			"pending": hexutil.Uint(pending),
+			"queued":  hexutil.Uint(queue),
+		}, nil
 	}
+	if tx := s.b.GetPoolTransaction(*hash); tx != nil {
+		from, err := types.Sender(types.LatestSigner(s.b.ChainConfig()), tx)
+		if err != nil {
+			return nil, err
+		}
+		pending, queue := s.b.TxPoolContentFrom(from)
+		for _, ptx := range pending {
+			if ptx.Hash() == *hash {
+				return &RPCTxStatus{Status: "pending"}, nil
+			}
+		}
+		for _, qtx := range queue {
+			if qtx.Hash() != *hash {
+				continue
+			}
+			// Transaction is queued, find the first nonce missing before it
+			gap, err := s.b.GetPoolNonce(ctx, from)
+			if err != nil {
+				return nil, err
+			}
+			for _, next := range queue {
+				if next.Nonce() > gap {
+					break
+				}
+				if next.Nonce() == gap {
+					gap++
+				}
+			}
+			status := &RPCTxStatus{Status: "queued"}
+			if gap < tx.Nonce() {
+				status.NonceGap = (*hexutil.Uint64)(&gap)
+			}
+			return status, nil
+		}
+	}
+	if drop := s.b.GetPoolDroppedTransaction(*hash); drop != nil {
+		return &RPCTxStatus{Status: "dropped", Reason: &drop.Reason, Replacement: drop.Replacement}, nil
+	}
+	return &RPCTxStatus{Status: "unknown"}, nil
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -925,6 +1041,69 @@ func (s *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.
 	return result, nil
 }
 
+// BlobSidecar is the RPC representation of an archived blob along with its KZG
+// commitment and proof.
+type BlobSidecar struct {
+	BlockNumber   hexutil.Uint64 `json:"blockNumber"`
+	TxHash        common.Hash    `json:"transactionHash"`
+	Index         hexutil.Uint64 `json:"index"`
+	VersionedHash common.Hash    `json:"versionedHash"`
+	Blob          hexutil.Bytes  `json:"blob"`
+	Commitment    hexutil.Bytes  `json:"kzgCommitment"`
+	Proof         hexutil.Bytes  `json:"kzgProof"`
+}
+
+// newBlobSidecar converts an archived blob into its RPC representation.
+func newBlobSidecar(vhash common.Hash, blob *blobpool.ArchivedBlob) *BlobSidecar {
+	return &BlobSidecar{
+		BlockNumber:   hexutil.Uint64(blob.Block),
+		TxHash:        blob.TxHash,
+		Index:         hexutil.Uint64(blob.Index),
+		VersionedHash: vhash,
+		Blob:          blob.Blob[:],
+		Commitment:    blob.Commitment[:],
+		Proof:         blob.Proof[:],
+	}
+}
+
+// GetBlobSidecars returns the blobs of all the blob transactions included in the
+// given block, in inclusion order. Blobs are only available if the node runs
+// with the blob archive enabled and saw the transactions before inclusion.
+func (s *BlockChainAPI) GetBlobSidecars(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*BlobSidecar, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		// When the block doesn't exist, the RPC method should return JSON null
+		// as per specification.
+		return nil, nil
+	}
+	result := make([]*BlobSidecar, 0)
+	for _, tx := range block.Transactions() {
+		for i, vhash := range tx.BlobHashes() {
+			blob := s.b.GetArchivedBlob(vhash)
+			if blob == nil {
+				return nil, fmt.Errorf("blob %x of transaction %x not archived", vhash, tx.Hash())
+			}
+			// The same blob might have been archived from a different transaction,
+			// report the location requested by the caller
+			sidecar := newBlobSidecar(vhash, blob)
+			sidecar.BlockNumber = hexutil.Uint64(block.NumberU64())
+			sidecar.TxHash = tx.Hash()
+			sidecar.Index = hexutil.Uint64(i)
+			result = append(result, sidecar)
+		}
+	}
+	return result, nil
+}
+
+// GetBlobByVersionedHash returns an archived blob by its versioned hash, or nil
+// if the blob is not available.
+func (s *BlockChainAPI) GetBlobByVersionedHash(ctx context.Context, hash common.Hash) *BlobSidecar {
+	if blob := s.b.GetArchivedBlob(hash); blob != nil {
+		return newBlobSidecar(hash, blob)
+	}
+	return nil
+}
+
 // OverrideAccount indicates the overriding fields of account during the execution
 // of a message call.
 // Note, state and stateDiff can't be specified at the same time. If state is
@@ -1746,6 +1925,39 @@ func (s *TransactionAPI) GetTransactionByHash(ctx context.Context, hash common.H
 	return nil, nil
 }
 
+// GetTransactionBySenderAndNonce returns the transaction with the given sender
+// and nonce. Transactions included in the canonical chain take precedence over
+// pending ones in the transaction pool. Only pending transactions are found if
+// the sender index is not enabled, otherwise an error is returned.
+func (s *TransactionAPI) GetTransactionBySenderAndNonce(ctx context.Context, sender common.Address, nonce hexutil.Uint64) (*RPCTransaction, error) {
+	// Try to return an already finalized transaction
+	tx, blockHash, blockNumber, index, err := s.b.GetTransactionBySenderAndNonce(ctx, sender, uint64(nonce))
+	if err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		header, err := s.b.HeaderByHash(ctx, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		return newRPCTransaction(tx, blockHash, blockNumber, header.Time, index, header.BaseFee, s.b.ChainConfig()), nil
+	}
+	// No finalized transaction, try to retrieve it from the pool
+	pending, queued := s.b.TxPoolContentFrom(sender)
+	for _, txs := range [][]*types.Transaction{pending, queued} {
+		for _, tx := range txs {
+			if tx.Nonce() == uint64(nonce) {
+				return NewRPCPendingTransaction(tx, s.b.CurrentHeader(), s.b.ChainConfig()), nil
+			}
+		}
+	}
+	// Transaction unknown, flag it if mined transactions cannot be looked up
+	if rawdb.ReadSenderIndexTail(s.b.ChainDb()) == nil {
+		return nil, errors.New("sender index not enabled")
+	}
+	return nil, nil
+}
+
 // GetRawTransactionByHash returns the bytes of the transaction for the given hash.
 func (s *TransactionAPI) GetRawTransactionByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
 	// Retrieve a finalized transaction, or a pooled otherwise
@@ -1904,7 +2116,13 @@ func (s *TransactionAPI) SendTransaction(ctx context.Context, args TransactionAr
 	if err != nil {
 		return common.Hash{}, err
 	}
-	return SubmitTransaction(ctx, s.b, signed)
+	hash, err := SubmitTransaction(ctx, s.b, signed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// The wallet can re-sign the transaction, so allow the node to bump its fees
+	s.b.TrackTx(signed, account.Address)
+	return hash, nil
 }
 
 // FillTransaction fills the defaults (nonce, gas, gasPrice or 1559 fields)
@@ -2165,6 +2383,35 @@ func (s *DebugAPI) GetRawTransaction(ctx context.Context, hash common.Hash) (hex
 	return tx.MarshalBinary()
 }
 
+// ContractCreator is the creator of a contract, along with the transaction and
+// block it was created in.
+type ContractCreator struct {
+	Creator         common.Address `json:"creator"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockHash       common.Hash    `json:"blockHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+}
+
+// GetContractCreator returns the account which created the given contract, which
+// is a contract itself for internal creations. Only contracts created in blocks
+// executed since the contract creation index was enabled are known.
+func (api *DebugAPI) GetContractCreator(ctx context.Context, address common.Address) (*ContractCreator, error) {
+	db := api.b.ChainDb()
+	if rawdb.ReadContractIndexTail(db) == nil {
+		return nil, errors.New("contract creation index not enabled")
+	}
+	creation := rawdb.ReadContractCreation(db, address)
+	if creation == nil {
+		return nil, nil
+	}
+	return &ContractCreator{
+		Creator:         creation.Creator,
+		TransactionHash: creation.TxHash,
+		BlockHash:       creation.BlockHash,
+		BlockNumber:     hexutil.Uint64(creation.BlockNumber),
+	}, nil
+}
+
 // PrintBlock retrieves a block and returns its pretty printed form.
 func (api *DebugAPI) PrintBlock(ctx context.Context, number uint64) (string, error) {
 	block, _ := api.b.BlockByNumber(ctx, rpc.BlockNumber(number))
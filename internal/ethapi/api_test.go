@@ -38,11 +38,15 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/blocktest"
@@ -417,6 +421,7 @@ func newTestBackend(t *testing.T, n int, gspec *core.Genesis, engine consensus.E
 			TrieTimeLimit:     5 * time.Minute,
 			SnapshotLimit:     0,
 			TrieDirtyDisabled: true, // Archive mode
+			SenderIndex:       true,
 		}
 	)
 	// Generate blocks for testing
@@ -442,8 +447,14 @@ func (b testBackend) SyncProgress() ethereum.SyncProgress { return ethereum.Sync
 func (b testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return big.NewInt(0), nil
 }
-func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
-	return nil, nil, nil, nil, nil
+func (b testBackend) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	return nil, nil
+}
+func (b testBackend) SuggestFeeTiers(ctx context.Context) (*gasprice.FeeTiers, error) {
+	panic("implement me")
+}
+func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
+	return nil, nil, nil, nil, nil, nil, nil
 }
 func (b testBackend) ChainDb() ethdb.Database           { return b.db }
 func (b testBackend) AccountManager() *accounts.Manager { return nil }
@@ -530,6 +541,7 @@ func (b testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.R
 	receipts := rawdb.ReadReceipts(b.db, hash, header.Number.Uint64(), header.Time, b.chain.Config())
 	return receipts, nil
 }
+func (b testBackend) AddressIndexStatus() (uint64, uint64, bool) { return 0, 0, true }
 func (b testBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
 	if b.pending != nil && hash == b.pending.Hash() {
 		return nil
@@ -551,6 +563,9 @@ func (b testBackend) GetEVM(ctx context.Context, msg *core.Message, state *state
 func (b testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeFinalityEvent(ch chan<- core.FinalityEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	panic("implement me")
 }
@@ -560,25 +575,39 @@ func (b testBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) even
 func (b testBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	panic("implement me")
 }
+func (b testBackend) TrackTx(signedTx *types.Transaction, from common.Address) {}
 func (b testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
 	return tx, blockHash, blockNumber, index, nil
 }
+func (b testBackend) GetTransactionBySenderAndNonce(ctx context.Context, sender common.Address, nonce uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransactionBySenderAndNonce(b.db, sender, nonce)
+	return tx, blockHash, blockNumber, index, nil
+}
 func (b testBackend) GetPoolTransactions() (types.Transactions, error)         { panic("implement me") }
 func (b testBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction { panic("implement me") }
 func (b testBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	panic("implement me")
 }
+func (b testBackend) GetPoolDroppedTransaction(txHash common.Hash) *txpool.DroppedTx {
+	panic("implement me")
+}
+func (b testBackend) GetArchivedBlob(vhash common.Hash) *blobpool.ArchivedBlob {
+	panic("implement me")
+}
 func (b testBackend) Stats() (pending int, queued int) { panic("implement me") }
 func (b testBackend) TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
 	panic("implement me")
 }
 func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
-	panic("implement me")
+	return nil, nil
 }
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeDropTxsEvent(events chan<- txpool.DropTxsEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
 func (b testBackend) Engine() consensus.Engine         { return b.chain.Engine() }
 func (b testBackend) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
@@ -594,6 +623,7 @@ func (b testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Sub
 	panic("implement me")
 }
 func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
+func (b testBackend) LogIndex() *logindex.Index     { panic("implement me") }
 func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	panic("implement me")
 }
@@ -1638,3 +1668,219 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 	}
 	require.JSONEqf(t, string(want), string(data), "test %d: json not match, want: %s, have: %s", testid, string(want), string(data))
 }
+
+func TestRPCGetTransactionsByAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, txHashes = setupReceiptBackend(t, 6)
+		api               = NewTransactionAPI(backend, new(AddrLocker))
+		acc1Key, _        = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		acc2Key, _        = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+		acc1Addr          = crypto.PubkeyToAddress(acc1Key.PublicKey)
+		acc2Addr          = crypto.PubkeyToAddress(acc2Key.PublicKey)
+		contract          = common.HexToAddress("0000000000000000000000000000000000031ec7")
+	)
+	type activity struct {
+		hash  common.Hash
+		roles []string
+	}
+	query := func(address common.Address, args *AddressActivityArgs) ([]activity, *AddressActivityCursor) {
+		t.Helper()
+
+		result, err := api.GetTransactionsByAddress(context.Background(), address, args)
+		if err != nil {
+			t.Fatalf("failed to query activity of %x: %v", address, err)
+		}
+		var have []activity
+		for _, tx := range result.Transactions {
+			have = append(have, activity{tx.Hash, tx.Roles})
+		}
+		return have, result.Cursor
+	}
+	for i, tt := range []struct {
+		address common.Address
+		want    []activity
+	}{
+		{acc2Addr, []activity{{txHashes[0], []string{"recipient"}}, {txHashes[5], []string{"recipient"}}}},
+		{contract, []activity{{txHashes[2], []string{"recipient", "emitter"}}, {txHashes[3], []string{"recipient"}}}}, // second call reverts
+		{crypto.CreateAddress(acc1Addr, 1), []activity{{txHashes[1], []string{"created"}}}},
+		{common.Address{0xff}, nil},
+	} {
+		have, cursor := query(tt.address, nil)
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: activity mismatch: have %v, want %v", i, have, tt.want)
+		}
+		if cursor != nil {
+			t.Errorf("test %d: unexpected cursor %v", i, cursor)
+		}
+	}
+	// Page through the transactions of the sender
+	have, cursor := query(acc1Addr, &AddressActivityArgs{Limit: 4})
+	if len(have) != 4 || cursor == nil || *cursor != (AddressActivityCursor{BlockNumber: 4, TransactionIndex: 1}) {
+		t.Fatalf("first page mismatch: have %d transactions, cursor %v", len(have), cursor)
+	}
+	rest, cursor := query(acc1Addr, &AddressActivityArgs{Limit: 4, Cursor: cursor})
+	if cursor != nil {
+		t.Fatalf("unexpected cursor after last page: %v", cursor)
+	}
+	for i, tx := range append(have, rest...) {
+		if tx.hash != txHashes[i] || !reflect.DeepEqual(tx.roles, []string{"sender"}) {
+			t.Errorf("transaction %d mismatch: have %x %v, want %x [sender]", i, tx.hash, tx.roles, txHashes[i])
+		}
+	}
+	// Restrict the range of blocks to search
+	from, to := rpc.BlockNumber(2), rpc.BlockNumber(3)
+	if have, _ := query(acc1Addr, &AddressActivityArgs{FromBlock: &from, ToBlock: &to}); len(have) != 2 || have[0].hash != txHashes[1] {
+		t.Errorf("ranged activity mismatch: have %v", have)
+	}
+}
+
+func TestRPCGetTransactionBySenderAndNonce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, txHashes = setupReceiptBackend(t, 6)
+		api               = NewTransactionAPI(backend, new(AddrLocker))
+		acc1Key, _        = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		acc1Addr          = crypto.PubkeyToAddress(acc1Key.PublicKey)
+	)
+	for nonce, hash := range txHashes {
+		tx, err := api.GetTransactionBySenderAndNonce(context.Background(), acc1Addr, hexutil.Uint64(nonce))
+		if err != nil {
+			t.Fatalf("nonce %d: lookup failed: %v", nonce, err)
+		}
+		if tx == nil || tx.Hash != hash || tx.BlockNumber.ToInt().Uint64() != uint64(nonce+1) {
+			t.Fatalf("nonce %d: transaction mismatch: have %v, want %x in block %d", nonce, tx, hash, nonce+1)
+		}
+	}
+	if tx, err := api.GetTransactionBySenderAndNonce(context.Background(), acc1Addr, hexutil.Uint64(len(txHashes))); tx != nil || err != nil {
+		t.Fatalf("unused nonce: have %v, %v, want nil", tx, err)
+	}
+	// Without the sender index, unknown transactions are reported as unindexed
+	api = NewTransactionAPI(&testBackend{db: rawdb.NewMemoryDatabase(), chain: backend.chain}, new(AddrLocker))
+	if tx, err := api.GetTransactionBySenderAndNonce(context.Background(), acc1Addr, 0); tx != nil || err == nil {
+		t.Fatalf("unindexed lookup: have %v, %v, want error", tx, err)
+	}
+}
+
+// txPoolBackend is a backend serving a fixed transaction pool content.
+type txPoolBackend struct {
+	Backend
+	pending map[common.Address][]*types.Transaction
+	queued  map[common.Address][]*types.Transaction
+	dropped map[common.Hash]*txpool.DroppedTx
+}
+
+func (b *txPoolBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+
+func (b *txPoolBackend) Stats() (int, int) {
+	var pending, queued int
+	for _, txs := range b.pending {
+		pending += len(txs)
+	}
+	for _, txs := range b.queued {
+		queued += len(txs)
+	}
+	return pending, queued
+}
+
+func (b *txPoolBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	for _, content := range []map[common.Address][]*types.Transaction{b.pending, b.queued} {
+		for _, txs := range content {
+			for _, tx := range txs {
+				if tx.Hash() == hash {
+					return tx
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (b *txPoolBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	if txs := b.pending[addr]; len(txs) > 0 {
+		return txs[len(txs)-1].Nonce() + 1, nil
+	}
+	return 0, nil
+}
+
+func (b *txPoolBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	return b.pending[addr], b.queued[addr]
+}
+
+func (b *txPoolBackend) GetPoolDroppedTransaction(hash common.Hash) *txpool.DroppedTx {
+	return b.dropped[hash]
+}
+
+// Tests that the status of the pool and of single transactions is reported,
+// including the nonce gaps blocking queued transactions and the reasons for
+// dropped transactions.
+func TestTxPoolStatus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.TestChainConfig)
+		txs    = make([]*types.Transaction, 5)
+	)
+	for i := range txs {
+		txs[i] = types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), To: &common.Address{}, Gas: params.TxGas, GasPrice: big.NewInt(params.GWei)})
+	}
+	var (
+		replaced    = common.Hash{0x01}
+		underpriced = common.Hash{0x02}
+		backend     = &txPoolBackend{
+			pending: map[common.Address][]*types.Transaction{addr: {txs[0]}},
+			queued:  map[common.Address][]*types.Transaction{addr: {txs[2], txs[3]}},
+			dropped: map[common.Hash]*txpool.DroppedTx{
+				replaced:    {Hash: replaced, Reason: txpool.DropReplaced, Replacement: &common.Hash{0x03}},
+				underpriced: {Hash: underpriced, Reason: txpool.DropUnderpriced},
+			},
+		}
+		api = NewTxPoolAPI(backend)
+	)
+	status, err := api.Status(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve pool status: %v", err)
+	}
+	if want := map[string]hexutil.Uint{"pending": 1, "queued": 2}; !reflect.DeepEqual(status, want) {
+		t.Fatalf("pool status mismatch: have %v, want %v", status, want)
+	}
+	var tests = []struct {
+		hash common.Hash
+		want string
+	}{
+		{txs[0].Hash(), `{"status":"pending"}`},
+		{txs[2].Hash(), `{"status":"queued","nonceGap":"0x1"}`},
+		{txs[3].Hash(), `{"status":"queued","nonceGap":"0x1"}`},
+		{replaced, `{"status":"dropped","reason":"replaced","replacement":"0x0300000000000000000000000000000000000000000000000000000000000000"}`},
+		{underpriced, `{"status":"dropped","reason":"underpriced"}`},
+		{txs[4].Hash(), `{"status":"unknown"}`},
+	}
+	for i, tt := range tests {
+		status, err := api.Status(context.Background(), &tt.hash)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve status: %v", i, err)
+		}
+		have, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode status: %v", i, err)
+		}
+		if string(have) != tt.want {
+			t.Errorf("test %d: status mismatch: have %s, want %s", i, have, tt.want)
+		}
+	}
+	// Fill the nonce gap, the queued transactions become executable but are not
+	// yet promoted
+	backend.queued[addr] = []*types.Transaction{txs[1], txs[2], txs[3]}
+	hash := txs[3].Hash()
+	status, err = api.Status(context.Background(), &hash)
+	if err != nil {
+		t.Fatalf("failed to retrieve status: %v", err)
+	}
+	if have, _ := json.Marshal(status); string(have) != `{"status":"queued"}` {
+		t.Errorf("gapless status mismatch: have %s, want %s", have, `{"status":"queued"}`)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
 )
 
 // TransactionArgs represents the arguments to construct a new transaction
@@ -53,6 +54,10 @@ type TransactionArgs struct {
 	// Introduced by AccessListTxType transaction.
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// Introduced by BlobTxType transaction.
+	BlobFeeCap *hexutil.Big  `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
 }
 
 // from retrieves the transaction sender address.
@@ -95,6 +100,9 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 	if args.To == nil && len(args.data()) == 0 {
 		return errors.New(`contract creation without any data provided`)
 	}
+	if args.BlobHashes != nil && args.To == nil {
+		return errors.New(`missing "to" in blob transaction`)
+	}
 	// Estimate the gas usage if necessary.
 	if args.Gas == nil {
 		// These fields are immutable during the estimation, safe to
@@ -109,6 +117,8 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 			Value:                args.Value,
 			Data:                 (*hexutil.Bytes)(&data),
 			AccessList:           args.AccessList,
+			BlobFeeCap:           args.BlobFeeCap,
+			BlobHashes:           args.BlobHashes,
 		}
 		pendingBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 		estimated, err := DoEstimateGas(ctx, b, callArgs, pendingBlockNr, nil, b.RPCGasCap())
@@ -133,6 +143,10 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 
 // setFeeDefaults fills in default fee values for unspecified tx fields.
 func (args *TransactionArgs) setFeeDefaults(ctx context.Context, b Backend) error {
+	// Blob fees are independent of the execution fee mechanism, fill them first.
+	if err := args.setBlobFeeDefaults(ctx, b); err != nil {
+		return err
+	}
 	// If both gasPrice and at least one of the EIP-1559 fee parameters are specified, error.
 	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
 		return errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
@@ -169,6 +183,32 @@ func (args *TransactionArgs) setFeeDefaults(ctx context.Context, b Backend) erro
 	return nil
 }
 
+// setBlobFeeDefaults fills in a default blob fee cap for blob transactions if it
+// was not specified.
+func (args *TransactionArgs) setBlobFeeDefaults(ctx context.Context, b Backend) error {
+	if args.BlobFeeCap != nil && args.BlobFeeCap.ToInt().Sign() == 0 {
+		return errors.New("maxFeePerBlobGas, if specified, must be non-zero")
+	}
+	if args.BlobHashes != nil && args.GasPrice != nil {
+		return errors.New("blob transactions do not support gasPrice, use maxFeePerGas and maxPriorityFeePerGas")
+	}
+	if args.BlobHashes == nil || args.BlobFeeCap != nil {
+		return nil
+	}
+	fee, err := b.BlobBaseFee(ctx)
+	if err != nil {
+		return err
+	}
+	if fee == nil {
+		return errors.New("blob transactions are not valid before Cancun is active")
+	}
+	// Set the max fee to be 2 times larger than the next block's blob base fee.
+	// The additional slack allows the tx to not become invalidated if the blob
+	// base fee is rising.
+	args.BlobFeeCap = (*hexutil.Big)(new(big.Int).Mul(fee, big.NewInt(2)))
+	return nil
+}
+
 // setLondonFeeDefaults fills in reasonable default fee values for unspecified fields.
 func (args *TransactionArgs) setLondonFeeDefaults(ctx context.Context, head *types.Header, b Backend) error {
 	// Set maxPriorityFeePerGas if it is missing.
@@ -264,6 +304,12 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (*
 	if args.AccessList != nil {
 		accessList = *args.AccessList
 	}
+	var blobFeeCap *big.Int
+	if args.BlobFeeCap != nil {
+		blobFeeCap = args.BlobFeeCap.ToInt()
+	} else if args.BlobHashes != nil {
+		blobFeeCap = new(big.Int)
+	}
 	msg := &core.Message{
 		From:              addr,
 		To:                args.To,
@@ -274,6 +320,8 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (*
 		GasTipCap:         gasTipCap,
 		Data:              data,
 		AccessList:        accessList,
+		BlobGasFeeCap:     blobFeeCap,
+		BlobHashes:        args.BlobHashes,
 		SkipAccountChecks: true,
 	}
 	return msg, nil
@@ -284,6 +332,24 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (*
 func (args *TransactionArgs) toTransaction() *types.Transaction {
 	var data types.TxData
 	switch {
+	case args.BlobHashes != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		data = &types.BlobTx{
+			To:         *args.To,
+			ChainID:    toUint256(args.ChainID),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasFeeCap:  toUint256(args.MaxFeePerGas),
+			GasTipCap:  toUint256(args.MaxPriorityFeePerGas),
+			Value:      toUint256(args.Value),
+			Data:       args.data(),
+			AccessList: al,
+			BlobFeeCap: toUint256(args.BlobFeeCap),
+			BlobHashes: args.BlobHashes,
+		}
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -324,6 +390,16 @@ func (args *TransactionArgs) toTransaction() *types.Transaction {
 	return types.NewTx(data)
 }
 
+// toUint256 converts an optional argument into a uint256, treating a missing
+// value as zero. Arguments can not overflow, as hexutil.Big rejects values
+// exceeding 256 bits when decoding.
+func toUint256(b *hexutil.Big) *uint256.Int {
+	if b == nil {
+		return new(uint256.Int)
+	}
+	return uint256.MustFromBig((*big.Int)(b))
+}
+
 // ToTransaction converts the arguments to a transaction.
 // This assumes that setDefaults has been called.
 func (args *TransactionArgs) ToTransaction() *types.Transaction {
@@ -17,7 +17,9 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
 	"reflect"
@@ -31,9 +33,13 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -207,6 +213,74 @@ func TestSetFeeDefaults(t *testing.T) {
 	}
 }
 
+// TestSetBlobFeeDefaults tests the logic for filling in default blob fee values.
+func TestSetBlobFeeDefaults(t *testing.T) {
+	var (
+		b        = newBackendMock()
+		fortytwo = (*hexutil.Big)(big.NewInt(42))
+		hashes   = []common.Hash{{0x01}}
+	)
+	tests := []struct {
+		name     string
+		isCancun bool
+		in       *TransactionArgs
+		want     *hexutil.Big
+		err      string
+	}{
+		{"non-blob tx", true, &TransactionArgs{}, nil, ""},
+		{"blob tx, default fee cap", true, &TransactionArgs{BlobHashes: hashes}, (*hexutil.Big)(big.NewInt(14)), ""},
+		{"blob tx, explicit fee cap", true, &TransactionArgs{BlobHashes: hashes, BlobFeeCap: fortytwo}, fortytwo, ""},
+		{"blob tx, zero fee cap", true, &TransactionArgs{BlobHashes: hashes, BlobFeeCap: new(hexutil.Big)}, nil, "maxFeePerBlobGas, if specified, must be non-zero"},
+		{"blob tx, gas price", true, &TransactionArgs{BlobHashes: hashes, GasPrice: fortytwo}, nil, "blob transactions do not support gasPrice, use maxFeePerGas and maxPriorityFeePerGas"},
+		{"blob tx pre-Cancun", false, &TransactionArgs{BlobHashes: hashes}, nil, "blob transactions are not valid before Cancun is active"},
+	}
+	for i, test := range tests {
+		if test.isCancun {
+			b.activateCancun()
+		} else {
+			b.deactivateCancun()
+		}
+		err := test.in.setBlobFeeDefaults(context.Background(), b)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("test %d (%s): error mismatch: have %v, want %v", i, test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d (%s): unexpected error: %v", i, test.name, err)
+		}
+		if !reflect.DeepEqual(test.in.BlobFeeCap, test.want) {
+			t.Fatalf("test %d (%s): blob fee cap mismatch: have %v, want %v", i, test.name, test.in.BlobFeeCap, test.want)
+		}
+	}
+}
+
+// TestBlobTxArgs tests the conversion and encoding of blob transaction arguments
+// with missing fields.
+func TestBlobTxArgs(t *testing.T) {
+	var (
+		to    = common.Address{0x01}
+		nonce = hexutil.Uint64(1)
+		gas   = hexutil.Uint64(21000)
+		args  = &TransactionArgs{To: &to, Nonce: &nonce, Gas: &gas, BlobHashes: []common.Hash{{0x01}}}
+	)
+	tx := args.toTransaction()
+	if tx.Type() != types.BlobTxType {
+		t.Fatalf("transaction type mismatch: have %d, want %d", tx.Type(), types.BlobTxType)
+	}
+	if tx.GasFeeCap().Sign() != 0 || tx.GasTipCap().Sign() != 0 || tx.BlobGasFeeCap().Sign() != 0 || tx.Value().Sign() != 0 {
+		t.Fatalf("missing fields not zero: feecap %v, tipcap %v, blobfeecap %v, value %v", tx.GasFeeCap(), tx.GasTipCap(), tx.BlobGasFeeCap(), tx.Value())
+	}
+	enc, err := json.Marshal(&TransactionArgs{})
+	if err != nil {
+		t.Fatalf("failed to encode arguments: %v", err)
+	}
+	if bytes.Contains(enc, []byte("maxFeePerBlobGas")) {
+		t.Fatalf("missing blob fee cap encoded: %s", enc)
+	}
+}
+
 type backendMock struct {
 	current *types.Header
 	config  *params.ChainConfig
@@ -250,6 +324,15 @@ func (b *backendMock) activateLondon() {
 func (b *backendMock) deactivateLondon() {
 	b.current.Number = big.NewInt(900)
 }
+
+func (b *backendMock) activateCancun() {
+	excess := uint64(0)
+	b.current.ExcessBlobGas = &excess
+}
+
+func (b *backendMock) deactivateCancun() {
+	b.current.ExcessBlobGas = nil
+}
 func (b *backendMock) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return big.NewInt(42), nil
 }
@@ -258,8 +341,17 @@ func (b *backendMock) ChainConfig() *params.ChainConfig { return b.config }
 
 // Other methods needed to implement Backend interface.
 func (b *backendMock) SyncProgress() ethereum.SyncProgress { return ethereum.SyncProgress{} }
-func (b *backendMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
-	return nil, nil, nil, nil, nil
+func (b *backendMock) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	if b.current.ExcessBlobGas == nil {
+		return nil, nil
+	}
+	return big.NewInt(7), nil
+}
+func (b *backendMock) SuggestFeeTiers(ctx context.Context) (*gasprice.FeeTiers, error) {
+	return nil, nil
+}
+func (b *backendMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
+	return nil, nil, nil, nil, nil, nil, nil
 }
 func (b *backendMock) ChainDb() ethdb.Database           { return nil }
 func (b *backendMock) AccountManager() *accounts.Manager { return nil }
@@ -309,6 +401,9 @@ func (b *backendMock) GetEVM(ctx context.Context, msg *core.Message, state *stat
 	return nil, nil
 }
 func (b *backendMock) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription { return nil }
+func (b *backendMock) SubscribeFinalityEvent(ch chan<- core.FinalityEvent) event.Subscription {
+	return nil
+}
 func (b *backendMock) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
 	return nil
 }
@@ -316,24 +411,35 @@ func (b *backendMock) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) eve
 	return nil
 }
 func (b *backendMock) SendTx(ctx context.Context, signedTx *types.Transaction) error { return nil }
+func (b *backendMock) TrackTx(signedTx *types.Transaction, from common.Address)      {}
 func (b *backendMock) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
 	return nil, [32]byte{}, 0, 0, nil
 }
+func (b *backendMock) GetTransactionBySenderAndNonce(ctx context.Context, sender common.Address, nonce uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	return nil, [32]byte{}, 0, 0, nil
+}
 func (b *backendMock) GetPoolTransactions() (types.Transactions, error)         { return nil, nil }
 func (b *backendMock) GetPoolTransaction(txHash common.Hash) *types.Transaction { return nil }
 func (b *backendMock) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return 0, nil
 }
-func (b *backendMock) Stats() (pending int, queued int) { return 0, 0 }
+func (b *backendMock) GetPoolDroppedTransaction(txHash common.Hash) *txpool.DroppedTx { return nil }
+func (b *backendMock) GetArchivedBlob(vhash common.Hash) *blobpool.ArchivedBlob       { return nil }
+func (b *backendMock) Stats() (pending int, queued int)                               { return 0, 0 }
 func (b *backendMock) TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
 	return nil, nil
 }
 func (b *backendMock) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
 	return nil, nil
 }
-func (b *backendMock) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription      { return nil }
+func (b *backendMock) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription { return nil }
+func (b *backendMock) SubscribeDropTxsEvent(chan<- txpool.DropTxsEvent) event.Subscription {
+	return nil
+}
 func (b *backendMock) BloomStatus() (uint64, uint64)                                        { return 0, 0 }
 func (b *backendMock) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {}
+func (b *backendMock) LogIndex() *logindex.Index                                            { return nil }
+func (b *backendMock) AddressIndexStatus() (uint64, uint64, bool)                           { return 0, 0, false }
 func (b *backendMock) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription         { return nil }
 func (b *backendMock) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return nil
@@ -406,7 +406,7 @@ func testGetProofs(t *testing.T, protocol int) {
 	accounts := []common.Address{bankAddr, userAddr1, userAddr2, signerAddr, {}}
 	for i := uint64(0); i <= bc.CurrentBlock().Number.Uint64(); i++ {
 		header := bc.GetHeaderByNumber(i)
-		trie, _ := trie.New(trie.StateTrieID(header.Root), server.backend.Blockchain().TrieDB())
+		trie, _ := trie.New(trie.StateTrieID(header.Root), server.backend.TrieDB())
 
 		for _, acc := range accounts {
 			req := ProofReq{
@@ -457,7 +457,7 @@ func testGetStaleProof(t *testing.T, protocol int) {
 		var expected []rlp.RawValue
 		if wantOK {
 			proofsV2 := light.NewNodeSet()
-			t, _ := trie.New(trie.StateTrieID(header.Root), server.backend.Blockchain().TrieDB())
+			t, _ := trie.New(trie.StateTrieID(header.Root), server.backend.TrieDB())
 			t.Prove(account, proofsV2)
 			expected = proofsV2.NodeList()
 		}
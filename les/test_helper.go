@@ -21,14 +21,12 @@
 package les
 
 import (
-	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -39,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -97,13 +96,11 @@ contract test {
 }
 */
 
-// prepare pre-commits specified number customized blocks into chain.
-func prepare(n int, backend *backends.SimulatedBackend) {
-	var (
-		ctx    = context.Background()
-		signer = types.HomesteadSigner{}
-	)
-	for i := 0; i < n; i++ {
+// prepare generates the specified number of customized blocks on top of the
+// genesis block.
+func prepare(n int, gspec *core.Genesis) []*types.Block {
+	signer := types.HomesteadSigner{}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), n, func(i int, gen *core.BlockGen) {
 		switch i {
 		case 0:
 			// Builtin-block
@@ -111,60 +108,58 @@ func prepare(n int, backend *backends.SimulatedBackend) {
 			//    txs:    1
 
 			// bankUser transfers some ether to user1
-			nonce, _ := backend.PendingNonceAt(ctx, bankAddr)
-			tx, _ := types.SignTx(types.NewTransaction(nonce, userAddr1, big.NewInt(10_000_000_000_000_000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, bankKey)
-			backend.SendTransaction(ctx, tx)
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(bankAddr), userAddr1, big.NewInt(10_000_000_000_000_000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, bankKey)
+			gen.AddTx(tx)
 		case 1:
 			// Builtin-block
 			//    number: 2
 			//    txs:    4
 
-			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
-			userNonce1, _ := backend.PendingNonceAt(ctx, userAddr1)
+			bankNonce := gen.TxNonce(bankAddr)
+			userNonce1 := gen.TxNonce(userAddr1)
 
 			// bankUser transfers more ether to user1
 			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, userAddr1, big.NewInt(1_000_000_000_000_000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, bankKey)
-			backend.SendTransaction(ctx, tx1)
+			gen.AddTx(tx1)
 
 			// user1 relays ether to user2
 			tx2, _ := types.SignTx(types.NewTransaction(userNonce1, userAddr2, big.NewInt(1_000_000_000_000_000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, userKey1)
-			backend.SendTransaction(ctx, tx2)
+			gen.AddTx(tx2)
 
 			// user1 deploys a test contract
 			tx3, _ := types.SignTx(types.NewContractCreation(userNonce1+1, big.NewInt(0), 200000, big.NewInt(params.InitialBaseFee), testContractCode), signer, userKey1)
-			backend.SendTransaction(ctx, tx3)
+			gen.AddTx(tx3)
 			testContractAddr = crypto.CreateAddress(userAddr1, userNonce1+1)
 
 			// user1 deploys a event contract
 			tx4, _ := types.SignTx(types.NewContractCreation(userNonce1+2, big.NewInt(0), 200000, big.NewInt(params.InitialBaseFee), testEventEmitterCode), signer, userKey1)
-			backend.SendTransaction(ctx, tx4)
+			gen.AddTx(tx4)
 		case 2:
 			// Builtin-block
 			//    number: 3
 			//    txs:    2
 
 			// bankUser transfer some ether to signer
-			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
+			bankNonce := gen.TxNonce(bankAddr)
 			tx1, _ := types.SignTx(types.NewTransaction(bankNonce, signerAddr, big.NewInt(1000000000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, bankKey)
-			backend.SendTransaction(ctx, tx1)
+			gen.AddTx(tx1)
 
 			// invoke test contract
 			data := common.Hex2Bytes("C16431B900000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001")
 			tx2, _ := types.SignTx(types.NewTransaction(bankNonce+1, testContractAddr, big.NewInt(0), 100000, big.NewInt(params.InitialBaseFee), data), signer, bankKey)
-			backend.SendTransaction(ctx, tx2)
+			gen.AddTx(tx2)
 		case 3:
 			// Builtin-block
 			//    number: 4
 			//    txs:    1
 
 			// invoke test contract
-			bankNonce, _ := backend.PendingNonceAt(ctx, bankAddr)
 			data := common.Hex2Bytes("C16431B900000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000002")
-			tx, _ := types.SignTx(types.NewTransaction(bankNonce, testContractAddr, big.NewInt(0), 100000, big.NewInt(params.InitialBaseFee), data), signer, bankKey)
-			backend.SendTransaction(ctx, tx)
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(bankAddr), testContractAddr, big.NewInt(0), 100000, big.NewInt(params.InitialBaseFee), data), signer, bankKey)
+			gen.AddTx(tx)
 		}
-		backend.Commit()
-	}
+	})
+	return blocks
 }
 
 // testIndexers creates a set of indexers with specified params for testing purpose.
@@ -178,7 +173,7 @@ func testIndexers(db ethdb.Database, odr light.OdrBackend, config *light.Indexer
 	return indexers[:]
 }
 
-func newTestClientHandler(backend *backends.SimulatedBackend, odr *LesOdr, indexers []*core.ChainIndexer, db ethdb.Database, peers *serverPeerSet) (*clientHandler, func()) {
+func newTestClientHandler(odr *LesOdr, indexers []*core.ChainIndexer, db ethdb.Database, peers *serverPeerSet) (*clientHandler, func()) {
 	var (
 		evmux  = new(event.TypeMux)
 		engine = ethash.NewFaker()
@@ -218,7 +213,7 @@ func newTestClientHandler(backend *backends.SimulatedBackend, odr *LesOdr, index
 	}
 }
 
-func newTestServerHandler(blocks int, indexers []*core.ChainIndexer, db ethdb.Database, clock mclock.Clock) (*serverHandler, *backends.SimulatedBackend, func()) {
+func newTestServerHandler(blocks int, indexers []*core.ChainIndexer, db ethdb.Database, clock mclock.Clock) (*serverHandler, *core.BlockChain, func()) {
 	var (
 		gspec = core.Genesis{
 			Config:   params.AllEthashProtocolChanges,
@@ -227,17 +222,22 @@ func newTestServerHandler(blocks int, indexers []*core.ChainIndexer, db ethdb.Da
 			BaseFee:  big.NewInt(params.InitialBaseFee),
 		}
 	)
-	genesis := gspec.MustCommit(db, trie.NewDatabase(db, trie.HashDefaults))
+	// create a blockchain and pre-commit several customized block to the database.
+	chain, err := core.NewBlockChain(db, nil, &gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := chain.InsertChain(prepare(blocks, &gspec)); err != nil {
+		panic(err)
+	}
 
-	// create a simulation backend and pre-commit several customized block to the database.
-	simulation := backends.NewSimulatedBackendWithDatabase(db, gspec.Alloc, 100000000)
-	prepare(blocks, simulation)
+	genesis := chain.Genesis()
 
 	txpoolConfig := legacypool.DefaultConfig
 	txpoolConfig.Journal = ""
 
-	pool := legacypool.New(txpoolConfig, simulation.Blockchain())
-	txpool, _ := txpool.New(new(big.Int).SetUint64(txpoolConfig.PriceLimit), simulation.Blockchain(), []txpool.SubPool{pool})
+	pool := legacypool.New(txpoolConfig, chain)
+	txpool, _ := txpool.New(new(big.Int).SetUint64(txpoolConfig.PriceLimit), chain, []txpool.SubPool{pool})
 
 	server := &LesServer{
 		lesCommons: lesCommons{
@@ -246,7 +246,7 @@ func newTestServerHandler(blocks int, indexers []*core.ChainIndexer, db ethdb.Da
 			chainConfig: params.AllEthashProtocolChanges,
 			iConfig:     light.TestServerIndexerConfig,
 			chainDb:     db,
-			chainReader: simulation.Blockchain(),
+			chainReader: chain,
 			closeCh:     make(chan struct{}),
 		},
 		peers:        newClientPeerSet(),
@@ -262,11 +262,11 @@ func newTestServerHandler(blocks int, indexers []*core.ChainIndexer, db ethdb.Da
 	server.clientPool = vfs.NewClientPool(db, testBufRecharge, defaultConnectedBias, clock, alwaysTrueFn)
 	server.clientPool.Start()
 	server.clientPool.SetLimits(10000, 10000) // Assign enough capacity for clientpool
-	server.handler = newServerHandler(server, simulation.Blockchain(), db, txpool, func() bool { return true })
+	server.handler = newServerHandler(server, chain, db, txpool, func() bool { return true })
 	server.servingQueue.setThreads(4)
 	server.handler.start()
 	closer := func() { server.Stop() }
-	return server.handler, simulation, closer
+	return server.handler, chain, closer
 }
 
 func alwaysTrueFn() bool {
@@ -460,7 +460,7 @@ type testClient struct {
 // testServer represents a server object for testing with necessary auxiliary fields.
 type testServer struct {
 	clock   mclock.Clock
-	backend *backends.SimulatedBackend
+	backend *core.BlockChain
 	db      ethdb.Database
 	peer    *testPeer
 	handler *serverHandler
@@ -553,7 +553,7 @@ func newClientServerEnv(t *testing.T, config testnetConfig) (*testServer, *testC
 	odr.SetIndexers(ccIndexer, cbIndexer, cbtIndexer)
 
 	server, b, serverClose := newTestServerHandler(config.blocks, sindexers, sdb, clock)
-	client, clientClose := newTestClientHandler(b, odr, cIndexers, cdb, speers)
+	client, clientClose := newTestClientHandler(odr, cIndexers, cdb, speers)
 
 	scIndexer.Start(server.blockchain)
 	sbIndexer.Start(server.blockchain)
@@ -611,7 +611,7 @@ func newClientServerEnv(t *testing.T, config testnetConfig) (*testServer, *testC
 		sbIndexer.Close()
 		dist.close()
 		serverClose()
-		b.Close()
+		b.Stop()
 		clientClose()
 	}
 	return s, c, teardown
@@ -172,15 +172,17 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 	}
 
 	// Determine config.
+	rpcConfig, err := api.node.publicRPCConfig()
+	if err != nil {
+		return false, err
+	}
 	config := httpConfig{
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
-		rpcEndpointConfig: rpcEndpointConfig{
-			batchItemLimit:         api.node.config.BatchRequestLimit,
-			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
-		},
+		rpcEndpointConfig:  rpcConfig,
 	}
+	config.methodFilter = newMethodFilter(api.node.config.HTTPMethods)
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
 		for _, origin := range strings.Split(*cors, ",") {
@@ -250,15 +252,17 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 	}
 
 	// Determine config.
+	rpcConfig, err := api.node.publicRPCConfig()
+	if err != nil {
+		return false, err
+	}
 	config := wsConfig{
 		Modules: api.node.config.WSModules,
 		Origins: api.node.config.WSOrigins,
 		// ExposeAll: api.node.config.WSExposeAll,
-		rpcEndpointConfig: rpcEndpointConfig{
-			batchItemLimit:         api.node.config.BatchRequestLimit,
-			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
-		},
+		rpcEndpointConfig: rpcConfig,
 	}
+	config.methodFilter = newMethodFilter(api.node.config.WSMethods)
 	if apis != nil {
 		config.Modules = nil
 		for _, m := range strings.Split(*apis, ",") {
@@ -102,6 +102,12 @@ type Config struct {
 	// relative), then that specific path is enforced. An empty path disables IPC.
 	IPCPath string
 
+	// IPCMethods restricts the methods callable via the IPC endpoint. Entries are
+	// method names or prefixes ending in '*', entries starting with '!' deny the
+	// matching methods. If the list has no allowing entries, all methods not
+	// denied can be called.
+	IPCMethods []string `toml:",omitempty"`
+
 	// HTTPHost is the host interface on which to start the HTTP RPC server. If this
 	// field is empty, no HTTP API endpoint will be started.
 	HTTPHost string
@@ -130,6 +136,10 @@ type Config struct {
 	// exposed.
 	HTTPModules []string
 
+	// HTTPMethods restricts the methods callable via the HTTP RPC interface on top
+	// of HTTPModules, in the same format as IPCMethods.
+	HTTPMethods []string `toml:",omitempty"`
+
 	// HTTPTimeouts allows for customization of the timeout values used by the HTTP RPC
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
@@ -169,6 +179,10 @@ type Config struct {
 	// exposed.
 	WSModules []string
 
+	// WSMethods restricts the methods callable via the websocket RPC interface on
+	// top of WSModules, in the same format as IPCMethods.
+	WSMethods []string `toml:",omitempty"`
+
 	// WSExposeAll exposes all API modules via the WebSocket RPC interface rather
 	// than just the public ones.
 	//
@@ -204,9 +218,30 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCRateLimits are the call budgets granted to every client of the HTTP and
+	// WebSocket endpoints. A client shares its budgets across both endpoints.
+	RPCRateLimits []rpc.RateLimit `toml:",omitempty"`
+
+	// RPCRateLimitIdentity selects how clients are told apart for rate limiting:
+	// "ip" (default), "jwt" for the subject of their token verified by one of the
+	// RPCJWTKeys, or "header:<name>" for an API key header listed in the
+	// RPCRateLimitKeyFile.
+	RPCRateLimitIdentity string `toml:",omitempty"`
+
+	// RPCRateLimitKeyFile is the path to the file listing the API keys accepted
+	// as client identities, one per line. Clients sending other keys are
+	// identified by their IP address.
+	RPCRateLimitKeyFile string `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
+	// RPCJWTKeys maps key names to the paths of hex-encoded JWT secrets or PEM
+	// encoded public keys. If set, the HTTP and websocket RPC interfaces require
+	// tokens signed with one of the keys, which may restrict the methods callable
+	// by the client.
+	RPCJWTKeys map[string]string `toml:",omitempty"`
+
 	// EnablePersonal enables the deprecated personal namespace.
 	EnablePersonal bool `toml:"-"`
 
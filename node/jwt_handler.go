@@ -17,17 +17,134 @@
 package node
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
 
 const jwtExpiryTimeout = 60 * time.Second
 
+// readOnlyMethods are the methods permitted to clients with read-only tokens, as
+// they neither change the state of the node nor access its file system.
+var readOnlyMethods = []string{
+	"debug_accountRange",
+	"debug_chaindbProperty",
+	"debug_dumpBlock",
+	"debug_gcStats",
+	"debug_getAccessibleState",
+	"debug_getBadBlocks",
+	"debug_getContractCreator",
+	"debug_getModifiedAccountsByHash",
+	"debug_getModifiedAccountsByNumber",
+	"debug_getRaw*",
+	"debug_getTrieFlushInterval",
+	"debug_intermediateRoots",
+	"debug_memStats",
+	"debug_preimage",
+	"debug_printBlock",
+	"debug_stacks",
+	"debug_storageRangeAt",
+	"debug_subscribe",
+	"debug_traceBadBlock",
+	"debug_traceBlock",
+	"debug_traceBlockByHash",
+	"debug_traceBlockByNumber",
+	"debug_traceCall",
+	"debug_traceTransaction",
+	"eth_accounts",
+	"eth_blobBaseFee",
+	"eth_blockNumber",
+	"eth_call",
+	"eth_chainId",
+	"eth_coinbase",
+	"eth_createAccessList",
+	"eth_estimateGas",
+	"eth_etherbase",
+	"eth_feeHistory",
+	"eth_feeRecommendations",
+	"eth_gasPrice",
+	"eth_get*",
+	"eth_hashrate",
+	"eth_maxPriorityFeePerGas",
+	"eth_mining",
+	"eth_newBlockFilter",
+	"eth_newFilter",
+	"eth_newPendingTransactionFilter",
+	"eth_pendingTransactions",
+	"eth_subscribe",
+	"eth_syncing",
+	"eth_uninstallFilter",
+	"net_*",
+	"rpc_modules",
+	"txpool_*",
+	"web3_*",
+}
+
+// jwtClaims are the claims of the tokens accepted by the RPC endpoints. Tokens
+// signed with named keys may restrict the methods the client can call.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Namespaces []string `json:"namespaces,omitempty"` // API namespaces the client may use
+	Methods    []string `json:"methods,omitempty"`    // Methods the client may call, '*' suffix for prefixes
+	ReadOnly   bool     `json:"readonly,omitempty"`   // Restricts the client to the read-only methods
+}
+
+// methodFilter returns the filter restricting the client to the permitted
+// methods. Tokens without namespaces or methods allow all of them.
+func (c *jwtClaims) methodFilter() *rpc.MethodFilter {
+	filter := new(rpc.MethodFilter)
+	for _, namespace := range c.Namespaces {
+		filter.Allow = append(filter.Allow, namespace+"_*")
+	}
+	filter.Allow = append(filter.Allow, c.Methods...)
+	if c.ReadOnly {
+		filter.Allow = intersectMethods(filter.Allow, readOnlyMethods)
+		if len(filter.Allow) == 0 {
+			filter.Deny = []string{"*"} // None of the permitted methods are read-only
+		}
+	}
+	return filter
+}
+
+// intersectMethods returns the patterns matching the methods matched by both of
+// the pattern lists, where an empty list matches all methods.
+func intersectMethods(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	var patterns []string
+	for _, p := range a {
+		for _, q := range b {
+			pprefix, pwild := strings.CutSuffix(p, "*")
+			qprefix, qwild := strings.CutSuffix(q, "*")
+			switch {
+			case pwild && strings.HasPrefix(q, pprefix):
+				patterns = append(patterns, q)
+			case qwild && strings.HasPrefix(p, qprefix):
+				patterns = append(patterns, p)
+			case p == q:
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	return patterns
+}
+
 type jwtHandler struct {
 	keyFunc func(token *jwt.Token) (interface{}, error)
+	methods []string // signing methods accepted
+	scoped  bool     // whether tokens carry the permissions of the client
 	next    http.Handler
 }
 
@@ -37,7 +154,32 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 		keyFunc: func(token *jwt.Token) (interface{}, error) {
 			return secret, nil
 		},
-		next: next,
+		methods: []string{"HS256"},
+		next:    next,
+	}
+}
+
+// newScopedJWTHandler creates a http.Handler authenticating requests with tokens
+// signed by any of the named keys, selected by the 'kid' header of the token.
+// The claims of the token restrict the methods the client can call, and tokens
+// with an expiry may be used until then, regardless of their issuance time.
+func newScopedJWTHandler(keys map[string]interface{}, next http.Handler) http.Handler {
+	return &jwtHandler{
+		keyFunc: func(token *jwt.Token) (interface{}, error) {
+			name, _ := token.Header["kid"].(string)
+			if name == "" && len(keys) == 1 {
+				for _, key := range keys {
+					return key, nil
+				}
+			}
+			if key, ok := keys[name]; ok {
+				return key, nil
+			}
+			return nil, fmt.Errorf("unknown key %q", name)
+		},
+		methods: []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
+		scoped:  true,
+		next:    next,
 	}
 }
 
@@ -45,7 +187,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -54,11 +196,11 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		http.Error(out, "missing token", http.StatusUnauthorized)
 		return
 	}
-	// We explicitly set only HS256 allowed, and also disables the
+	// We explicitly set the allowed signing methods, and also disable the
 	// claim-check: the RegisteredClaims internally requires 'iat' to
 	// be no later than 'now', but we allow for a bit of drift.
 	token, err := jwt.ParseWithClaims(strToken, &claims, handler.keyFunc,
-		jwt.WithValidMethods([]string{"HS256"}),
+		jwt.WithValidMethods(handler.methods),
 		jwt.WithoutClaimsValidation())
 
 	switch {
@@ -68,6 +210,9 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		http.Error(out, "invalid token", http.StatusUnauthorized)
 	case !claims.VerifyExpiresAt(time.Now(), false): // optional
 		http.Error(out, "token is expired", http.StatusUnauthorized)
+	case handler.scoped && claims.ExpiresAt != nil:
+		// Scoped tokens are bounded by their expiry if they have one
+		handler.serve(out, r, &claims)
 	case claims.IssuedAt == nil:
 		http.Error(out, "missing issued-at", http.StatusUnauthorized)
 	case time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
@@ -75,6 +220,74 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusUnauthorized)
 	default:
-		handler.next.ServeHTTP(out, r)
+		handler.serve(out, r, &claims)
+	}
+}
+
+// serve passes an authenticated request on, restricted to the methods permitted
+// by the token if it's scoped.
+func (handler *jwtHandler) serve(out http.ResponseWriter, r *http.Request, claims *jwtClaims) {
+	if claims.Subject != "" {
+		r = r.WithContext(context.WithValue(r.Context(), jwtSubjectKey{}, claims.Subject))
+	}
+	if handler.scoped {
+		r = r.WithContext(rpc.WithMethodFilter(r.Context(), claims.methodFilter()))
+
+		// Websocket connections outliving the token are closed when it expires
+		if claims.ExpiresAt != nil && isWebsocket(r) {
+			ctx, cancel := context.WithDeadline(r.Context(), claims.ExpiresAt.Time)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+	}
+	handler.next.ServeHTTP(out, r)
+}
+
+// loadJWTKeys loads the named JWT keys from the given files, which contain either
+// a hex-encoded secret of at least 32 bytes or a PEM-encoded RSA, ECDSA or
+// Ed25519 public key.
+func loadJWTKeys(files map[string]string) (map[string]interface{}, error) {
+	keys := make(map[string]interface{}, len(files))
+	for name, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %q: %v", name, err)
+		}
+		key, err := parseJWTKey(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT key %q: %v", name, err)
+		}
+		keys[name] = key
 	}
+	return keys, nil
+}
+
+// parseJWTKey parses a hex-encoded secret or PEM-encoded public key.
+func parseJWTKey(data string) (interface{}, error) {
+	if !strings.HasPrefix(data, "-----BEGIN") {
+		secret := common.FromHex(data)
+		if len(secret) < 32 {
+			return nil, errors.New("secret shorter than 32 bytes")
+		}
+		return secret, nil
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(data)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM([]byte(data)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM([]byte(data)); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported public key")
+}
+
+type jwtSubjectKey struct{}
+
+// jwtSubject returns the subject of the JWT the request was authenticated with
+// by a jwtHandler, or an empty string if the request wasn't authenticated.
+func jwtSubject(r *http.Request) string {
+	subject, _ := r.Context().Value(jwtSubjectKey{}).(string)
+	return subject
 }
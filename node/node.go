@@ -64,6 +64,8 @@ type Node struct {
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
+	rpcLimiter *rpc.RateLimiter // Call budgets of the clients, shared by the HTTP and WS endpoints
+
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
 
@@ -152,7 +154,7 @@ func New(conf *Config) (*Node, error) {
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
-	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.ipc = newIPCServer(node.log, conf.IPCEndpoint(), newMethodFilter(conf.IPCMethods))
 
 	return node, nil
 }
@@ -339,6 +341,46 @@ func (n *Node) closeDataDir() {
 	}
 }
 
+// publicRPCConfig returns the settings shared by the public HTTP and websocket
+// RPC endpoints. The caller must hold n.lock.
+func (n *Node) publicRPCConfig() (rpcEndpointConfig, error) {
+	var (
+		keys    map[string]interface{}
+		apiKeys []string
+		err     error
+	)
+	if len(n.config.RPCJWTKeys) > 0 {
+		if keys, err = loadJWTKeys(n.config.RPCJWTKeys); err != nil {
+			return rpcEndpointConfig{}, err
+		}
+	}
+	if n.config.RPCRateLimitKeyFile != "" {
+		data, err := os.ReadFile(n.config.RPCRateLimitKeyFile)
+		if err != nil {
+			return rpcEndpointConfig{}, fmt.Errorf("failed to read API keys: %v", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if key := strings.TrimSpace(line); key != "" {
+				apiKeys = append(apiKeys, key)
+			}
+		}
+	}
+	identity, err := newRateLimitIdentity(n.config.RPCRateLimitIdentity, len(keys) > 0, apiKeys)
+	if err != nil {
+		return rpcEndpointConfig{}, err
+	}
+	// Create the limiter once, so a client has the same budgets on all endpoints
+	if n.rpcLimiter == nil && len(n.config.RPCRateLimits) > 0 {
+		n.rpcLimiter = rpc.NewRateLimiter(n.config.RPCRateLimits, identity)
+	}
+	return rpcEndpointConfig{
+		batchItemLimit:         n.config.BatchRequestLimit,
+		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		rateLimiter:            n.rpcLimiter,
+		jwtKeys:                keys,
+	}, nil
+}
+
 // obtainJWTSecret loads the jwt-secret, either from the provided config,
 // or from the default location. If neither of those are present, it generates
 // a new secret and stores to the default location.
@@ -404,22 +446,24 @@ func (n *Node) startRPC() error {
 		openAPIs, allAPIs = n.getAPIs()
 	)
 
-	rpcConfig := rpcEndpointConfig{
-		batchItemLimit:         n.config.BatchRequestLimit,
-		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+	rpcConfig, err := n.publicRPCConfig()
+	if err != nil {
+		return err
 	}
 
 	initHttp := func(server *httpServer, port int) error {
 		if err := server.setListenAddr(n.config.HTTPHost, port); err != nil {
 			return err
 		}
-		if err := server.enableRPC(openAPIs, httpConfig{
+		config := httpConfig{
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
 			rpcEndpointConfig:  rpcConfig,
-		}); err != nil {
+		}
+		config.methodFilter = newMethodFilter(n.config.HTTPMethods)
+		if err := server.enableRPC(openAPIs, config); err != nil {
 			return err
 		}
 		servers = append(servers, server)
@@ -431,12 +475,14 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.WSHost, port); err != nil {
 			return err
 		}
-		if err := server.enableWS(openAPIs, wsConfig{
+		config := wsConfig{
 			Modules:           n.config.WSModules,
 			Origins:           n.config.WSOrigins,
 			prefix:            n.config.WSPathPrefix,
 			rpcEndpointConfig: rpcConfig,
-		}); err != nil {
+		}
+		config.methodFilter = newMethodFilter(n.config.WSMethods)
+		if err := server.enableWS(openAPIs, config); err != nil {
 			return err
 		}
 		servers = append(servers, server)
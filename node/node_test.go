@@ -479,6 +479,40 @@ func TestWebsocketHTTPOnSeparatePort_WSRequest(t *testing.T) {
 	}
 }
 
+// Tests that a client shares its call budgets across the HTTP and WS endpoints.
+func TestRateLimitsSharedAcrossEndpoints(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("can't listen:", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	node := createNode(t, 0, port)
+	node.config.RPCRateLimits = []rpc.RateLimit{{Methods: []string{"rpc_modules"}, Rate: 0.001, Burst: 1}}
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	defer node.Close()
+
+	var modules map[string]string
+	for i, endpoint := range []string{node.HTTPEndpoint(), node.WSEndpoint()} {
+		client, err := rpc.Dial(endpoint)
+		if err != nil {
+			t.Fatalf("could not dial %s: %v", endpoint, err)
+		}
+		err = client.Call(&modules, "rpc_modules")
+		client.Close()
+
+		if i == 0 && err != nil {
+			t.Fatalf("first call failed: %v", err)
+		}
+		if i == 1 && err == nil {
+			t.Fatalf("call on the other endpoint not limited")
+		}
+	}
+}
+
 type rpcPrefixTest struct {
 	httpPrefix, wsPrefix string
 	// These lists paths on which JSON-RPC should be served / not served.
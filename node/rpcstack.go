@@ -56,6 +56,9 @@ type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
 	batchItemLimit         int
 	batchResponseSizeLimit int
+	rateLimiter            *rpc.RateLimiter       // optional call budgets of the clients
+	methodFilter           *rpc.MethodFilter      // optional restriction of the callable methods
+	jwtKeys                map[string]interface{} // optional named JWT keys, tokens carry permissions
 }
 
 type rpcHandler struct {
@@ -171,7 +174,7 @@ func (h *httpServer) start() error {
 	}
 	// Log http endpoint.
 	h.log.Info("HTTP server started",
-		"endpoint", listener.Addr(), "auth", (h.httpConfig.jwtSecret != nil),
+		"endpoint", listener.Addr(), "auth", (h.httpConfig.jwtSecret != nil || len(h.httpConfig.jwtKeys) > 0),
 		"prefix", h.httpConfig.prefix,
 		"cors", strings.Join(h.httpConfig.CorsAllowedOrigins, ","),
 		"vhosts", strings.Join(h.httpConfig.Vhosts, ","),
@@ -304,12 +307,18 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetRateLimiter(config.rateLimiter)
+	srv.SetMethodFilter(config.methodFilter)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
 	h.httpConfig = config
+	handler := NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret)
+	if len(config.jwtKeys) > 0 {
+		handler = newScopedJWTHandler(config.jwtKeys, handler)
+	}
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -336,12 +345,18 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetRateLimiter(config.rateLimiter)
+	srv.SetMethodFilter(config.methodFilter)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
 	h.wsConfig = config
+	handler := NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret)
+	if len(config.jwtKeys) > 0 {
+		handler = newScopedJWTHandler(config.jwtKeys, handler)
+	}
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -385,6 +400,66 @@ func isWebsocket(r *http.Request) bool {
 		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
 }
 
+// newRateLimitIdentity returns the function identifying the clients of an RPC
+// endpoint for rate limiting. Supported kinds are "ip" to use their IP address,
+// "jwt" to use the subject of their verified JWT, and "header:<name>" to use the
+// value of an API key header, if it's one of the accepted keys. Clients without
+// a verified JWT or an accepted API key fall back to their IP, so that they can't
+// obtain fresh budgets by making up identities.
+func newRateLimitIdentity(kind string, verifiesJWT bool, apiKeys []string) (func(*http.Request) string, error) {
+	switch {
+	case kind == "" || kind == "ip":
+		return nil, nil
+	case kind == "jwt":
+		if !verifiesJWT {
+			return nil, fmt.Errorf("rate limit identity %q requires JWT keys", kind)
+		}
+		return func(r *http.Request) string {
+			if subject := jwtSubject(r); subject != "" {
+				return "jwt:" + subject
+			}
+			return ""
+		}, nil
+	case strings.HasPrefix(kind, "header:") && len(kind) > len("header:"):
+		if len(apiKeys) == 0 {
+			return nil, fmt.Errorf("rate limit identity %q requires API keys", kind)
+		}
+		var (
+			header   = http.CanonicalHeaderKey(strings.TrimPrefix(kind, "header:"))
+			accepted = make(map[string]bool, len(apiKeys))
+		)
+		for _, key := range apiKeys {
+			accepted[key] = true
+		}
+		return func(r *http.Request) string {
+			if key := r.Header.Get(header); accepted[key] {
+				return "key:" + key
+			}
+			return ""
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid rate limit client identity %q", kind)
+	}
+}
+
+// newMethodFilter creates the filter restricting an endpoint to the methods
+// matching the given patterns, where patterns starting with '!' deny the methods
+// instead. It returns nil if there are no patterns.
+func newMethodFilter(patterns []string) *rpc.MethodFilter {
+	if len(patterns) == 0 {
+		return nil
+	}
+	filter := new(rpc.MethodFilter)
+	for _, pattern := range patterns {
+		if denied, ok := strings.CutPrefix(pattern, "!"); ok {
+			filter.Deny = append(filter.Deny, denied)
+		} else {
+			filter.Allow = append(filter.Allow, pattern)
+		}
+	}
+	return filter
+}
+
 // NewHTTPHandlerStack returns wrapped http-related handlers
 func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte) http.Handler {
 	// Wrap the CORS-handler within a host-handler
@@ -579,14 +654,15 @@ func newGzipHandler(next http.Handler) http.Handler {
 type ipcServer struct {
 	log      log.Logger
 	endpoint string
+	filter   *rpc.MethodFilter
 
 	mu       sync.Mutex
 	listener net.Listener
 	srv      *rpc.Server
 }
 
-func newIPCServer(log log.Logger, endpoint string) *ipcServer {
-	return &ipcServer{log: log, endpoint: endpoint}
+func newIPCServer(log log.Logger, endpoint string, filter *rpc.MethodFilter) *ipcServer {
+	return &ipcServer{log: log, endpoint: endpoint, filter: filter}
 }
 
 // Start starts the httpServer's http.Server
@@ -597,7 +673,12 @@ func (is *ipcServer) start(apis []rpc.API) error {
 	if is.listener != nil {
 		return nil // already running
 	}
-	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis)
+	srv := rpc.NewServer()
+	srv.SetMethodFilter(is.filter)
+	if err := RegisterApis(apis, nil, srv); err != nil {
+		return err
+	}
+	listener, err := srv.ServeIPC(is.endpoint)
 	if err != nil {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
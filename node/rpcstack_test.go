@@ -18,16 +18,23 @@ package node
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -614,3 +621,217 @@ func (s *testService) Greet() string {
 func (s *testService) Sleep() {
 	time.Sleep(1500 * time.Millisecond)
 }
+
+func TestRateLimitIdentity(t *testing.T) {
+	var (
+		secret = bytes.Repeat([]byte{0x01}, 32)
+		token  = func(key []byte) string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}).SignedString(key)
+			return token
+		}
+	)
+	tests := []struct {
+		kind    string
+		headers map[string]string
+		want    string
+	}{
+		{"jwt", map[string]string{"Authorization": "Bearer " + token(secret)}, "jwt:alice"},
+		{"jwt", nil, ""},
+		{"header:x-api-key", map[string]string{"X-Api-Key": "k1"}, "key:k1"},
+		{"header:x-api-key", map[string]string{"X-Api-Key": "k3"}, ""},
+		{"header:x-api-key", map[string]string{"Authorization": "Bearer " + token(secret)}, ""},
+	}
+	for i, tt := range tests {
+		identify, err := newRateLimitIdentity(tt.kind, true, []string{"k1", "k2"})
+		if err != nil {
+			t.Fatalf("test %d: failed to create identity: %v", i, err)
+		}
+		var have string
+		handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			have = identify(r)
+		}))
+		if tt.kind == "jwt" && tt.headers != nil {
+			handler = newScopedJWTHandler(map[string]interface{}{"k": secret}, handler)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		for key, value := range tt.headers {
+			r.Header.Set(key, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if have != tt.want {
+			t.Errorf("test %d: identity mismatch: have %q, want %q", i, have, tt.want)
+		}
+	}
+	// Subjects of tokens that aren't verified are ignored
+	identify, _ := newRateLimitIdentity("jwt", true, nil)
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token([]byte("forged")))
+	if have := identify(r); have != "" {
+		t.Errorf("unverified token identified as %q", have)
+	}
+	if identify, err := newRateLimitIdentity("ip", false, nil); identify != nil || err != nil {
+		t.Errorf("ip identity: have %v, %v, want nil", identify != nil, err)
+	}
+	for _, kind := range []string{"header:", "cookie"} {
+		if _, err := newRateLimitIdentity(kind, true, []string{"k1"}); err == nil {
+			t.Errorf("invalid identity %q accepted", kind)
+		}
+	}
+	if _, err := newRateLimitIdentity("jwt", false, nil); err == nil {
+		t.Error("jwt identity accepted without verified tokens")
+	}
+	if _, err := newRateLimitIdentity("header:x-api-key", true, nil); err == nil {
+		t.Error("header identity accepted without API keys")
+	}
+}
+
+func TestScopedJWT(t *testing.T) {
+	var (
+		dir          = t.TempDir()
+		secret       = bytes.Repeat([]byte{0x01}, 32)
+		pub, priv, _ = ed25519.GenerateKey(nil)
+	)
+	der, _ := x509.MarshalPKIXPublicKey(pub)
+	os.WriteFile(filepath.Join(dir, "secret"), []byte(hexutil.Encode(secret)), 0600)
+	os.WriteFile(filepath.Join(dir, "pub.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+	os.WriteFile(filepath.Join(dir, "short"), []byte("0x0102"), 0600)
+
+	if _, err := loadJWTKeys(map[string]string{"short": filepath.Join(dir, "short")}); err == nil {
+		t.Fatal("short secret accepted")
+	}
+	keys, err := loadJWTKeys(map[string]string{"team": filepath.Join(dir, "secret"), "ops": filepath.Join(dir, "pub.pem")})
+	if err != nil {
+		t.Fatalf("failed to load keys: %v", err)
+	}
+	cfg := rpcEndpointConfig{jwtKeys: keys, methodFilter: newMethodFilter([]string{"!test_sleep"})}
+	srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: cfg}, false, nil, nil)
+	defer srv.stop()
+	htUrl := fmt.Sprintf("http://%v", srv.listenAddr())
+
+	issue := func(kid string, method jwt.SigningMethod, key interface{}, claims testClaim) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+	var (
+		now    = time.Now().Unix()
+		later  = time.Now().Add(time.Hour).Unix()
+		hs256  = jwt.SigningMethodHS256
+		eddsa  = jwt.SigningMethodEdDSA
+		denied = strconv.Itoa(-32004)
+		absent = strconv.Itoa(-32601)
+	)
+	tests := []struct {
+		token  string
+		method string
+		status int
+		code   string // JSON-RPC error code, empty for success
+	}{
+		// Long-lived tokens restricted to methods or namespaces
+		{issue("team", hs256, secret, testClaim{"exp": later, "methods": []string{"test_greet"}}), "test_greet", http.StatusOK, ""},
+		{issue("team", hs256, secret, testClaim{"exp": later, "methods": []string{"test_greet"}}), testMethod, http.StatusOK, denied},
+		{issue("ops", eddsa, priv, testClaim{"exp": later, "namespaces": []string{"rpc"}}), testMethod, http.StatusOK, ""},
+		{issue("ops", eddsa, priv, testClaim{"exp": later, "namespaces": []string{"rpc"}}), "test_greet", http.StatusOK, denied},
+		{issue("team", hs256, secret, testClaim{"exp": later, "readonly": true}), testMethod, http.StatusOK, ""},
+		{issue("team", hs256, secret, testClaim{"exp": later, "readonly": true}), "test_greet", http.StatusOK, denied},
+		// Unrestricted tokens are still subject to the endpoint filter
+		{issue("team", hs256, secret, testClaim{"iat": now}), "test_greet", http.StatusOK, ""},
+		{issue("team", hs256, secret, testClaim{"iat": now}), "test_sleep", http.StatusOK, absent},
+		// Tokens without expiry must be fresh, and the key must match
+		{issue("team", hs256, secret, testClaim{}), "test_greet", http.StatusUnauthorized, ""},
+		{issue("team", hs256, secret, testClaim{"exp": now - 1}), "test_greet", http.StatusUnauthorized, ""},
+		{issue("", hs256, secret, testClaim{"iat": now}), "test_greet", http.StatusUnauthorized, ""},
+		{issue("ops", hs256, secret, testClaim{"iat": now}), "test_greet", http.StatusUnauthorized, ""},
+		{issue("unknown", hs256, secret, testClaim{"iat": now}), "test_greet", http.StatusUnauthorized, ""},
+	}
+	for i, tt := range tests {
+		resp := rpcRequest(t, htUrl, tt.method, "Authorization", tt.token)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, resp.StatusCode, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		hasErr := strings.Contains(string(body), `"error"`)
+		if tt.code == "" && hasErr {
+			t.Errorf("test %d: unexpected error: %s", i, body)
+		}
+		if tt.code != "" && !strings.Contains(string(body), `"code":`+tt.code) {
+			t.Errorf("test %d: expected error %s, got %s", i, tt.code, body)
+		}
+	}
+}
+
+func TestReadOnlyJWT(t *testing.T) {
+	tests := []struct {
+		claims  jwtClaims
+		allowed []string
+		denied  []string
+	}{
+		{
+			claims:  jwtClaims{ReadOnly: true},
+			allowed: []string{"eth_getBalance", "eth_call", "debug_traceTransaction", "debug_getRawBlock", "net_version"},
+			denied: []string{
+				"eth_sendRawTransaction", "eth_sign", "admin_addPeer", "debug_setHead",
+				"debug_cpuProfile", "debug_goTrace", "debug_blockProfile", "debug_mutexProfile",
+				"debug_standardTraceBlockToFile", "debug_standardTraceBadBlockToFile", "debug_traceBlockFromFile",
+			},
+		},
+		{
+			claims:  jwtClaims{ReadOnly: true, Namespaces: []string{"debug"}, Methods: []string{"eth_get*"}},
+			allowed: []string{"debug_traceCall", "eth_getLogs"},
+			denied:  []string{"eth_call", "debug_cpuProfile", "debug_writeMemProfile"},
+		},
+		{
+			claims: jwtClaims{ReadOnly: true, Namespaces: []string{"admin"}},
+			denied: []string{"admin_peers", "eth_call"},
+		},
+	}
+	for i, tt := range tests {
+		filter := tt.claims.methodFilter()
+		for _, method := range tt.allowed {
+			if !filter.Permits(method) {
+				t.Errorf("test %d: %s denied", i, method)
+			}
+		}
+		for _, method := range tt.denied {
+			if filter.Permits(method) {
+				t.Errorf("test %d: %s permitted", i, method)
+			}
+		}
+	}
+}
+
+// Tests that websocket connections authenticated by scoped tokens are closed when
+// their token expires.
+func TestScopedJWTExpiry(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x01}, 32)
+	cfg := rpcEndpointConfig{jwtKeys: map[string]interface{}{"team": secret}}
+	srv := createAndStartServer(t, &httpConfig{}, true, &wsConfig{Origins: []string{"*"}, rpcEndpointConfig: cfg}, nil)
+	defer srv.stop()
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"exp": time.Now().Add(2 * time.Second).Unix()}).SignedString(secret)
+	client, err := rpc.DialOptions(context.Background(), fmt.Sprintf("ws://%v", srv.listenAddr()), rpc.WithHeader("Authorization", "Bearer "+token))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	var modules map[string]string
+	if err := client.Call(&modules, testMethod); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	time.Sleep(3 * time.Second)
+	if err := client.Call(&modules, testMethod); err == nil {
+		t.Fatal("call succeeded after token expiry")
+	}
+}
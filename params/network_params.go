@@ -32,6 +32,14 @@ const (
 	// considered probably final and its rotated bits are calculated.
 	BloomConfirms = 256
 
+	// AddressIndexBlocks is the number of blocks a single address activity index
+	// section contains. Newer blocks are searched without the index.
+	AddressIndexBlocks uint64 = 512
+
+	// AddressIndexConfirms is the number of confirmation blocks before an address
+	// activity index section is considered probably final and gets indexed.
+	AddressIndexConfirms = 64
+
 	// CHTFrequency is the block frequency for creating CHTs
 	CHTFrequency = 32768
 
@@ -0,0 +1,83 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+)
+
+// MethodFilter restricts the methods which may be called. Patterns match a method
+// by its full name, or by prefix if they end with '*', e.g. "debug_trace*".
+type MethodFilter struct {
+	Allow []string // Methods allowed, all of them if empty
+	Deny  []string // Methods denied, taking precedence over the allowed ones
+}
+
+// Permits reports whether the filter allows calling the method. A nil filter
+// permits everything.
+func (f *MethodFilter) Permits(method string) bool {
+	if f == nil {
+		return true
+	}
+	if matchMethod(f.Deny, method) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchMethod(f.Allow, method)
+}
+
+// matchMethod reports whether any of the patterns matches the method.
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(method, prefix) {
+				return true
+			}
+		} else if pattern == method {
+			return true
+		}
+	}
+	return false
+}
+
+type methodFilterContextKey struct{}
+
+// WithMethodFilter returns a copy of the HTTP request context restricting the
+// calls of the client to the methods permitted by the filter, on top of the
+// restrictions of the server. It allows authentication middleware to apply the
+// permissions of each client.
+func WithMethodFilter(ctx context.Context, filter *MethodFilter) context.Context {
+	return context.WithValue(ctx, methodFilterContextKey{}, filter)
+}
+
+// methodFilterFromContext returns the filter of the client set on the context.
+func methodFilterFromContext(ctx context.Context) *MethodFilter {
+	filter, _ := ctx.Value(methodFilterContextKey{}).(*MethodFilter)
+	return filter
+}
+
+// permitted returns an error if the call of the method is prohibited by the
+// filter of the server or the client making the call.
+func (h *handler) permitted(ctx context.Context, method string) error {
+	if !h.filter.Permits(method) {
+		return &methodNotFoundError{method: method}
+	}
+	if !PeerInfoFromContext(ctx).filter.Permits(method) {
+		return &accessDeniedError{method: method}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodFilter(t *testing.T) {
+	t.Parallel()
+
+	filter := &MethodFilter{
+		Allow: []string{"eth_*", "debug_traceTransaction"},
+		Deny:  []string{"eth_send*"},
+	}
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"eth_call", true},
+		{"eth_sendRawTransaction", false},
+		{"debug_traceTransaction", true},
+		{"debug_traceTransactionX", false},
+		{"debug_setHead", false},
+	}
+	for _, tt := range tests {
+		if have := filter.Permits(tt.method); have != tt.want {
+			t.Errorf("%s: permitted mismatch: have %v, want %v", tt.method, have, tt.want)
+		}
+	}
+	if !(*MethodFilter)(nil).Permits("debug_setHead") {
+		t.Error("nil filter denied method")
+	}
+	if !(&MethodFilter{Deny: []string{"admin_*"}}).Permits("eth_call") {
+		t.Error("filter without allow list denied method")
+	}
+}
+
+func TestServerMethodFilter(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetMethodFilter(&MethodFilter{Deny: []string{"test_null"}})
+
+	// Restrict the clients sending a token header to the echo methods
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "" {
+			r = r.WithContext(WithMethodFilter(r.Context(), &MethodFilter{Allow: []string{"test_echo*", "nftest_*"}, Deny: []string{"nftest_subscribe"}}))
+		}
+		if strings.HasPrefix(r.URL.Path, "/ws") {
+			server.WebsocketHandler([]string{"*"}).ServeHTTP(w, r)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	checkCode := func(err error, code int) {
+		t.Helper()
+		if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != code {
+			t.Fatalf("wrong error: have %v, want code %d", err, code)
+		}
+	}
+	for _, url := range []string{ts.URL, "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"} {
+		client, err := DialOptions(context.Background(), url, WithHeader("X-Token", "1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result echoResult
+		if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+			t.Fatalf("%s: permitted call failed: %v", url, err)
+		}
+		checkCode(client.Call(nil, "test_null"), -32601)
+		checkCode(client.Call(nil, "test_rets"), errcodeAccessDenied)
+		if strings.HasPrefix(url, "ws") {
+			_, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 1, 1)
+			checkCode(err, errcodeAccessDenied)
+		}
+		client.Close()
+
+		// Clients without the token are only subject to the server filter
+		client, err = Dial(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCode(client.Call(nil, "test_null"), -32601)
+		if err := client.Call(nil, "test_rets"); err != nil {
+			t.Fatalf("%s: unrestricted call failed: %v", url, err)
+		}
+		client.Close()
+	}
+}
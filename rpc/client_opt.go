@@ -40,6 +40,8 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	limiter            *RateLimiter
+	filter             *MethodFilter
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -43,10 +43,9 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 	}
 	log.Debug("IPCs registered", "namespaces", strings.Join(registered, ","))
 	// All APIs registered, start the IPC listener.
-	listener, err := ipcListen(ipcEndpoint)
+	listener, err := handler.ServeIPC(ipcEndpoint)
 	if err != nil {
 		return nil, nil, err
 	}
-	go handler.ServeListener(listener)
 	return listener, handler, nil
 }
@@ -62,6 +62,8 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	limiter              *RateLimiter  // call budgets of the client, nil if unlimited
+	filter               *MethodFilter // methods which may be called, nil if all
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -225,6 +227,10 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 				break
 			}
 			resp := h.handleCallMsg(cp, msg)
+			if resp != nil && resp.stream != nil {
+				// Batch responses are written at once, so streamed results are buffered
+				resp = resp.buffered()
+			}
 			callBuffer.pushResponse(resp)
 			if resp != nil && h.batchResponseMaxSize != 0 {
 				responseBytes += len(resp.Result)
@@ -302,6 +308,7 @@ func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 		responded.Do(func() {
 			h.conn.writeJSON(cp.ctx, answer, false)
 		})
+		answer.finish()
 	}
 	for _, n := range cp.notifiers {
 		n.activate()
@@ -462,7 +469,7 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	start := time.Now()
 	switch {
 	case msg.isNotification():
-		h.handleCall(ctx, msg)
+		h.handleCall(ctx, msg).finish()
 		h.log.Debug("Served "+msg.Method, "duration", time.Since(start))
 		return nil
 
@@ -490,7 +497,7 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 }
 
 // handleCall processes method calls.
-func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) (answer *jsonrpcMessage) {
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -503,13 +510,33 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if callb != h.unsubscribeCb {
+		if err := h.permitted(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
+	if h.limiter != nil && callb != h.unsubscribeCb {
+		release, err := h.limiter.acquire(msg.Method, limitClient(cp.ctx))
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		// Streamed results are produced while they're written after the call
+		// returns, so the call holds its slot until then
+		defer func() {
+			if answer.stream != nil {
+				answer.done = release
+			} else {
+				release()
+			}
+		}()
+	}
 
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	answer = h.runMethod(cp.ctx, msg, callb, args)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -533,6 +560,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
 
+	if err := h.permitted(cp.ctx, msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
 	if err != nil {
@@ -543,6 +573,13 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	if h.limiter != nil {
+		release, err := h.limiter.acquire(msg.Method, limitClient(cp.ctx))
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
@@ -291,7 +291,11 @@ func newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
 	dec := json.NewDecoder(conn)
 	dec.UseNumber()
 
-	return NewFuncCodec(conn, encoder, dec.Decode)
+	codec := NewFuncCodec(conn, encoder, dec.Decode).(*jsonCodec)
+	codec.stream = func() (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	}
+	return codec
 }
 
 // Close does nothing and always returns nil.
@@ -323,6 +327,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	if s.limiter != nil && s.limiter.identify != nil {
+		connInfo.client = s.limiter.identify(r)
+	}
+	connInfo.filter = methodFilterFromContext(r.Context())
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
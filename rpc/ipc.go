@@ -39,6 +39,17 @@ func (s *Server) ServeListener(l net.Listener) error {
 	}
 }
 
+// ServeIPC opens an IPC listener on the given endpoint and serves JSON-RPC on the
+// accepted connections in the background until the listener is closed.
+func (s *Server) ServeIPC(endpoint string) (net.Listener, error) {
+	listener, err := ipcListen(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	go s.ServeListener(listener)
+	return listener, nil
+}
+
 // DialIPC create a new IPC client that connects to the given endpoint. On Unix it assumes
 // the endpoint is the full path to a unix socket, and Windows the endpoint is an
 // identifier for a named pipe.
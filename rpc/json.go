@@ -55,6 +55,9 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	stream StreamedResult // result encoded while writing the message, if Result is nil
+	done   func()         // releases the call of a streamed result once written
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
@@ -102,6 +105,9 @@ func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 }
 
 func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
+	if stream, ok := result.(StreamedResult); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, stream: stream}
+	}
 	enc, err := json.Marshal(result)
 	if err != nil {
 		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
@@ -173,6 +179,7 @@ type jsonCodec struct {
 	decode  decodeFunc       // decoder to allow multiple transports
 	encMu   sync.Mutex       // guards the encoder
 	encode  encodeFunc       // encoder to allow multiple transports
+	stream  streamFunc       // writer for streamed results, buffered if nil
 	conn    deadlineCloser
 }
 
@@ -206,7 +213,11 @@ func NewCodec(conn Conn) ServerCodec {
 	encode := func(v interface{}, isErrorResponse bool) error {
 		return enc.Encode(v)
 	}
-	return NewFuncCodec(conn, encode, dec.Decode)
+	codec := NewFuncCodec(conn, encode, dec.Decode).(*jsonCodec)
+	codec.stream = func() (io.WriteCloser, error) {
+		return nopWriteCloser{conn}, nil
+	}
+	return codec
 }
 
 func (c *jsonCodec) peerInfo() PeerInfo {
@@ -245,6 +256,12 @@ func (c *jsonCodec) writeJSON(ctx context.Context, v interface{}, isErrorRespons
 		deadline = time.Now().Add(defaultWriteTimeout)
 	}
 	c.conn.SetWriteDeadline(deadline)
+	if msg, ok := v.(*jsonrpcMessage); ok && msg.stream != nil {
+		if c.stream != nil {
+			return c.writeStream(msg)
+		}
+		v = msg.buffered()
+	}
 	return c.encode(v, isErrorResponse)
 }
 
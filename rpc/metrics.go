@@ -32,6 +32,12 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// limitedMeterName is the prefix of the per-method meters of calls rejected
+	// by a rate or concurrency limit.
+	limitedMeterName = "rpc/limited"
+
+	rpcLimitedMeter = metrics.NewRegisteredMeter(limitedMeterName+"/all", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -0,0 +1,185 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// budgetPruneInterval is the interval at which the budgets of idle clients are
+// dropped to bound the memory used by the limiter.
+const budgetPruneInterval = time.Minute
+
+// RateLimit is a call budget for a group of methods, granted to every client
+// separately. A call is rejected if it would exceed any budget covering its
+// method.
+type RateLimit struct {
+	Methods    []string // Methods covered, a trailing '*' matches all methods with the prefix
+	Rate       float64  // Sustained calls per second, zero for no rate limit
+	Burst      int      // Calls allowed at once on top of the sustained rate, at least one
+	Concurrent int      // Calls allowed to run at the same time, zero for no limit
+}
+
+// budgetKey identifies the budget of a single client for a rate limit.
+type budgetKey struct {
+	limit  int
+	client string
+}
+
+// budget is the remaining allowance of a single client for a rate limit.
+type budget struct {
+	tokens  float64        // Calls allowed before the rate limit kicks in
+	updated mclock.AbsTime // Time the tokens were last refilled
+	active  int            // Number of calls currently running
+}
+
+// RateLimiter enforces the call budgets of the clients of one or more servers.
+// Servers sharing a limiter also share the budgets of each client across them.
+type RateLimiter struct {
+	limits   []RateLimit
+	identify func(*http.Request) string
+	clock    mclock.Clock
+
+	lock    sync.Mutex
+	budgets map[budgetKey]*budget
+	pruned  mclock.AbsTime
+}
+
+// NewRateLimiter creates a limiter granting the given call budgets to every client.
+// The identify function returns the identity of the client sending an HTTP request
+// or opening a WebSocket connection, e.g. based on an API key header. Clients are
+// identified by their IP address if it's nil or returns an empty identity.
+func NewRateLimiter(limits []RateLimit, identify func(*http.Request) string) *RateLimiter {
+	limits = append([]RateLimit(nil), limits...)
+	for i := range limits {
+		if limits[i].Burst < 1 {
+			limits[i].Burst = 1
+		}
+	}
+	return &RateLimiter{
+		limits:   limits,
+		identify: identify,
+		clock:    mclock.System{},
+		budgets:  make(map[budgetKey]*budget),
+	}
+}
+
+// acquire takes a call of the method from the budgets of the client, returning
+// a function to release the call once it's done. An error is returned if any of
+// the budgets is exhausted, in which case nothing is taken.
+func (l *RateLimiter) acquire(method string, client string) (func(), error) {
+	var keys []budgetKey
+	for i := range l.limits {
+		if matchMethod(l.limits[i].Methods, method) {
+			keys = append(keys, budgetKey{i, client})
+		}
+	}
+	if len(keys) == 0 {
+		return func() {}, nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	if time.Duration(now-l.pruned) >= budgetPruneInterval {
+		l.prune(now)
+	}
+	budgets := make([]*budget, len(keys))
+	for i, key := range keys {
+		limit := &l.limits[key.limit]
+		b := l.budgets[key]
+		if b == nil {
+			b = &budget{tokens: float64(limit.Burst), updated: now}
+			l.budgets[key] = b
+		}
+		if limit.Rate > 0 {
+			b.tokens += time.Duration(now-b.updated).Seconds() * limit.Rate
+			if b.tokens > float64(limit.Burst) {
+				b.tokens = float64(limit.Burst)
+			}
+			b.updated = now
+			if b.tokens < 1 {
+				return nil, l.reject(method, "rate")
+			}
+		}
+		if limit.Concurrent > 0 && b.active >= limit.Concurrent {
+			return nil, l.reject(method, "concurrency")
+		}
+		budgets[i] = b
+	}
+	for i, b := range budgets {
+		if l.limits[keys[i].limit].Rate > 0 {
+			b.tokens--
+		}
+		b.active++
+	}
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		for _, b := range budgets {
+			b.active--
+		}
+	}, nil
+}
+
+// reject records a call exceeding the given kind of limit.
+func (l *RateLimiter) reject(method string, kind string) error {
+	rpcLimitedMeter.Mark(1)
+	metrics.GetOrRegisterMeter(limitedMeterName+"/"+method, nil).Mark(1)
+	return &limitExceededError{method: method, limit: kind}
+}
+
+// prune drops the budgets of the clients which have no calls running and have
+// their full allowance back, so forgetting them loses nothing.
+func (l *RateLimiter) prune(now mclock.AbsTime) {
+	for key, b := range l.budgets {
+		if b.active > 0 {
+			continue
+		}
+		limit := &l.limits[key.limit]
+		if limit.Rate > 0 && b.tokens+time.Duration(now-b.updated).Seconds()*limit.Rate < float64(limit.Burst) {
+			continue
+		}
+		delete(l.budgets, key)
+	}
+	l.pruned = now
+}
+
+// limitClient returns the identity of the client making a call for rate limiting,
+// which is the identity assigned to the connection, or the IP address of the
+// client otherwise. Local clients are identified by their transport.
+func limitClient(ctx context.Context) string {
+	info := PeerInfoFromContext(ctx)
+	if info.client != "" {
+		return info.client
+	}
+	if info.RemoteAddr == "" {
+		return info.Transport
+	}
+	if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+		return host
+	}
+	return info.RemoteAddr
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	clock := new(mclock.Simulated)
+	limiter := NewRateLimiter([]RateLimit{
+		{Methods: []string{"debug_trace*"}, Rate: 1, Burst: 2},
+		{Methods: []string{"debug_*", "eth_getLogs"}, Concurrent: 2},
+	}, nil)
+	limiter.clock = clock
+
+	acquire := func(method, client string, ok bool) func() {
+		t.Helper()
+
+		release, err := limiter.acquire(method, client)
+		if ok && err != nil {
+			t.Fatalf("%s by %s rejected: %v", method, client, err)
+		}
+		if !ok && err == nil {
+			t.Fatalf("%s by %s not rejected", method, client)
+		}
+		if err != nil {
+			var rpcErr Error
+			if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+				t.Fatalf("wrong error for %s by %s: %v", method, client, err)
+			}
+		}
+		return release
+	}
+	// Use up the burst of the rate limit, ensuring other clients and methods
+	// are not affected
+	acquire("debug_traceBlock", "a", true)()
+	acquire("debug_traceCall", "a", true)()
+	acquire("debug_traceCall", "a", false)
+	acquire("debug_traceCall", "b", true)()
+	acquire("eth_call", "a", true)()
+
+	// Refill the allowance partially, then entirely
+	clock.Run(time.Second)
+	acquire("debug_traceCall", "a", true)()
+	acquire("debug_traceCall", "a", false)
+	clock.Run(10 * time.Second)
+	acquire("debug_traceCall", "a", true)()
+	acquire("debug_traceCall", "a", true)()
+
+	// Fill the concurrency limit, ensuring rejected calls don't take from the
+	// rate limit of the same method
+	release1 := acquire("eth_getLogs", "a", true)
+	release2 := acquire("debug_getRawBlock", "a", true)
+	clock.Run(10 * time.Second)
+	acquire("debug_traceCall", "a", false)
+	acquire("eth_getLogs", "b", true)()
+	release1()
+	acquire("debug_traceCall", "a", true)()
+	acquire("debug_traceCall", "a", true)()
+	acquire("debug_traceCall", "a", false)
+	release2()
+
+	// Ensure idle clients with their full allowance back are forgotten
+	clock.Run(budgetPruneInterval)
+	acquire("eth_getLogs", "c", true)()
+	if len(limiter.budgets) != 1 {
+		t.Fatalf("budgets not pruned: have %d, want %d", len(limiter.budgets), 1)
+	}
+}
+
+func TestServerRateLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetRateLimits([]RateLimit{{Methods: []string{"test_echo"}, Rate: 0.001, Burst: 2}}, func(r *http.Request) string {
+		return r.Header.Get("X-Api-Key")
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	dial := func(key string) *Client {
+		client, err := Dial(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" {
+			client.SetHeader("X-Api-Key", key)
+		}
+		return client
+	}
+	var (
+		keyA   = dial("a")
+		keyB   = dial("b")
+		result echoResult
+	)
+	defer keyA.Close()
+	defer keyB.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := keyA.Call(&result, "test_echo", "x", 1); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	err := keyA.Call(&result, "test_echo", "x", 1)
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error for exceeded limit: %v", err)
+	}
+	if err := keyA.Call(nil, "test_null"); err != nil {
+		t.Fatalf("unlimited method failed: %v", err)
+	}
+	if err := keyB.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call by other client failed: %v", err)
+	}
+	// Clients without an API key fall back to their IP address
+	anon := dial("")
+	defer anon.Close()
+	for i := 0; i < 2; i++ {
+		if err := anon.Call(&result, "test_echo", "x", 1); err != nil {
+			t.Fatalf("anonymous call %d failed: %v", i, err)
+		}
+	}
+	if err := anon.Call(&result, "test_echo", "x", 1); err == nil {
+		t.Fatal("anonymous call not limited")
+	}
+}
@@ -19,6 +19,7 @@ package rpc
 import (
 	"context"
 	"io"
+	"net/http"
 	"sync"
 	"sync/atomic"
 
@@ -51,6 +52,8 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	limiter            *RateLimiter
+	filter             *MethodFilter
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -78,6 +81,38 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetRateLimits sets call budgets granted to every client of the server, see
+// NewRateLimiter for the identification of clients.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimits(limits []RateLimit, identify func(*http.Request) string) {
+	if len(limits) == 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = NewRateLimiter(limits, identify)
+}
+
+// SetRateLimiter sets the limiter enforcing the call budgets of the clients of
+// the server. Passing the same limiter to multiple servers makes every client
+// share its budgets across them. A nil limiter removes all limits.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.limiter = limiter
+}
+
+// SetMethodFilter restricts the methods which may be called on the server. Calls
+// of prohibited methods fail as if the methods didn't exist.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetMethodFilter(filter *MethodFilter) {
+	s.filter = filter
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -103,6 +138,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		limiter:            s.limiter,
+		filter:             s.filter,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -138,6 +175,8 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.limiter = s.limiter
+	h.filter = s.filter
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -210,6 +249,9 @@ type PeerInfo struct {
 		Origin    string
 		Host      string
 	}
+
+	client string        // Identity of the client for rate limiting, if not the IP address
+	filter *MethodFilter // Methods the client may call, on top of the server filter
 }
 
 type peerInfoContextKey struct{}
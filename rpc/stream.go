@@ -0,0 +1,173 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// StreamedResult is a method result which is encoded while it's written to the
+// client, instead of being marshaled in memory in full. Methods with very large
+// results return it to keep their memory use bounded.
+//
+// The result is written to HTTP, WebSocket and IPC connections incrementally, as
+// fast as the client reads it. Results within batches are still encoded in memory.
+// Errors should be reported by the method before returning the result: once parts
+// of it have been written, an encoding error can only abort the response.
+//
+// The call counts towards the concurrency limits of its method until the result
+// has been written. On WebSocket and IPC connections, writing may take as long as
+// the client keeps reading; over HTTP, the write timeout of the server bounds the
+// whole response, after which the result is truncated.
+type StreamedResult interface {
+	// StreamJSON writes the JSON encoding of the result to w.
+	StreamJSON(w io.Writer) error
+}
+
+// StreamFunc is an adapter to allow the use of ordinary functions as streamed
+// results.
+type StreamFunc func(w io.Writer) error
+
+// StreamJSON implements StreamedResult.
+func (f StreamFunc) StreamJSON(w io.Writer) error {
+	return f(w)
+}
+
+// MarshalJSON encodes the result in memory, for callers using it as an ordinary
+// value.
+func (f StreamFunc) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// StreamArray returns a result encoding the items returned by next as a JSON array,
+// requesting each item only once the previous one has been written. The iterator
+// returns io.EOF after the last item.
+func StreamArray(next func() (interface{}, error)) StreamFunc {
+	return func(w io.Writer) error {
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		for i := 0; ; i++ {
+			item, err := next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "]")
+		return err
+	}
+}
+
+// streamFunc returns the writer of a message written in parts, which is complete
+// once the writer is closed.
+type streamFunc = func() (io.WriteCloser, error)
+
+// nopWriteCloser is a writer of a message which needs no completion.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// deadlineWriter extends the write deadline of the connection before every write,
+// so writing a streamed result may take as long as the client keeps reading it.
+// This has no effect over HTTP, where the write timeout of the server applies.
+type deadlineWriter struct {
+	w    io.Writer
+	conn deadlineCloser
+}
+
+func (dw *deadlineWriter) Write(p []byte) (int, error) {
+	dw.conn.SetWriteDeadline(time.Now().Add(defaultWriteTimeout))
+	return dw.w.Write(p)
+}
+
+// streamTo writes the response with its streamed result to w.
+func (msg *jsonrpcMessage) streamTo(w io.Writer) error {
+	// Encode the envelope without the result, then splice the result in
+	head, err := json.Marshal(&jsonrpcMessage{Version: msg.Version, ID: msg.ID})
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.Write(head[:len(head)-1])
+	bw.WriteString(`,"result":`)
+	if err := msg.stream.StreamJSON(bw); err != nil {
+		return err
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// finish releases the resources held by the call of a streamed response, once the
+// response has been written or discarded.
+func (msg *jsonrpcMessage) finish() {
+	if msg != nil && msg.done != nil {
+		msg.done()
+		msg.done = nil
+	}
+}
+
+// buffered returns the response with its streamed result encoded in memory, for
+// writing it as part of a batch or on transports not supporting streaming.
+func (msg *jsonrpcMessage) buffered() *jsonrpcMessage {
+	defer msg.finish()
+
+	var buf bytes.Buffer
+	if err := msg.stream.StreamJSON(&buf); err != nil {
+		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
+	}
+	if !json.Valid(buf.Bytes()) {
+		return msg.errorResponse(&internalServerError{errcodeMarshalError, "invalid streamed result"})
+	}
+	return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: buf.Bytes()}
+}
+
+// writeStream writes a response with a streamed result to the connection. As
+// the message is incomplete if writing fails midway, the connection is closed.
+func (c *jsonCodec) writeStream(msg *jsonrpcMessage) error {
+	w, err := c.stream()
+	if err != nil {
+		return err
+	}
+	err = msg.streamTo(&deadlineWriter{w: w, conn: c.conn})
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		c.close()
+	}
+	return err
+}
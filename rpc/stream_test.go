@@ -0,0 +1,207 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type streamService struct {
+	started chan struct{} // signaled when Wait starts streaming
+	unblock chan struct{} // closed to let Wait complete
+}
+
+// Count streams the numbers up to n, failing after fail items if it's positive.
+func (s *streamService) Count(n int, fail int) StreamedResult {
+	i := 0
+	return StreamArray(func() (interface{}, error) {
+		if fail > 0 && i == fail {
+			return nil, errors.New("stream failed")
+		}
+		if i == n {
+			return nil, io.EOF
+		}
+		i++
+		return i, nil
+	})
+}
+
+// Wait streams a single item once unblocked.
+func (s *streamService) Wait() StreamedResult {
+	done := false
+	return StreamArray(func() (interface{}, error) {
+		if done {
+			return nil, io.EOF
+		}
+		s.started <- struct{}{}
+		<-s.unblock
+		done = true
+		return 1, nil
+	})
+}
+
+func TestStreamedResultEncoding(t *testing.T) {
+	t.Parallel()
+
+	msg := &jsonrpcMessage{Version: vsn, ID: json.RawMessage("7"), stream: new(streamService).Count(3, 0)}
+	var buf bytes.Buffer
+	if err := msg.streamTo(&buf); err != nil {
+		t.Fatalf("failed to stream: %v", err)
+	}
+	var have struct {
+		Version string `json:"jsonrpc"`
+		ID      int    `json:"id"`
+		Result  []int  `json:"result"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &have); err != nil {
+		t.Fatalf("invalid message %q: %v", buf.String(), err)
+	}
+	if have.Version != vsn || have.ID != 7 || !reflect.DeepEqual(have.Result, []int{1, 2, 3}) {
+		t.Fatalf("wrong message: %s", buf.String())
+	}
+	// Ordinary marshaling of the result encodes it in memory
+	enc, err := json.Marshal(new(streamService).Count(2, 0))
+	if err != nil || string(enc) != "[1,2]" {
+		t.Fatalf("wrong marshaled result: %s, %v", enc, err)
+	}
+}
+
+func TestStreamedResult(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	if err := server.RegisterName("stream", new(streamService)); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+
+	dialers := map[string]func() (*Client, error){
+		"inproc": func() (*Client, error) { return DialInProc(server), nil },
+		"http":   func() (*Client, error) { return Dial(httpsrv.URL) },
+		"ws":     func() (*Client, error) { return Dial("ws" + strings.TrimPrefix(wssrv.URL, "http")) },
+	}
+	want := make([]int, 1000)
+	for i := range want {
+		want[i] = i + 1
+	}
+	for name, dial := range dialers {
+		client, err := dial()
+		if err != nil {
+			t.Fatalf("%s: dial failed: %v", name, err)
+		}
+		var result []int
+		if err := client.Call(&result, "stream_count", len(want), 0); err != nil {
+			t.Fatalf("%s: call failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Fatalf("%s: wrong result, have %d items", name, len(result))
+		}
+		// Results within batches are buffered
+		batch := []BatchElem{
+			{Method: "stream_count", Args: []interface{}{2, 0}, Result: new([]int)},
+			{Method: "stream_count", Args: []interface{}{2, 1}, Result: new([]int)},
+		}
+		if err := client.BatchCall(batch); err != nil {
+			t.Fatalf("%s: batch failed: %v", name, err)
+		}
+		if batch[0].Error != nil || !reflect.DeepEqual(*batch[0].Result.(*[]int), []int{1, 2}) {
+			t.Fatalf("%s: wrong batch result: %v %v", name, batch[0].Result, batch[0].Error)
+		}
+		if batch[1].Error == nil {
+			t.Fatalf("%s: failed stream in batch not reported", name)
+		}
+		client.Close()
+	}
+	// Failures midway truncate HTTP responses
+	client, _ := Dial(httpsrv.URL)
+	defer client.Close()
+	var result []int
+	if err := client.Call(&result, "stream_count", 10, 5); err == nil {
+		t.Fatal("failed stream not reported")
+	}
+	// Failures midway close other connections
+	p1, p2 := net.Pipe()
+	go server.ServeCodec(NewCodec(p1), 0)
+	go io.WriteString(p2, `{"jsonrpc":"2.0","id":1,"method":"stream_count","params":[10,5]}`)
+	p2.SetDeadline(time.Now().Add(10 * time.Second))
+	resp, err := io.ReadAll(p2)
+	if err != nil {
+		t.Fatalf("connection not closed: %v", err)
+	}
+	if json.Valid(resp) {
+		t.Fatalf("failed stream written in full: %s", resp)
+	}
+}
+
+// Tests that streamed results hold the concurrency slots of their calls until
+// they're written.
+func TestStreamedResultLimits(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	service := &streamService{started: make(chan struct{}), unblock: make(chan struct{})}
+	if err := server.RegisterName("stream", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetRateLimits([]RateLimit{{Methods: []string{"stream_wait"}, Concurrent: 1}}, nil)
+	// Responses on a connection are written in order, so use separate ones
+	streaming, client := DialInProc(server), DialInProc(server)
+	defer streaming.Close()
+	defer client.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		var result []int
+		errc <- streaming.Call(&result, "stream_wait")
+	}()
+	<-service.started
+
+	err := client.Call(nil, "stream_wait")
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error while streaming: %v", err)
+	}
+	close(service.unblock)
+	if err := <-errc; err != nil {
+		t.Fatalf("streamed call failed: %v", err)
+	}
+	// The slot is released right after the response is written
+	go func() { <-service.started }()
+	for i := 0; ; i++ {
+		err := client.Call(nil, "stream_wait")
+		if err == nil {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("call after stream failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -61,6 +62,20 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header)
+		if s.limiter != nil && s.limiter.identify != nil {
+			codec.(*websocketCodec).info.client = s.limiter.identify(r)
+		}
+		codec.(*websocketCodec).info.filter = methodFilterFromContext(r.Context())
+
+		// Close the connection if the request context ends, e.g. because the
+		// credentials the connection was authenticated with expire
+		go func() {
+			select {
+			case <-r.Context().Done():
+				codec.close()
+			case <-codec.closed():
+			}
+		}()
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -288,8 +303,12 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header) Serve
 	encode := func(v interface{}, isErrorResponse bool) error {
 		return conn.WriteJSON(v)
 	}
+	codec := NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec)
+	codec.stream = func() (io.WriteCloser, error) {
+		return conn.NextWriter(websocket.TextMessage)
+	}
 	wc := &websocketCodec{
-		jsonCodec:    NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec),
+		jsonCodec:    codec,
 		conn:         conn,
 		pingReset:    make(chan struct{}, 1),
 		pongReceived: make(chan struct{}),